@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"backend/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 一次性操作令牌的用途，不同用途的令牌使用不同的签名密钥，不能互相替代，也不能当作访问令牌使用
+const (
+	PurposePasswordReset = "password_reset" // 找回密码
+	PurposeEmailVerify   = "email_verify"   // 验证邮箱
+)
+
+// ErrInvalidActionToken 操作令牌签名错误、已过期或已经使用过
+var ErrInvalidActionToken = errors.New("链接无效或已过期")
+
+// ActionClaims 操作令牌中的声明
+type ActionClaims struct {
+	UserID  int
+	Binding string // 签发时绑定的状态指纹，状态变化后令牌自动失效
+}
+
+// SignActionToken 签发邮件链接中使用的一次性令牌
+// binding 是签发时的状态指纹（例如当前密码哈希的指纹），使用前与最新状态比对：
+// 密码修改后重置链接失效，邮箱修改后验证链接失效，因此不需要在服务端保存令牌
+func SignActionToken(purpose string, userID int, binding string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userID,
+		"pur": purpose,
+		"bnd": binding,
+		"exp": time.Now().Add(ttl).Unix(),
+	})
+	return token.SignedString(actionKey(purpose))
+}
+
+// ParseActionToken 校验操作令牌的签名、用途和有效期，绑定的状态由调用方比对
+func ParseActionToken(purpose, tokenString string) (*ActionClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return actionKey(purpose), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["pur"] != purpose {
+		return nil, ErrInvalidActionToken
+	}
+	id, ok := claims["sub"].(float64)
+	if !ok {
+		return nil, ErrInvalidActionToken
+	}
+	binding, _ := claims["bnd"].(string)
+	return &ActionClaims{UserID: int(id), Binding: binding}, nil
+}
+
+// PasswordFingerprint 密码哈希的指纹，绑定到找回密码令牌中，密码修改后令牌失效
+func PasswordFingerprint(passwordHash string) string {
+	return fingerprint(PurposePasswordReset, passwordHash)
+}
+
+// EmailFingerprint 邮箱地址的指纹（不区分大小写），绑定到验证邮箱令牌中
+func EmailFingerprint(email string) string {
+	return fingerprint(PurposeEmailVerify, strings.ToLower(email))
+}
+
+// MatchBinding 以固定时间比较令牌中的指纹和当前状态的指纹
+func MatchBinding(claims *ActionClaims, current string) bool {
+	return hmac.Equal([]byte(claims.Binding), []byte(current))
+}
+
+// actionKey 从 JWT 密钥派生出各用途独立的签名密钥
+func actionKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, config.JwtSecret)
+	mac.Write([]byte("action:" + purpose))
+	return mac.Sum(nil)
+}
+
+// fingerprint 计算带密钥的指纹，令牌内容可以被解码，不能直接放入密码哈希
+func fingerprint(purpose, value string) string {
+	mac := hmac.New(sha256.New, actionKey(purpose))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
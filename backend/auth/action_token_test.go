@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestActionToken(t *testing.T) {
+	setupConfig(t)
+	binding := PasswordFingerprint("hash-1")
+
+	token, err := SignActionToken(PurposePasswordReset, 7, binding, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseActionToken(PurposePasswordReset, token)
+	if err != nil {
+		t.Fatalf("ParseActionToken() 出错: %v", err)
+	}
+	if claims.UserID != 7 || !MatchBinding(claims, binding) {
+		t.Fatalf("ParseActionToken() = %+v", claims)
+	}
+	// 密码修改后指纹变化，同一个链接不能再次使用
+	if MatchBinding(claims, PasswordFingerprint("hash-2")) {
+		t.Fatal("密码修改后指纹不应该匹配")
+	}
+
+	// 不同用途的令牌不能混用
+	if _, err := ParseActionToken(PurposeEmailVerify, token); !errors.Is(err, ErrInvalidActionToken) {
+		t.Fatalf("用验证邮箱的用途解析 = %v，期望 %v", err, ErrInvalidActionToken)
+	}
+
+	expired, err := SignActionToken(PurposePasswordReset, 7, binding, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseActionToken(PurposePasswordReset, expired); !errors.Is(err, ErrInvalidActionToken) {
+		t.Fatalf("解析过期令牌 = %v，期望 %v", err, ErrInvalidActionToken)
+	}
+}
+
+func TestEmailFingerprintIgnoresCase(t *testing.T) {
+	setupConfig(t)
+	if EmailFingerprint("Alice@Example.com") != EmailFingerprint("alice@example.com") {
+		t.Fatal("邮箱指纹应该不区分大小写")
+	}
+	if EmailFingerprint("alice@example.com") == EmailFingerprint("bob@example.com") {
+		t.Fatal("不同邮箱的指纹不应该相同")
+	}
+}
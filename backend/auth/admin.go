@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// EnsureAdmin 按配置创建初始管理员，conf 通常为 config.Conf.Admin
+// 未配置用户名时什么也不做；用户名已存在时不修改该用户，避免重启服务覆盖已修改的密码和角色
+func EnsureAdmin(ctx context.Context, conf config.AdminConfig, users repository.UserRepository, passwords *PasswordService) error {
+	if conf.Username == "" {
+		return nil
+	}
+	_, err := users.GetByUsername(ctx, conf.Username)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	if err := passwords.Validate(conf.Password); err != nil {
+		return fmt.Errorf("admin.password 不符合密码策略: %w", err)
+	}
+	hash, err := passwords.Hash(conf.Password)
+	if err != nil {
+		return err
+	}
+	user := &models.User{
+		Username:     conf.Username,
+		Password:     hash,
+		Email:        conf.Email,
+		RegisterTime: time.Now().Format("2006-01-02 15:04:05"),
+		Status:       "0",
+		Role:         models.RoleAdmin,
+	}
+	if err := users.Create(ctx, user); err != nil {
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+	log.Printf("Created initial admin %q", conf.Username)
+	return nil
+}
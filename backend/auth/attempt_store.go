@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Attempt 一个用户名或 IP 的登录尝试记录
+type Attempt struct {
+	Count       int       // 用户名：连续失败次数；IP：当前窗口内的请求数
+	WindowStart time.Time // IP 限流窗口的开始时间
+	LastAt      time.Time // 最近一次失败的时间
+	LockedUntil time.Time // 锁定的解除时间，零值表示未锁定
+}
+
+// AttemptStore 登录尝试记录的存储接口
+// 默认使用内存实现，多实例部署时可以基于数据库或 Redis 实现该接口，让各实例共享计数
+type AttemptStore interface {
+	// Get 返回 key 的记录，不存在或已过期时返回零值
+	Get(ctx context.Context, key string) (Attempt, error)
+	// Update 原子地修改 key 的记录并返回修改后的值，记录在 ttl 之后过期
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(a *Attempt)) (Attempt, error)
+	// Delete 删除 key 的记录
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryAttemptStore 基于内存的 AttemptStore，记录只在当前进程中有效
+type memoryAttemptStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryAttempt
+	lastSweep time.Time
+}
+
+type memoryAttempt struct {
+	Attempt
+	expiresAt time.Time
+}
+
+// sweepInterval 清理过期记录的间隔，避免大量不同的用户名或 IP 占用内存
+const sweepInterval = time.Minute
+
+// NewMemoryAttemptStore 创建基于内存的登录尝试存储
+func NewMemoryAttemptStore() AttemptStore {
+	return &memoryAttemptStore{entries: map[string]memoryAttempt{}, lastSweep: time.Now()}
+}
+
+func (s *memoryAttemptStore) Get(_ context.Context, key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || !entry.expiresAt.After(time.Now()) {
+		return Attempt{}, nil
+	}
+	return entry.Attempt, nil
+}
+
+func (s *memoryAttemptStore) Update(_ context.Context, key string, ttl time.Duration, fn func(a *Attempt)) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	entry, ok := s.entries[key]
+	if !ok || !entry.expiresAt.After(now) {
+		entry = memoryAttempt{}
+	}
+	fn(&entry.Attempt)
+	entry.expiresAt = now.Add(ttl)
+	s.entries[key] = entry
+	return entry.Attempt, nil
+}
+
+func (s *memoryAttemptStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep 定期删除已过期的记录，调用方需持有锁
+func (s *memoryAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !entry.expiresAt.After(now) {
+			delete(s.entries, key)
+		}
+	}
+}
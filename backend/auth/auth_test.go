@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"testing"
+)
+
+// setupConfig 使用默认配置，测试结束后恢复全局配置
+func setupConfig(t *testing.T) *config.Config {
+	t.Helper()
+	conf, secret := config.Conf, config.JwtSecret
+	t.Cleanup(func() { config.Conf, config.JwtSecret = conf, secret })
+
+	config.Conf = config.Default()
+	config.Conf.Password.Hash.BcryptCost = 4 // 测试中使用最低的计算成本
+	config.JwtSecret = []byte("0123456789abcdef0123456789abcdef")
+	return config.Conf
+}
+
+// createUser 在内存仓库中创建一个用户，password 为加密后的密码，可以为空
+func createUser(t *testing.T, repos *repository.Repositories, username, password string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Password: password, Status: models.UserStatusNormal, Role: models.RoleAuthor}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
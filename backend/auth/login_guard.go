@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 登录被拦截时返回的错误码
+const (
+	CodeLoginRateLimited = "login_rate_limited" // 同一 IP 的登录请求过多
+	CodeLoginDelayed     = "login_delayed"      // 刚刚登录失败，需要等待一段时间再试
+	CodeAccountLocked    = "account_locked"     // 连续失败次数过多，账号被临时锁定
+)
+
+// LoginBlockedError 登录请求被防暴力破解策略拦截
+type LoginBlockedError struct {
+	Code       string `json:"code"`
+	Message    string `json:"-"`
+	RetryAfter int    `json:"retry_after"` // 需要等待的秒数
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Message
+}
+
+// ErrorCode 返回错误码，用于填充响应中的 code
+func (e *LoginBlockedError) ErrorCode() string {
+	return e.Code
+}
+
+// LoginGuard 登录防暴力破解
+//
+// 按用户名统计连续失败次数：每次失败后需要等待的时间按 delay_base 翻倍增长，
+// 达到 max_failures 次后账号被锁定 lockout_duration，登录成功后清零；
+// 按 IP 统计固定窗口内的登录请求数，超过 ip_rate_limit 后拒绝该 IP 的登录请求。
+// 不存在的用户名同样计数，避免通过响应差异判断用户名是否存在。
+// 登录后关闭两步验证、重新生成恢复码时再次确认身份，同样按这些规则按用户id单独计数。
+type LoginGuard struct {
+	store AttemptStore
+	audit repository.AuditRepository
+	conf  config.LoginConfig
+}
+
+// NewLoginGuard 创建登录防暴力破解器，conf 通常为 config.Conf.Login
+func NewLoginGuard(store AttemptStore, audit repository.AuditRepository, conf config.LoginConfig) *LoginGuard {
+	return &LoginGuard{store: store, audit: audit, conf: conf}
+}
+
+// userKey 返回用户名的计数键，Check、Fail、Succeed 和 Unlock 都使用它
+// MySQL 的用户名比较不区分大小写并忽略末尾空格，"Admin"、"admin " 登录的是同一个账号，
+// 计数键需要统一转换，否则换一种写法就能绕过延迟和锁定
+func userKey(username string) string { return "user:" + strings.ToLower(strings.TrimSpace(username)) }
+
+func ipKey(ip string) string { return "ip:" + ip }
+
+// twoFactorKey 登录后再次确认身份（关闭两步验证、重新生成恢复码）的计数键，与登录分开计数，
+// 持有访问令牌的人猜错验证码不会导致账号无法登录
+func twoFactorKey(userID int) string { return "2fa:" + strconv.Itoa(userID) }
+
+// Check 在校验密码之前调用，登录请求被拦截时返回 *LoginBlockedError
+// 每次调用都会计入该 IP 的请求数
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) error {
+	return g.check(ctx, userKey(username), ip)
+}
+
+// CheckTwoFactor 在登录后再次校验密码或两步验证码之前调用，规则与 Check 相同，按用户id单独计数
+func (g *LoginGuard) CheckTwoFactor(ctx context.Context, userID int, ip string) error {
+	return g.check(ctx, twoFactorKey(userID), ip)
+}
+
+// check 检查 IP 请求数以及 key 的失败等待时间和锁定状态
+func (g *LoginGuard) check(ctx context.Context, key, ip string) error {
+	now := time.Now()
+
+	if err := g.checkIP(ctx, ip, now, "登录请求过于频繁，请稍后再试"); err != nil {
+		return err
+	}
+
+	a, err := g.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if a.LockedUntil.After(now) {
+		return &LoginBlockedError{
+			Code:       CodeAccountLocked,
+			Message:    "登录失败次数过多，账号已被临时锁定",
+			RetryAfter: seconds(a.LockedUntil.Sub(now)),
+		}
+	}
+	if a.Count > 0 {
+		if next := a.LastAt.Add(g.delay(a.Count)); next.After(now) {
+			return &LoginBlockedError{
+				Code:       CodeLoginDelayed,
+				Message:    "登录失败次数过多，请稍后再试",
+				RetryAfter: seconds(next.Sub(now)),
+			}
+		}
+	}
+	return nil
+}
+
+// CheckIP 只检查并计入 IP 请求数，用于找回密码等同样需要防止滥用的匿名接口
+// 与登录共用同一个计数窗口
+func (g *LoginGuard) CheckIP(ctx context.Context, ip string) error {
+	return g.checkIP(ctx, ip, time.Now(), "请求过于频繁，请稍后再试")
+}
+
+// checkIP 按固定窗口统计 IP 的请求数，超过 ip_rate_limit 时返回 *LoginBlockedError
+func (g *LoginGuard) checkIP(ctx context.Context, ip string, now time.Time, message string) error {
+	if g.conf.IPRateLimit <= 0 {
+		return nil
+	}
+	window := g.conf.IPRateWindow.Duration
+	a, err := g.store.Update(ctx, ipKey(ip), window, func(a *Attempt) {
+		if now.Sub(a.WindowStart) >= window {
+			a.Count = 0
+			a.WindowStart = now
+		}
+		a.Count++
+	})
+	if err != nil {
+		return err
+	}
+	if a.Count > g.conf.IPRateLimit {
+		return &LoginBlockedError{
+			Code:       CodeLoginRateLimited,
+			Message:    message,
+			RetryAfter: seconds(a.WindowStart.Add(window).Sub(now)),
+		}
+	}
+	return nil
+}
+
+// Fail 记录一次登录失败，达到上限时锁定账号并写入审计日志
+func (g *LoginGuard) Fail(ctx context.Context, username, ip string) error {
+	return g.fail(ctx, userKey(username), username, ip)
+}
+
+// FailTwoFactor 记录一次登录后确认身份的失败，username 用于审计日志
+func (g *LoginGuard) FailTwoFactor(ctx context.Context, userID int, username, ip string) error {
+	return g.fail(ctx, twoFactorKey(userID), username, ip)
+}
+
+// fail 记录 key 的一次失败，达到上限时锁定并写入审计日志，审计日志的目标为 username
+func (g *LoginGuard) fail(ctx context.Context, key, username, ip string) error {
+	now := time.Now()
+	locked := false
+	ttl := g.conf.FailureWindow.Duration
+	if g.conf.LockoutDuration.Duration > ttl {
+		ttl = g.conf.LockoutDuration.Duration
+	}
+
+	a, err := g.store.Update(ctx, key, ttl, func(a *Attempt) {
+		if now.Sub(a.LastAt) > g.conf.FailureWindow.Duration {
+			a.Count = 0
+		}
+		a.Count++
+		a.LastAt = now
+		if g.conf.MaxFailures > 0 && a.Count >= g.conf.MaxFailures {
+			// 锁定期间不再计数，解锁后重新开始
+			a.LockedUntil = now.Add(g.conf.LockoutDuration.Duration)
+			a.Count = 0
+			locked = true
+		}
+	})
+	if err != nil || !locked {
+		return err
+	}
+
+	detail := fmt.Sprintf("连续 %d 次登录失败，锁定至 %s", g.conf.MaxFailures, a.LockedUntil.Format(models.TimeLayout))
+	log.Printf("Login locked: username=%q ip=%s %s", username, ip, detail)
+	return g.audit.Create(ctx, &models.AuditLog{
+		Action:    models.AuditLoginLocked,
+		Target:    username,
+		IP:        ip,
+		Detail:    detail,
+		CreatedAt: now.Format(models.TimeLayout),
+	})
+}
+
+// Succeed 登录成功后清除该用户名的失败记录
+func (g *LoginGuard) Succeed(ctx context.Context, username string) error {
+	return g.store.Delete(ctx, userKey(username))
+}
+
+// SucceedTwoFactor 登录后确认身份成功，清除失败记录
+func (g *LoginGuard) SucceedTwoFactor(ctx context.Context, userID int) error {
+	return g.store.Delete(ctx, twoFactorKey(userID))
+}
+
+// Unlock 管理员解除账号锁定并写入审计日志
+func (g *LoginGuard) Unlock(ctx context.Context, username string, actorID int, ip string) error {
+	if err := g.store.Delete(ctx, userKey(username)); err != nil {
+		return err
+	}
+	return g.audit.Create(ctx, &models.AuditLog{
+		Action:    models.AuditLoginUnlocked,
+		ActorID:   actorID,
+		Target:    username,
+		IP:        ip,
+		CreatedAt: time.Now().Format(models.TimeLayout),
+	})
+}
+
+// delay 返回失败 failures 次后需要等待的时间
+func (g *LoginGuard) delay(failures int) time.Duration {
+	base := g.conf.DelayBase.Duration
+	if base <= 0 {
+		return 0
+	}
+	// 指数过大时直接取上限，避免溢出
+	if failures > 30 {
+		return g.conf.MaxDelay.Duration
+	}
+	d := time.Duration(float64(base) * math.Pow(2, float64(failures-1)))
+	if d > g.conf.MaxDelay.Duration {
+		d = g.conf.MaxDelay.Duration
+	}
+	return d
+}
+
+// seconds 向上取整为秒，至少为 1 秒
+func seconds(d time.Duration) int {
+	s := int(math.Ceil(d.Seconds()))
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestLoginGuard 使用内存存储创建登录防暴力破解器
+func newTestLoginGuard(conf config.LoginConfig) (*LoginGuard, repository.AuditRepository) {
+	audit := repository.NewMemory().Audit
+	return NewLoginGuard(NewMemoryAttemptStore(), audit, conf), audit
+}
+
+// blockedCode 返回 *LoginBlockedError 的错误码，未被拦截时返回空字符串
+func blockedCode(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var blocked *LoginBlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("error = %v，期望 *LoginBlockedError", err)
+	}
+	return blocked.Code
+}
+
+func TestLoginGuardDelay(t *testing.T) {
+	g, _ := newTestLoginGuard(config.LoginConfig{
+		DelayBase: config.Duration{Duration: time.Second},
+		MaxDelay:  config.Duration{Duration: 10 * time.Second},
+	})
+	// 每多失败一次等待时间翻倍，不超过上限
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, d := range want {
+		if got := g.delay(i + 1); got != d {
+			t.Errorf("delay(%d) = %v，期望 %v", i+1, got, d)
+		}
+	}
+	if got := g.delay(1000); got != 10*time.Second {
+		t.Errorf("delay(1000) = %v，期望上限 10s", got)
+	}
+
+	noDelay, _ := newTestLoginGuard(config.LoginConfig{})
+	if got := noDelay.delay(3); got != 0 {
+		t.Errorf("delay_base 为 0 时 delay(3) = %v，期望 0", got)
+	}
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestLoginGuard(config.LoginConfig{
+		FailureWindow: config.Duration{Duration: time.Minute},
+		DelayBase:     config.Duration{Duration: time.Minute},
+		MaxDelay:      config.Duration{Duration: time.Hour},
+	})
+
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatalf("首次登录 Check() error = %v", err)
+	}
+	if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := g.Check(ctx, "alice", "10.0.0.2")
+	if code := blockedCode(t, err); code != CodeLoginDelayed {
+		t.Fatalf("失败后立即重试 Check() code = %q，期望 %q", code, CodeLoginDelayed)
+	}
+	if retry := err.(*LoginBlockedError).RetryAfter; retry < 59 || retry > 60 {
+		t.Fatalf("RetryAfter = %d，期望约 60 秒", retry)
+	}
+
+	// 延迟只针对该用户名
+	if err := g.Check(ctx, "bob", "10.0.0.1"); err != nil {
+		t.Fatalf("其他用户 Check() error = %v", err)
+	}
+
+	// 登录成功后清零
+	if err := g.Succeed(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatalf("登录成功后 Check() error = %v", err)
+	}
+}
+
+func TestLoginGuardLockout(t *testing.T) {
+	ctx := context.Background()
+	g, audit := newTestLoginGuard(config.LoginConfig{
+		MaxFailures:     3,
+		LockoutDuration: config.Duration{Duration: 15 * time.Minute},
+		FailureWindow:   config.Duration{Duration: 15 * time.Minute},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatalf("第 %d 次失败后 Check() error = %v，未达到上限不应锁定", i+1, err)
+		}
+	}
+	if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Check(ctx, "alice", "10.0.0.1")
+	if code := blockedCode(t, err); code != CodeAccountLocked {
+		t.Fatalf("达到上限后 Check() code = %q，期望 %q", code, CodeAccountLocked)
+	}
+	if retry := err.(*LoginBlockedError).RetryAfter; retry < 899 || retry > 900 {
+		t.Fatalf("RetryAfter = %d，期望约 900 秒", retry)
+	}
+
+	logs, total, err := audit.List(ctx, repository.AuditQuery{Action: models.AuditLoginLocked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || logs[0].Target != "alice" {
+		t.Fatalf("锁定审计日志 = %+v，期望一条 alice 的记录", logs)
+	}
+
+	// 管理员解锁后可以重新登录，并记录审计日志
+	if err := g.Unlock(ctx, "alice", 1, "10.0.0.9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatalf("解锁后 Check() error = %v", err)
+	}
+	if _, total, _ := audit.List(ctx, repository.AuditQuery{Action: models.AuditLoginUnlocked}); total != 1 {
+		t.Fatalf("解锁审计日志 %d 条，期望 1 条", total)
+	}
+}
+
+func TestLoginGuardIPRateLimit(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestLoginGuard(config.LoginConfig{
+		IPRateLimit:  2,
+		IPRateWindow: config.Duration{Duration: time.Minute},
+	})
+	for i := 0; i < 2; i++ {
+		if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatalf("第 %d 次请求 Check() error = %v", i+1, err)
+		}
+	}
+	if code := blockedCode(t, g.Check(ctx, "bob", "10.0.0.1")); code != CodeLoginRateLimited {
+		t.Fatalf("超过限制后 Check() code = %q，期望 %q", code, CodeLoginRateLimited)
+	}
+	if code := blockedCode(t, g.CheckIP(ctx, "10.0.0.1")); code != CodeLoginRateLimited {
+		t.Fatalf("找回密码与登录共用计数，CheckIP() code = %q，期望 %q", code, CodeLoginRateLimited)
+	}
+	if err := g.Check(ctx, "alice", "10.0.0.2"); err != nil {
+		t.Fatalf("其他 IP Check() error = %v", err)
+	}
+}
+
+func TestLoginGuardNormalizesUsername(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestLoginGuard(config.LoginConfig{
+		MaxFailures:     3,
+		LockoutDuration: config.Duration{Duration: 15 * time.Minute},
+		FailureWindow:   config.Duration{Duration: 15 * time.Minute},
+	})
+
+	// 大小写和首尾空格不同的用户名登录的是同一个账号，计入同一个计数
+	for _, username := range []string{"admin", "Admin", "ADMIN "} {
+		if err := g.Fail(ctx, username, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, username := range []string{"admin", " aDmIn"} {
+		if code := blockedCode(t, g.Check(ctx, username, "10.0.0.1")); code != CodeAccountLocked {
+			t.Fatalf("Check(%q) code = %q，期望 %q", username, code, CodeAccountLocked)
+		}
+	}
+
+	// 解锁时同样不区分写法
+	if err := g.Unlock(ctx, "Admin", 1, "10.0.0.9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(ctx, "admin", "10.0.0.1"); err != nil {
+		t.Fatalf("解锁后 Check() error = %v", err)
+	}
+}
+
+func TestLoginGuardTwoFactorSeparateFromLogin(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestLoginGuard(config.LoginConfig{
+		MaxFailures:     3,
+		LockoutDuration: config.Duration{Duration: 15 * time.Minute},
+		FailureWindow:   config.Duration{Duration: 15 * time.Minute},
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := g.FailTwoFactor(ctx, 1, "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := blockedCode(t, g.CheckTwoFactor(ctx, 1, "10.0.0.1")); code != CodeAccountLocked {
+		t.Fatalf("确认身份失败达到上限后 CheckTwoFactor() code = %q，期望 %q", code, CodeAccountLocked)
+	}
+	// 与登录分开计数，其他用户也不受影响
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatalf("登录 Check() error = %v", err)
+	}
+	if err := g.CheckTwoFactor(ctx, 2, "10.0.0.1"); err != nil {
+		t.Fatalf("其他用户 CheckTwoFactor() error = %v", err)
+	}
+
+	if err := g.SucceedTwoFactor(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CheckTwoFactor(ctx, 1, "10.0.0.1"); err != nil {
+		t.Fatalf("清除后 CheckTwoFactor() error = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// 临时密码使用的字符集，覆盖密码策略可能要求的全部字符类别；去掉了容易混淆的 0/O、1/l/I
+const (
+	lowerChars   = "abcdefghijkmnopqrstuvwxyz"
+	upperChars   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	digitChars   = "23456789"
+	specialChars = ".!@#$%^&*()"
+)
+
+// temporaryPasswordLength 临时密码的默认长度，密码策略的最小长度更长时使用最小长度
+const temporaryPasswordLength = 16
+
+// GenerateTemporaryPassword 生成重置密码时使用的一次性临时密码，length 不能小于 4
+// 每类字符至少包含一个，满足密码策略的字符类别要求；用户使用临时密码登录后必须先修改密码
+func GenerateTemporaryPassword(length int) (string, error) {
+	classes := []string{lowerChars, upperChars, digitChars, specialChars}
+	all := lowerChars + upperChars + digitChars + specialChars
+
+	password := make([]byte, 0, length)
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// 打乱顺序，避免前几位的字符类别固定
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+// randomChar 从 chars 中均匀地随机取一个字符
+func randomChar(chars string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n.Int64()], nil
+}
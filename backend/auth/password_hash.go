@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"backend/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2idPrefix argon2id 哈希使用 PHC 字符串格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+const argon2idPrefix = "$argon2id$"
+
+// errUnknownHash 数据库中的密码哈希格式无法识别
+var errUnknownHash = errors.New("无法识别的密码哈希格式")
+
+// PasswordHasher 按配置计算密码哈希
+// 校验时根据哈希本身的格式选择算法，因此修改配置后旧的哈希仍然可以校验，
+// 校验通过后如果哈希的算法或参数与当前配置不一致，调用方应使用新的哈希替换
+type PasswordHasher struct {
+	conf config.PasswordHashConfig
+}
+
+// NewPasswordHasher 创建密码哈希器，conf 通常为 config.Conf.Password.Hash
+func NewPasswordHasher(conf config.PasswordHashConfig) *PasswordHasher {
+	return &PasswordHasher{conf: conf}
+}
+
+// Hash 按当前配置计算密码哈希
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.conf.Algorithm == "argon2id" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, uint32(h.conf.Argon2Iterations), uint32(h.conf.Argon2Memory), uint8(h.conf.Argon2Parallelism), 32)
+		return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+			h.conf.Argon2Memory, h.conf.Argon2Iterations, h.conf.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.conf.BcryptCost)
+	return string(hash), err
+}
+
+// Verify 校验密码是否与哈希匹配，needsRehash 表示哈希的算法或参数与当前配置不一致
+func (h *PasswordHasher) Verify(hash, password string) (ok bool, needsRehash bool, err error) {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, false, err
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false, nil
+		}
+		current := h.conf.Algorithm == "argon2id" &&
+			params.memory == uint32(h.conf.Argon2Memory) &&
+			params.iterations == uint32(h.conf.Argon2Iterations) &&
+			params.parallelism == uint8(h.conf.Argon2Parallelism)
+		return true, !current, nil
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, errUnknownHash
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, errUnknownHash
+	}
+	return true, h.conf.Algorithm != "bcrypt" || cost != h.conf.BcryptCost, nil
+}
+
+// argon2idParams 从哈希字符串中解析出的参数
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(hash string) (argon2idParams, []byte, []byte, error) {
+	var params argon2idParams
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, errUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errUnknownHash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"backend/config"
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码不符合策略时返回的错误码
+const (
+	CodePasswordPolicy    = "password_policy"     // 长度或字符类别不符合要求
+	CodePasswordTooCommon = "password_too_common" // 在常见密码列表中
+	CodePasswordBreached  = "password_breached"   // 在泄露密码库中
+	CodePasswordReused    = "password_reused"     // 与最近使用过的密码相同
+	CodePasswordTooLong   = "password_too_long"   // 超过 bcrypt 能处理的字节数
+)
+
+// bcryptMaxBytes bcrypt 只能处理 72 个字节的密码；max_length 按字符计算，
+// 中文等多字节字符组成的密码不超过 max_length 也可能超过这个限制
+const bcryptMaxBytes = 72
+
+// PasswordPolicyError 密码不符合策略，Message 可以直接返回给用户，Params 用于翻译错误码对应的消息
+type PasswordPolicyError struct {
+	Code    string
+	Message string
+	Params  map[string]string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Message
+}
+
+// PasswordPolicy 密码强度规则：长度、字符类别和禁止使用的常见密码
+type PasswordPolicy struct {
+	conf   config.PasswordConfig
+	common map[string]bool // 小写的明文密码
+	sha1s  map[string]bool // 大写十六进制的 SHA-1，与 HIBP 下载的格式一致
+}
+
+// NewPasswordPolicy 创建密码策略并加载常见密码列表，conf 通常为 config.Conf.Password
+// 列表文件每行一个明文密码或 40 位 SHA-1 哈希（可以带 :次数 后缀），# 开头的行为注释
+func NewPasswordPolicy(conf config.PasswordConfig) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{conf: conf, common: map[string]bool{}, sha1s: map[string]bool{}}
+	if conf.CommonListFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(conf.CommonListFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			p.sha1s[strings.ToUpper(hash)] = true
+			continue
+		}
+		p.common[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", conf.CommonListFile, err)
+	}
+	return p, nil
+}
+
+// Describe 返回密码规则的说明，用于提示用户
+func (p *PasswordPolicy) Describe() string {
+	desc := fmt.Sprintf("密码长度在 %d-%d 位之间", p.conf.MinLength, p.conf.MaxLength)
+	classes := []string{}
+	if p.conf.RequireLower && p.conf.RequireUpper {
+		classes = append(classes, "大小写字母")
+	} else if p.conf.RequireLower {
+		classes = append(classes, "小写字母")
+	} else if p.conf.RequireUpper {
+		classes = append(classes, "大写字母")
+	}
+	if p.conf.RequireDigit {
+		classes = append(classes, "数字")
+	}
+	if p.conf.RequireSpecial {
+		classes = append(classes, "特殊字符")
+	}
+	if n := len(classes); n > 1 {
+		desc += "，且必须包含" + strings.Join(classes[:n-1], "、") + "和" + classes[n-1]
+	} else if n == 1 {
+		desc += "，且必须包含" + classes[0]
+	}
+	return desc
+}
+
+// policyError 长度或字符类别不符合要求时返回的错误，classes 参数使用与语言无关的写法
+func (p *PasswordPolicy) policyError() *PasswordPolicyError {
+	classes := []string{}
+	if p.conf.RequireLower {
+		classes = append(classes, "a-z")
+	}
+	if p.conf.RequireUpper {
+		classes = append(classes, "A-Z")
+	}
+	if p.conf.RequireDigit {
+		classes = append(classes, "0-9")
+	}
+	if p.conf.RequireSpecial {
+		classes = append(classes, "!@#...")
+	}
+	return &PasswordPolicyError{Code: CodePasswordPolicy, Message: p.Describe(), Params: map[string]string{
+		"min":     strconv.Itoa(p.conf.MinLength),
+		"max":     strconv.Itoa(p.conf.MaxLength),
+		"classes": strings.Join(classes, ", "),
+	}}
+}
+
+// Validate 检查密码是否符合规则，不符合时返回 *PasswordPolicyError
+func (p *PasswordPolicy) Validate(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < p.conf.MinLength || n > p.conf.MaxLength {
+		return p.policyError()
+	}
+	if p.conf.Hash.Algorithm == "bcrypt" && len(password) > bcryptMaxBytes {
+		return &PasswordPolicyError{
+			Code:    CodePasswordTooLong,
+			Message: fmt.Sprintf("密码过长，最多 %d 个字节（一个中文字符占 3 个字节）", bcryptMaxBytes),
+			Params:  map[string]string{"max": strconv.Itoa(bcryptMaxBytes)},
+		}
+	}
+
+	var lower, upper, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsLetter(r):
+			// 没有大小写之分的文字（例如中文）不计入任何类别
+		default:
+			special = true
+		}
+	}
+	if (p.conf.RequireLower && !lower) || (p.conf.RequireUpper && !upper) ||
+		(p.conf.RequireDigit && !digit) || (p.conf.RequireSpecial && !special) {
+		return p.policyError()
+	}
+
+	if p.common[strings.ToLower(password)] {
+		return &PasswordPolicyError{Code: CodePasswordTooCommon, Message: "该密码过于常见，请换一个密码"}
+	}
+	if len(p.sha1s) > 0 {
+		sum := sha1.Sum([]byte(password))
+		if p.sha1s[strings.ToUpper(hex.EncodeToString(sum[:]))] {
+			return &PasswordPolicyError{Code: CodePasswordBreached, Message: "该密码已出现在泄露的密码库中，请换一个密码"}
+		}
+	}
+	return nil
+}
+
+// isSHA1Hex 判断字符串是否为 40 位十六进制
+func isSHA1Hex(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"backend/config"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// policyCode 返回 *PasswordPolicyError 的错误码，通过校验时返回空字符串
+func policyCode(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("error = %v，期望 *PasswordPolicyError", err)
+	}
+	return policyErr.Code
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	conf := config.Default().Password
+	policy, err := NewPasswordPolicy(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{"符合要求", "Abcdef1!", ""},
+		{"太短", "Ab1!", CodePasswordPolicy},
+		{"太长", "Abcdef1!" + strings.Repeat("x", 30), CodePasswordPolicy},
+		{"缺少大写字母", "abcdef1!", CodePasswordPolicy},
+		{"缺少小写字母", "ABCDEF1!", CodePasswordPolicy},
+		{"缺少数字", "Abcdefg!", CodePasswordPolicy},
+		{"缺少特殊字符", "Abcdef12", CodePasswordPolicy},
+		{"中文不计入任何类别", "Ab1中文密码", CodePasswordPolicy},
+		{"长度按字符计算", "Ab1!中文", ""},
+		// 不超过 max_length 个字符，但超过 bcrypt 能处理的 72 个字节
+		{"超过 72 个字节", "Aa1!" + strings.Repeat("密", 24), CodePasswordTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policyCode(t, policy.Validate(tt.password)); got != tt.want {
+				t.Fatalf("Validate(%q) code = %q，期望 %q", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyByteLimitOnlyForBcrypt(t *testing.T) {
+	conf := config.Default().Password
+	conf.Hash.Algorithm = "argon2id"
+	policy, err := NewPasswordPolicy(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Validate("Aa1!" + strings.Repeat("密", 24)); err != nil {
+		t.Fatalf("argon2id 没有字节数限制，Validate() error = %v", err)
+	}
+}
+
+func TestPasswordPolicyCommonList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "common.txt")
+	content := strings.Join([]string{
+		"# 注释",
+		"Password1!",
+		// "Qwerty12#" 的 SHA-1，带出现次数后缀
+		"CC889D0C165575BBCF11833E907E447E4D0BE133:42",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf := config.Default().Password
+	conf.CommonListFile = file
+	policy, err := NewPasswordPolicy(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := policyCode(t, policy.Validate("pASSWORD1!")); code != CodePasswordTooCommon {
+		t.Fatalf("常见密码不区分大小写，code = %q，期望 %q", code, CodePasswordTooCommon)
+	}
+	if code := policyCode(t, policy.Validate("Qwerty12#")); code != CodePasswordBreached {
+		t.Fatalf("泄露的密码 code = %q，期望 %q", code, CodePasswordBreached)
+	}
+	if err := policy.Validate("Abcdef1!"); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	conf.CommonListFile = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := NewPasswordPolicy(conf); err == nil {
+		t.Fatal("列表文件不存在时应该返回错误")
+	}
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// PasswordService 统一处理密码的校验、哈希和修改
+// 所有设置密码的入口都通过它完成，保证使用同一套密码策略和哈希配置
+type PasswordService struct {
+	policy      *PasswordPolicy
+	hasher      *PasswordHasher
+	users       repository.UserRepository
+	history     repository.PasswordHistoryRepository
+	historySize int
+}
+
+// NewPasswordService 创建密码服务，conf 通常为 config.Conf.Password，常见密码列表读取失败时返回错误
+func NewPasswordService(conf config.PasswordConfig, users repository.UserRepository, history repository.PasswordHistoryRepository) (*PasswordService, error) {
+	policy, err := NewPasswordPolicy(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &PasswordService{
+		policy:      policy,
+		hasher:      NewPasswordHasher(conf.Hash),
+		users:       users,
+		history:     history,
+		historySize: conf.HistorySize,
+	}, nil
+}
+
+// Validate 检查密码是否符合密码策略，不符合时返回 *PasswordPolicyError
+func (s *PasswordService) Validate(password string) error {
+	return s.policy.Validate(password)
+}
+
+// Hash 按当前配置计算密码哈希，用于创建用户
+func (s *PasswordService) Hash(password string) (string, error) {
+	return s.hasher.Hash(password)
+}
+
+// Login 校验登录密码，user 需要包含加密后的密码
+// 校验通过且哈希的算法或参数已过时，会用新的配置重新计算并保存，同时更新 user.Password；
+// 重新计算失败不影响本次登录，只记录日志
+func (s *PasswordService) Login(ctx context.Context, user *models.User, password string) (bool, error) {
+	ok, needsRehash, err := s.hasher.Verify(user.Password, password)
+	if err != nil || !ok || !needsRehash {
+		return ok, err
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+		return true, nil
+	}
+	replaced, err := s.users.ReplacePasswordHash(ctx, user.ID, user.Password, hash)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+		return true, nil
+	}
+	if replaced {
+		user.Password = hash
+	}
+	return true, nil
+}
+
+// Check 校验用户当前的密码，用于修改密码、关闭两步验证等需要再次确认身份的操作
+func (s *PasswordService) Check(ctx context.Context, userID int, password string) (bool, error) {
+	hash, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	ok, _, err := s.hasher.Verify(hash, password)
+	return ok, err
+}
+
+// Set 修改用户密码，新密码需要已通过 Validate 检查
+// 新密码不能与当前密码以及最近 history_size-1 个历史密码相同，相同时返回 *PasswordPolicyError
+func (s *PasswordService) Set(ctx context.Context, userID int, password string, mustChange bool) error {
+	current, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.historySize > 0 {
+		recent, err := s.history.ListRecent(ctx, userID, s.historySize-1)
+		if err != nil {
+			return err
+		}
+		for _, hash := range append([]string{current}, recent...) {
+			if ok, _, _ := s.hasher.Verify(hash, password); ok {
+				return &PasswordPolicyError{
+					Code:    CodePasswordReused,
+					Message: fmt.Sprintf("新密码不能与最近使用过的 %d 个密码相同", s.historySize),
+					Params:  map[string]string{"count": strconv.Itoa(s.historySize)},
+				}
+			}
+		}
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	return s.replace(ctx, userID, current, hash, mustChange)
+}
+
+// SetTemporary 为用户生成一次性临时密码，用户登录后必须先修改密码
+func (s *PasswordService) SetTemporary(ctx context.Context, userID int) (string, error) {
+	current, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	length := temporaryPasswordLength
+	if length < s.policy.conf.MinLength {
+		length = s.policy.conf.MinLength
+	}
+	if length > s.policy.conf.MaxLength {
+		length = s.policy.conf.MaxLength
+	}
+	password, err := GenerateTemporaryPassword(length)
+	if err != nil {
+		return "", err
+	}
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return "", err
+	}
+	return password, s.replace(ctx, userID, current, hash, true)
+}
+
+// replace 保存新密码，并把被替换的密码记入历史
+func (s *PasswordService) replace(ctx context.Context, userID int, current, hash string, mustChange bool) error {
+	if err := s.users.UpdatePassword(ctx, userID, hash, mustChange); err != nil {
+		return err
+	}
+	if s.historySize <= 1 {
+		return nil
+	}
+	if err := s.history.Add(ctx, userID, current, time.Now().Format(models.TimeLayout)); err != nil {
+		return err
+	}
+	return s.history.Prune(ctx, userID, s.historySize-1)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/repository"
+	"context"
+	"strings"
+	"testing"
+)
+
+// newTestPasswordService 使用内存仓库创建密码服务
+func newTestPasswordService(t *testing.T, conf config.PasswordConfig, repos *repository.Repositories) *PasswordService {
+	t.Helper()
+	s, err := NewPasswordService(conf, repos.Users, repos.PasswordHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPasswordServiceHistory(t *testing.T) {
+	conf := setupConfig(t).Password
+	conf.HistorySize = 3
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	s := newTestPasswordService(t, conf, repos)
+
+	hash, err := s.Hash("Password1!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := createUser(t, repos, "alice", hash)
+
+	for _, password := range []string{"Password2!", "Password3!", "Password4!"} {
+		if err := s.Set(ctx, user.ID, password, false); err != nil {
+			t.Fatalf("Set(%q) error = %v", password, err)
+		}
+	}
+	// 当前密码和最近 history_size-1 个历史密码都不能再用
+	for _, password := range []string{"Password4!", "Password3!", "Password2!"} {
+		if code := policyCode(t, s.Set(ctx, user.ID, password, false)); code != CodePasswordReused {
+			t.Fatalf("Set(%q) code = %q，期望 %q", password, code, CodePasswordReused)
+		}
+	}
+	// 更早的密码已经超出历史记录的范围
+	if err := s.Set(ctx, user.ID, "Password1!", false); err != nil {
+		t.Fatalf("Set(%q) error = %v", "Password1!", err)
+	}
+	if ok, err := s.Check(ctx, user.ID, "Password1!"); err != nil || !ok {
+		t.Fatalf("Check() = %v, %v，期望密码已修改", ok, err)
+	}
+}
+
+func TestPasswordServiceRehashOnLogin(t *testing.T) {
+	conf := setupConfig(t).Password
+	ctx := context.Background()
+	repos := repository.NewMemory()
+
+	old := newTestPasswordService(t, conf, repos)
+	hash, err := old.Hash("Password1!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createUser(t, repos, "alice", hash)
+
+	// 修改哈希配置后，登录成功时按新的配置重新计算
+	conf.Hash = config.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+	s := newTestPasswordService(t, conf, repos)
+
+	user, err := repos.Users.GetByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Login(ctx, user, "wrong"); err != nil || ok {
+		t.Fatalf("密码错误时 Login() = %v, %v", ok, err)
+	}
+	if user.Password != hash {
+		t.Fatal("密码错误时不应该重新计算哈希")
+	}
+	if ok, err := s.Login(ctx, user, "Password1!"); err != nil || !ok {
+		t.Fatalf("Login() = %v, %v", ok, err)
+	}
+	stored, err := repos.Users.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, argon2idPrefix) || stored != user.Password {
+		t.Fatalf("重新计算后的哈希为 %q，期望 argon2id 哈希", stored)
+	}
+
+	// 旧的哈希已经替换，使用新的哈希仍然可以登录
+	if ok, err := s.Login(ctx, user, "Password1!"); err != nil || !ok {
+		t.Fatalf("重新计算后 Login() = %v, %v", ok, err)
+	}
+}
+
+func TestPasswordHashTooLong(t *testing.T) {
+	conf := setupConfig(t).Password
+	s := newTestPasswordService(t, conf, repository.NewMemory())
+	// 密码策略拦截超过 72 个字节的密码，不会走到 bcrypt 返回内部错误
+	password := "Aa1!" + strings.Repeat("密", 24)
+	if code := policyCode(t, s.Validate(password)); code != CodePasswordTooLong {
+		t.Fatalf("Validate() code = %q，期望 %q", code, CodePasswordTooLong)
+	}
+}
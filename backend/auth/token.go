@@ -0,0 +1,338 @@
+// Package auth 负责令牌的签发、刷新和撤销
+//
+// 登录后签发一对令牌：短期有效的 JWT 访问令牌，以及保存在服务端的刷新令牌。
+// 刷新令牌每使用一次就会轮换为新的令牌，同一次登录轮换出的令牌属于同一个家族，
+// 一个家族就是用户的一个登录会话；已经轮换过的令牌再次被使用说明令牌可能被盗，
+// 此时整个家族都会被撤销。
+//
+// 访问令牌通过两种方式提前失效：
+//   - 用户的令牌版本（user.token_version）递增后，之前签发的访问令牌全部失效，
+//     用于修改密码、重置密码、注销账号等需要让用户所有登录失效的场景；
+//   - 注销单个会话时，该会话近期签发的访问令牌 jti 加入黑名单，直到令牌自然过期。
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌不存在
+	ErrInvalidRefreshToken = errors.New("无效的刷新令牌")
+	// ErrRefreshTokenExpired 刷新令牌已过期
+	ErrRefreshTokenExpired = errors.New("刷新令牌已过期")
+	// ErrRefreshTokenReused 已轮换或已注销的刷新令牌被再次使用，整个家族已被撤销
+	ErrRefreshTokenReused = errors.New("刷新令牌已失效")
+	// ErrAccessTokenExpired 访问令牌已过期
+	ErrAccessTokenExpired = errors.New("令牌已过期")
+	// ErrInvalidAccessToken 访问令牌签名或格式错误
+	ErrInvalidAccessToken = errors.New("无效的令牌")
+	// ErrSessionNotFound 会话不存在或不属于该用户
+	ErrSessionNotFound = errors.New("会话不存在")
+	// ErrAccountCancelled 账号已注销，不能再签发令牌
+	ErrAccountCancelled = errors.New("账号已注销")
+)
+
+// TokenPair 登录或刷新后返回给客户端的令牌
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌的有效秒数
+}
+
+// Client 发起登录或刷新的设备信息
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// AccessClaims 访问令牌中的声明
+type AccessClaims struct {
+	UserID    int
+	SessionID string // 令牌家族 id
+	Version   int    // 签发时用户的令牌版本
+	JTI       string
+}
+
+// TokenService 令牌服务
+type TokenService struct {
+	tokens repository.TokenRepository
+	users  repository.UserRepository
+}
+
+// NewTokenService 创建令牌服务
+func NewTokenService(tokens repository.TokenRepository, users repository.UserRepository) *TokenService {
+	return &TokenService{tokens: tokens, users: users}
+}
+
+// Issue 登录成功后签发令牌，开始一个新的令牌家族
+func (s *TokenService) Issue(ctx context.Context, userID int, client Client) (*TokenPair, error) {
+	familyID, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, userID, familyID, client)
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string, client Client) (*TokenPair, error) {
+	stored, err := s.tokens.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != "" {
+		return nil, s.revokeReused(ctx, stored.FamilyID)
+	}
+	if stored.ExpiresAt <= time.Now().Format(models.TimeLayout) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	// 条件更新保证同一个令牌只能成功轮换一次，并发请求中失败的一方按重放处理
+	ok, err := s.tokens.Revoke(ctx, stored.ID, time.Now().Format(models.TimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.revokeReused(ctx, stored.FamilyID)
+	}
+
+	pair, err := s.issue(ctx, stored.UserID, stored.FamilyID, client)
+	if errors.Is(err, repository.ErrNotFound) { // 用户已被删除
+		return nil, ErrInvalidRefreshToken
+	}
+	return pair, err
+}
+
+// Revoke 注销刷新令牌所在的会话，令牌不存在时视为已注销
+func (s *TokenService) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := s.tokens.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, stored.FamilyID)
+}
+
+// Sessions 返回用户当前有效的登录会话，currentID 为发起请求的会话 id
+func (s *TokenService) Sessions(ctx context.Context, userID int, currentID string) ([]models.Session, error) {
+	tokens, err := s.tokens.ListActive(ctx, userID, time.Now().Format(models.TimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	// 每个家族只有最新的一个刷新令牌有效，正常情况下一个令牌就是一个会话
+	sessions := []models.Session{}
+	seen := map[string]bool{}
+	for _, t := range tokens {
+		if seen[t.FamilyID] {
+			continue
+		}
+		seen[t.FamilyID] = true
+		sessions = append(sessions, models.Session{
+			ID:           t.FamilyID,
+			UserAgent:    t.UserAgent,
+			IP:           t.IP,
+			LastActiveAt: t.CreatedAt,
+			ExpiresAt:    t.ExpiresAt,
+			Current:      t.FamilyID == currentID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession 注销用户的一个会话，该会话的访问令牌立即失效
+func (s *TokenService) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	family, err := s.tokens.ListFamily(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if len(family) == 0 || family[0].UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.revokeFamily(ctx, sessionID)
+}
+
+// RevokeAll 注销用户的所有会话，并使已签发的访问令牌全部失效
+func (s *TokenService) RevokeAll(ctx context.Context, userID int) error {
+	if err := s.users.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUser(ctx, userID, time.Now().Format(models.TimeLayout))
+}
+
+// IsDenied 判断访问令牌是否已被注销
+func (s *TokenService) IsDenied(ctx context.Context, claims *AccessClaims) (bool, error) {
+	return s.tokens.IsDenied(ctx, claims.JTI)
+}
+
+// revokeReused 撤销被重放的令牌家族
+func (s *TokenService) revokeReused(ctx context.Context, familyID string) error {
+	if err := s.revokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// revokeFamily 撤销整个令牌家族，并将其中可能仍未过期的访问令牌加入黑名单
+func (s *TokenService) revokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	if err := s.tokens.RevokeFamily(ctx, familyID, now.Format(models.TimeLayout)); err != nil {
+		return err
+	}
+
+	family, err := s.tokens.ListFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	accessTTL := config.Conf.JWT.AccessTokenTTL.Duration
+	for _, t := range family {
+		issuedAt, err := time.ParseInLocation(models.TimeLayout, t.CreatedAt, time.Local)
+		if err != nil || t.AccessJTI == "" {
+			continue
+		}
+		// 时间字段只精确到秒，多保留一秒避免令牌在边界上漏过
+		expiresAt := issuedAt.Add(accessTTL + time.Second)
+		if expiresAt.Before(now) {
+			continue
+		}
+		if err := s.tokens.Deny(ctx, t.AccessJTI, expiresAt.Format(models.TimeLayout)); err != nil {
+			return err
+		}
+	}
+	// 顺便清理已经过期的黑名单记录
+	return s.tokens.PurgeDenied(ctx, now.Format(models.TimeLayout))
+}
+
+// issue 在指定家族中签发访问令牌和新的刷新令牌
+func (s *TokenService) issue(ctx context.Context, userID int, familyID string, client Client) (*TokenPair, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == models.UserStatusCancelled {
+		return nil, ErrAccountCancelled
+	}
+
+	jti, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	accessTTL := config.Conf.JWT.AccessTokenTTL.Duration
+	accessToken, err := generateAccessToken(AccessClaims{
+		UserID:    userID,
+		SessionID: familyID,
+		Version:   user.TokenVersion,
+		JTI:       jti,
+	}, accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = s.tokens.Create(ctx, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		UserAgent: truncate(client.UserAgent, 255),
+		IP:        client.IP,
+		CreatedAt: now.Format(models.TimeLayout),
+		ExpiresAt: now.Add(config.Conf.JWT.RefreshTokenTTL.Duration).Format(models.TimeLayout),
+		AccessJTI: jti,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL / time.Second),
+	}, nil
+}
+
+// generateAccessToken 生成 JWT 访问令牌
+func generateAccessToken(claims AccessClaims, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userID": claims.UserID,
+		"sid":    claims.SessionID,
+		"ver":    claims.Version,
+		"jti":    claims.JTI,
+		"exp":    time.Now().Add(ttl).Unix(),
+	})
+	return token.SignedString(config.JwtSecret)
+}
+
+// ParseAccessToken 校验访问令牌的签名和有效期并读取声明
+// 不检查令牌版本和黑名单，这两项需要查询数据库，由调用方结合用户信息判断
+func ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return config.JwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrAccessTokenExpired
+		}
+		return nil, ErrInvalidAccessToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidAccessToken
+	}
+	id, ok := claims["userID"].(float64)
+	if !ok {
+		return nil, ErrInvalidAccessToken
+	}
+
+	// 旧版本签发的令牌没有 sid、ver、jti，无法校验令牌版本，也无法加入黑名单，一律视为无效
+	sid, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	ver, ok := claims["ver"].(float64)
+	if !ok || sid == "" || jti == "" {
+		return nil, ErrInvalidAccessToken
+	}
+	return &AccessClaims{UserID: int(id), SessionID: sid, Version: int(ver), JTI: jti}, nil
+}
+
+// hashToken 数据库中只保存刷新令牌的哈希，数据库泄露时令牌无法直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString 生成 n 字节的随机值并编码为 URL 安全的字符串
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// truncate 按字节截断字符串，避免超出数据库字段长度
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRefreshRotatesToken(t *testing.T) {
+	setupConfig(t)
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	user := createUser(t, repos, "alice", "")
+	tokens := NewTokenService(repos.Tokens, repos.Users)
+
+	first, err := tokens.Issue(ctx, user.ID, Client{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tokens.Refresh(ctx, first.RefreshToken, Client{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("刷新后应该签发新的令牌")
+	}
+
+	// 轮换后的令牌属于同一个会话
+	firstClaims, err := ParseAccessToken(first.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondClaims, err := ParseAccessToken(second.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstClaims.SessionID != secondClaims.SessionID {
+		t.Fatalf("会话 id 从 %q 变为 %q", firstClaims.SessionID, secondClaims.SessionID)
+	}
+	if firstClaims.JTI == secondClaims.JTI {
+		t.Fatal("每次签发的访问令牌 jti 应该不同")
+	}
+
+	if _, err := tokens.Refresh(ctx, second.RefreshToken, Client{}); err != nil {
+		t.Fatalf("使用新的刷新令牌 Refresh() error = %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	setupConfig(t)
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	user := createUser(t, repos, "alice", "")
+	tokens := NewTokenService(repos.Tokens, repos.Users)
+
+	first, err := tokens.Issue(ctx, user.ID, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tokens.Refresh(ctx, first.RefreshToken, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一次登录之外的会话不受影响
+	other, err := tokens.Issue(ctx, user.ID, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已轮换的令牌再次使用视为被盗，整个家族被撤销
+	if _, err := tokens.Refresh(ctx, first.RefreshToken, Client{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("重放旧的刷新令牌 Refresh() error = %v，期望 %v", err, ErrRefreshTokenReused)
+	}
+	if _, err := tokens.Refresh(ctx, second.RefreshToken, Client{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("家族被撤销后 Refresh() error = %v，期望 %v", err, ErrRefreshTokenReused)
+	}
+
+	// 家族中仍未过期的访问令牌加入黑名单
+	for _, pair := range []*TokenPair{first, second} {
+		claims, err := ParseAccessToken(pair.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		denied, err := tokens.IsDenied(ctx, claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !denied {
+			t.Fatalf("访问令牌 %s 应该在黑名单中", claims.JTI)
+		}
+	}
+
+	otherClaims, err := ParseAccessToken(other.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denied, _ := tokens.IsDenied(ctx, otherClaims); denied {
+		t.Fatal("其他会话的访问令牌不应该被加入黑名单")
+	}
+	if _, err := tokens.Refresh(ctx, other.RefreshToken, Client{}); err != nil {
+		t.Fatalf("其他会话 Refresh() error = %v", err)
+	}
+}
+
+func TestRefreshInvalidToken(t *testing.T) {
+	setupConfig(t)
+	repos := repository.NewMemory()
+	tokens := NewTokenService(repos.Tokens, repos.Users)
+	if _, err := tokens.Refresh(context.Background(), "unknown", Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() error = %v，期望 %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshCancelledAccount(t *testing.T) {
+	setupConfig(t)
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	user := createUser(t, repos, "alice", "")
+	tokens := NewTokenService(repos.Tokens, repos.Users)
+
+	pair, err := tokens.Issue(ctx, user.ID, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Status = models.UserStatusCancelled
+	if err := repos.Users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Refresh(ctx, pair.RefreshToken, Client{}); !errors.Is(err, ErrAccountCancelled) {
+		t.Fatalf("Refresh() error = %v，期望 %v", err, ErrAccountCancelled)
+	}
+}
+
+func TestParseAccessTokenRequiresClaims(t *testing.T) {
+	setupConfig(t)
+	exp := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"旧版本令牌", jwt.MapClaims{"userID": 1, "exp": exp}},
+		{"缺少 sid", jwt.MapClaims{"userID": 1, "ver": 0, "jti": "j", "exp": exp}},
+		{"缺少 jti", jwt.MapClaims{"userID": 1, "sid": "s", "ver": 0, "exp": exp}},
+		{"缺少 ver", jwt.MapClaims{"userID": 1, "sid": "s", "jti": "j", "exp": exp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString(config.JwtSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseAccessToken(token); !errors.Is(err, ErrInvalidAccessToken) {
+				t.Fatalf("ParseAccessToken() error = %v，期望 %v", err, ErrInvalidAccessToken)
+			}
+		})
+	}
+
+	token, err := generateAccessToken(AccessClaims{UserID: 1, SessionID: "s", Version: 2, JTI: "j"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		t.Fatalf("ParseAccessToken() error = %v", err)
+	}
+	if *claims != (AccessClaims{UserID: 1, SessionID: "s", Version: 2, JTI: "j"}) {
+		t.Fatalf("ParseAccessToken() = %+v", claims)
+	}
+}
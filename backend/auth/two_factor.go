@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PurposeTwoFactorLogin 密码验证通过后、提交两步验证码之前使用的临时令牌
+const PurposeTwoFactorLogin = "two_factor_login"
+
+var (
+	// ErrTwoFactorAlreadyEnabled 已经开启两步验证，需要先关闭才能重新绑定
+	ErrTwoFactorAlreadyEnabled = errors.New("已开启两步验证")
+	// ErrTwoFactorNotEnabled 未开启两步验证
+	ErrTwoFactorNotEnabled = errors.New("未开启两步验证")
+	// ErrTwoFactorNotSetup 开启前需要先获取密钥
+	ErrTwoFactorNotSetup = errors.New("请先获取两步验证密钥")
+	// ErrInvalidTwoFactorCode 验证码或恢复码错误，或者验证码已经使用过
+	ErrInvalidTwoFactorCode = errors.New("验证码错误")
+	// ErrTwoFactorRequired 当前角色必须开启两步验证，不能关闭
+	ErrTwoFactorRequired = errors.New("当前角色必须开启两步验证")
+)
+
+// TwoFactorSetup 绑定验证器时返回给用户的密钥和二维码地址
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// 地址，前端渲染为二维码
+}
+
+// TwoFactorStatus 用户的两步验证状态
+type TwoFactorStatus struct {
+	Enabled           bool   `json:"enabled"`
+	Required          bool   `json:"required"` // 当前角色是否必须开启
+	EnabledAt         string `json:"enabled_at"`
+	RecoveryCodesLeft int    `json:"recovery_codes_left"`
+}
+
+// TwoFactorChallenge 登录时需要两步验证，返回临时令牌代替访问令牌
+type TwoFactorChallenge struct {
+	Code               string `json:"code"`
+	InterimToken       string `json:"interim_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"` // 为 true 时需要先绑定验证器
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+// TwoFactorService 两步验证（TOTP）服务
+//
+// 用户获取密钥并提交一次正确的验证码后开启两步验证，同时生成一组一次性恢复码。
+// 开启后登录分两步：密码验证通过后返回临时令牌，提交验证码或恢复码后才签发访问令牌。
+type TwoFactorService struct {
+	repo  repository.TwoFactorRepository
+	users repository.UserRepository
+	audit repository.AuditRepository
+	conf  config.TwoFactorConfig
+}
+
+// NewTwoFactorService 创建两步验证服务，conf 通常为 config.Conf.TwoFactor
+func NewTwoFactorService(repo repository.TwoFactorRepository, users repository.UserRepository, audit repository.AuditRepository, conf config.TwoFactorConfig) *TwoFactorService {
+	return &TwoFactorService{repo: repo, users: users, audit: audit, conf: conf}
+}
+
+// Required 判断策略是否要求该角色开启两步验证
+func (s *TwoFactorService) Required(role string) bool {
+	return s.conf.RequireForAdmins && models.IsAdminRole(role)
+}
+
+// Status 查询用户的两步验证状态
+func (s *TwoFactorService) Status(ctx context.Context, user *models.User) (*TwoFactorStatus, error) {
+	status := &TwoFactorStatus{Required: s.Required(user.Role)}
+	tf, err := s.get(ctx, user.ID)
+	if err != nil || tf == nil || !tf.Enabled {
+		return status, err
+	}
+	status.Enabled = true
+	status.EnabledAt = tf.EnabledAt
+	status.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, user.ID)
+	return status, err
+}
+
+// Challenge 密码验证通过后调用，不需要两步验证时返回 nil
+func (s *TwoFactorService) Challenge(ctx context.Context, user *models.User) (*TwoFactorChallenge, error) {
+	tf, err := s.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := tf != nil && tf.Enabled
+	if !enabled && !s.Required(user.Role) {
+		return nil, nil
+	}
+
+	// 临时令牌绑定当前密码，密码被修改后失效
+	ttl := s.conf.ChallengeTTL.Duration
+	token, err := SignActionToken(PurposeTwoFactorLogin, user.ID, PasswordFingerprint(user.Password), ttl)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &TwoFactorChallenge{
+		Code:               models.CodeTwoFactorRequired,
+		InterimToken:       token,
+		EnrollmentRequired: !enabled,
+		ExpiresIn:          int64(ttl / time.Second),
+	}
+	if !enabled {
+		challenge.Code = models.CodeTwoFactorEnrollmentRequired
+	}
+	return challenge, nil
+}
+
+// ParseChallenge 校验临时令牌并返回对应的用户，返回的用户不包含密码
+func (s *TwoFactorService) ParseChallenge(ctx context.Context, interimToken string) (*models.User, error) {
+	claims, err := ParseActionToken(PurposeTwoFactorLogin, interimToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidActionToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.users.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !MatchBinding(claims, PasswordFingerprint(hash)) {
+		return nil, ErrInvalidActionToken
+	}
+	return user, nil
+}
+
+// Setup 生成新的密钥，在开启之前可以重复调用，每次都会替换之前未开启的密钥
+func (s *TwoFactorService) Setup(ctx context.Context, user *models.User) (*TwoFactorSetup, error) {
+	tf, err := s.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePending(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(s.conf.Issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 校验验证器生成的验证码并开启两步验证，返回恢复码的明文，明文只在这里返回一次
+func (s *TwoFactorService) Enable(ctx context.Context, user *models.User, code, ip string) ([]string, error) {
+	tf, err := s.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorNotSetup
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := ValidateTOTP(tf.Secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(s.conf.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Format(models.TimeLayout)
+	enabled, err := s.repo.Enable(ctx, user.ID, step, hashes, now)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled { // 并发请求已经先一步开启
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	return codes, s.writeAudit(ctx, models.AuditTwoFactorEnabled, user.ID, user.Username, ip, "")
+}
+
+// Verify 校验验证码或恢复码，6 位数字按验证码处理，其他按恢复码处理，恢复码使用后失效
+func (s *TwoFactorService) Verify(ctx context.Context, user *models.User, code, ip string) error {
+	tf, err := s.get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if tf == nil || !tf.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = normalizeCode(code)
+	if len(code) == totpDigits {
+		step, ok := ValidateTOTP(tf.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		// 同一个时间步（以及更早的时间步）的验证码只能使用一次
+		used, err := s.repo.UseStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, user.ID, hashToken(code), time.Now().Format(models.TimeLayout))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	left, err := s.repo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	return s.writeAudit(ctx, models.AuditRecoveryCodeUsed, user.ID, user.Username, ip, fmt.Sprintf("剩余恢复码 %d 个", left))
+}
+
+// Disable 关闭两步验证，策略要求该角色开启时不允许关闭
+func (s *TwoFactorService) Disable(ctx context.Context, user *models.User, ip string) error {
+	if s.Required(user.Role) {
+		return ErrTwoFactorRequired
+	}
+	if err := s.repo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.writeAudit(ctx, models.AuditTwoFactorDisabled, user.ID, user.Username, ip, "")
+}
+
+// Reset 管理员为丢失验证器和恢复码的用户关闭两步验证，策略要求时该用户下次登录需要重新绑定
+func (s *TwoFactorService) Reset(ctx context.Context, user *models.User, actorID int, ip string) error {
+	tf, err := s.get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if tf == nil || !tf.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.repo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.writeAudit(ctx, models.AuditTwoFactorDisabled, actorID, user.Username, ip, "管理员重置")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *models.User, ip string) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes(s.conf.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, s.writeAudit(ctx, models.AuditRecoveryCodesRenewed, user.ID, user.Username, ip, "")
+}
+
+// get 查询两步验证设置，未设置时返回 nil
+func (s *TwoFactorService) get(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return tf, err
+}
+
+func (s *TwoFactorService) writeAudit(ctx context.Context, action string, actorID int, target, ip, detail string) error {
+	return s.audit.Create(ctx, &models.AuditLog{
+		Action:    action,
+		ActorID:   actorID,
+		Target:    target,
+		IP:        ip,
+		Detail:    detail,
+		CreatedAt: time.Now().Format(models.TimeLayout),
+	})
+}
+
+// recoveryCodeAlphabet 恢复码使用的字符，去掉了容易混淆的 0、1、l、o
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// generateRecoveryCodes 生成 n 个恢复码，格式为 xxxxx-xxxxx，同时返回用于保存的哈希
+func generateRecoveryCodes(n int) (codes []string, hashes []string, err error) {
+	for i := 0; i < n; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[int(b[j])%len(recoveryCodeAlphabet)]
+		}
+		code := string(b)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeCode 去掉用户输入中的空格和连字符，恢复码不区分大小写
+func normalizeCode(code string) string {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+	return strings.ToLower(code)
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"backend/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// codeAt 计算密钥在指定时间步的验证码
+func codeAt(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, step)
+}
+
+func TestTwoFactorVerifyRejectsReplay(t *testing.T) {
+	conf := setupConfig(t)
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	user := createUser(t, repos, "alice", "")
+	service := NewTwoFactorService(repos.TwoFactor, repos.Users, repos.Audit, conf.TwoFactor)
+
+	setup, err := service.Setup(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := time.Now().Unix() / totpPeriod
+	recoveryCodes, err := service.Enable(ctx, user, codeAt(t, setup.Secret, step), "")
+	if err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+
+	// 开启时使用的验证码，以及更早时间步的验证码，都不能再用于登录
+	for name, code := range map[string]string{
+		"开启时使用的验证码": codeAt(t, setup.Secret, step),
+		"更早时间步的验证码": codeAt(t, setup.Secret, step-1),
+	} {
+		if err := service.Verify(ctx, user, code, ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("%s Verify() error = %v，期望 %v", name, err, ErrInvalidTwoFactorCode)
+		}
+	}
+
+	// 下一个时间步的验证码在允许的误差内，只能使用一次
+	next := codeAt(t, setup.Secret, step+1)
+	if err := service.Verify(ctx, user, next, ""); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := service.Verify(ctx, user, next, ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("重复使用验证码 Verify() error = %v，期望 %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	// 恢复码同样只能使用一次
+	if err := service.Verify(ctx, user, recoveryCodes[0], ""); err != nil {
+		t.Fatalf("使用恢复码 Verify() error = %v", err)
+	}
+	if err := service.Verify(ctx, user, recoveryCodes[0], ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("重复使用恢复码 Verify() error = %v，期望 %v", err, ErrInvalidTwoFactorCode)
+	}
+	status, err := service.Status(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(recoveryCodes) - 1; status.RecoveryCodesLeft != want {
+		t.Fatalf("剩余恢复码 %d 个，期望 %d 个", status.RecoveryCodesLeft, want)
+	}
+}
+
+func TestTwoFactorVerifyNotEnabled(t *testing.T) {
+	conf := setupConfig(t)
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	user := createUser(t, repos, "alice", "")
+	service := NewTwoFactorService(repos.TwoFactor, repos.Users, repos.Audit, conf.TwoFactor)
+
+	if err := service.Verify(ctx, user, "123456", ""); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("未开启时 Verify() error = %v，期望 %v", err, ErrTwoFactorNotEnabled)
+	}
+	// 获取密钥但还没有开启时同样视为未开启
+	setup, err := service.Setup(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codeAt(t, setup.Secret, time.Now().Unix()/totpPeriod)
+	if err := service.Verify(ctx, user, code, ""); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("开启前 Verify() error = %v，期望 %v", err, ErrTwoFactorNotEnabled)
+	}
+}
@@ -0,0 +1,743 @@
+package config
+
+import (
+	"backend/i18n"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2" // TOML 配置文件解析
+	"gopkg.in/yaml.v3"                // YAML 配置文件解析
+)
+
+// Conf 全局配置实例，由 Init 在程序启动时加载
+var Conf *Config
+
+// Config 应用的完整配置
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	JWT       JWTConfig       `yaml:"jwt" toml:"jwt"`
+	Login     LoginConfig     `yaml:"login" toml:"login"`
+	Password  PasswordConfig  `yaml:"password" toml:"password"`
+	TwoFactor TwoFactorConfig `yaml:"two_factor" toml:"two_factor"`
+	Mail      MailConfig      `yaml:"mail" toml:"mail"`
+	Upload    UploadConfig    `yaml:"upload" toml:"upload"`
+	I18n      I18nConfig      `yaml:"i18n" toml:"i18n"`
+	Scheduler SchedulerConfig `yaml:"scheduler" toml:"scheduler"`
+	Admin     AdminConfig     `yaml:"admin" toml:"admin"`
+}
+
+// ServerConfig HTTP 服务相关配置
+type ServerConfig struct {
+	Addr              string    `yaml:"addr" toml:"addr"`             // 监听地址，例如 ":8080"
+	StaticDir         string    `yaml:"static_dir" toml:"static_dir"` // 静态文件目录，映射到 /static
+	ReadHeaderTimeout Duration  `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ReadTimeout       Duration  `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout      Duration  `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration  `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   Duration  `yaml:"shutdown_timeout" toml:"shutdown_timeout"` // 关闭时等待进行中请求完成的最长时间，之后执行清理函数也使用同样的时间
+	TrustedProxies    []string  `yaml:"trusted_proxies" toml:"trusted_proxies"`   // 可信的反向代理地址或网段，只有来自这些地址的 X-Forwarded-For 才会被采信
+	TLS               TLSConfig `yaml:"tls" toml:"tls"`
+}
+
+// TLSConfig HTTPS 证书配置
+type TLSConfig struct {
+	Enabled      bool       `yaml:"enabled" toml:"enabled"`
+	CertFile     string     `yaml:"cert_file" toml:"cert_file"`         // 证书文件路径，收到 SIGHUP 时重新加载
+	KeyFile      string     `yaml:"key_file" toml:"key_file"`           // 私钥文件路径
+	RedirectAddr string     `yaml:"redirect_addr" toml:"redirect_addr"` // HTTP 重定向监听地址，例如 ":80"，为空则不启动
+	HSTS         HSTSConfig `yaml:"hsts" toml:"hsts"`
+}
+
+// HSTSConfig Strict-Transport-Security 响应头配置，仅对 HTTPS 请求生效
+type HSTSConfig struct {
+	MaxAge            Duration `yaml:"max_age" toml:"max_age"` // 为 0 时不发送该响应头
+	IncludeSubdomains bool     `yaml:"include_subdomains" toml:"include_subdomains"`
+}
+
+// DatabaseConfig 数据库连接及连接池配置
+type DatabaseConfig struct {
+	Driver          string   `yaml:"driver" toml:"driver"` // mysql、sqlite 或 memory
+	DSN             string   `yaml:"dsn" toml:"dsn"`       // MySQL：username:password@protocol(address)/dbname；SQLite：文件路径
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	AutoMigrate     bool     `yaml:"auto_migrate" toml:"auto_migrate"` // 启动时自动执行未执行的迁移
+}
+
+// JWTConfig 令牌签名配置
+type JWTConfig struct {
+	Secret          string   `yaml:"secret" toml:"secret"`                       // 签名密钥，至少 32 个字符
+	AccessTokenTTL  Duration `yaml:"access_token_ttl" toml:"access_token_ttl"`   // 访问令牌有效期，应尽量短
+	RefreshTokenTTL Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"` // 刷新令牌有效期，每次刷新都会轮换
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	MaxFailures     int      `yaml:"max_failures" toml:"max_failures"`         // 连续失败多少次后锁定账号，0 表示不锁定
+	LockoutDuration Duration `yaml:"lockout_duration" toml:"lockout_duration"` // 账号锁定时长
+	FailureWindow   Duration `yaml:"failure_window" toml:"failure_window"`     // 超过该时间没有再失败则重新计数
+	DelayBase       Duration `yaml:"delay_base" toml:"delay_base"`             // 失败后需要等待的时间，每多失败一次翻倍，0 表示不延迟
+	MaxDelay        Duration `yaml:"max_delay" toml:"max_delay"`               // 等待时间的上限
+	IPRateLimit     int      `yaml:"ip_rate_limit" toml:"ip_rate_limit"`       // 每个 IP 在 ip_rate_window 内最多的登录请求数，0 表示不限制
+	IPRateWindow    Duration `yaml:"ip_rate_window" toml:"ip_rate_window"`
+}
+
+// PasswordConfig 密码策略配置，注册、添加用户、修改密码和找回密码共用同一套规则
+type PasswordConfig struct {
+	MinLength      int                `yaml:"min_length" toml:"min_length"`
+	MaxLength      int                `yaml:"max_length" toml:"max_length"`
+	RequireLower   bool               `yaml:"require_lower" toml:"require_lower"`       // 必须包含小写字母
+	RequireUpper   bool               `yaml:"require_upper" toml:"require_upper"`       // 必须包含大写字母
+	RequireDigit   bool               `yaml:"require_digit" toml:"require_digit"`       // 必须包含数字
+	RequireSpecial bool               `yaml:"require_special" toml:"require_special"`   // 必须包含字母和数字以外的字符
+	CommonListFile string             `yaml:"common_list_file" toml:"common_list_file"` // 禁止使用的常见密码或已泄露密码列表，为空表示不检查
+	HistorySize    int                `yaml:"history_size" toml:"history_size"`         // 新密码不能与最近使用过的 N 个密码相同，0 表示不检查
+	Hash           PasswordHashConfig `yaml:"hash" toml:"hash"`
+}
+
+// PasswordHashConfig 密码哈希算法配置，修改后已有用户会在下次登录时自动按新配置重新计算哈希
+type PasswordHashConfig struct {
+	Algorithm         string `yaml:"algorithm" toml:"algorithm"` // bcrypt 或 argon2id
+	BcryptCost        int    `yaml:"bcrypt_cost" toml:"bcrypt_cost"`
+	Argon2Memory      int    `yaml:"argon2_memory" toml:"argon2_memory"` // 内存开销，单位 KiB
+	Argon2Iterations  int    `yaml:"argon2_iterations" toml:"argon2_iterations"`
+	Argon2Parallelism int    `yaml:"argon2_parallelism" toml:"argon2_parallelism"`
+}
+
+// TwoFactorConfig 两步验证（TOTP）配置
+type TwoFactorConfig struct {
+	Issuer           string   `yaml:"issuer" toml:"issuer"`                         // 验证器应用中显示的服务名称
+	RequireForAdmins bool     `yaml:"require_for_admins" toml:"require_for_admins"` // 要求所有管理员角色开启两步验证，未开启的管理员登录时必须先绑定
+	ChallengeTTL     Duration `yaml:"challenge_ttl" toml:"challenge_ttl"`           // 密码验证通过后，提交两步验证码的时限
+	RecoveryCodes    int      `yaml:"recovery_codes" toml:"recovery_codes"`         // 生成的恢复码数量
+}
+
+// MailConfig 邮件发送配置，用于找回密码和验证邮箱
+type MailConfig struct {
+	Driver           string     `yaml:"driver" toml:"driver"`     // smtp、file（保存为 .eml 文件）或 log（输出到日志，仅用于本地测试）
+	From             string     `yaml:"from" toml:"from"`         // 发件人地址
+	FileDir          string     `yaml:"file_dir" toml:"file_dir"` // file 驱动保存邮件的目录
+	SMTP             SMTPConfig `yaml:"smtp" toml:"smtp"`
+	ResetPasswordURL string     `yaml:"reset_password_url" toml:"reset_password_url"` // 前端重置密码页面地址，令牌以 token 参数附加在后面
+	VerifyEmailURL   string     `yaml:"verify_email_url" toml:"verify_email_url"`     // 前端验证邮箱页面地址
+	ResetTokenTTL    Duration   `yaml:"reset_token_ttl" toml:"reset_token_ttl"`       // 重置密码链接的有效期
+	VerifyTokenTTL   Duration   `yaml:"verify_token_ttl" toml:"verify_token_ttl"`     // 验证邮箱链接的有效期
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	TLS      string `yaml:"tls" toml:"tls"` // starttls（默认，通常为 587 端口）、tls（465 端口）或 none
+}
+
+// I18nConfig 接口消息的语言配置
+type I18nConfig struct {
+	DefaultLocale string `yaml:"default_locale" toml:"default_locale"` // 请求没有 Accept-Language 且用户没有设置语言偏好时使用的语言
+}
+
+// SchedulerConfig 定时发布和下线文章的后台任务配置
+// 多个实例同时开启时，每篇文章只会被其中一个实例处理
+type SchedulerConfig struct {
+	Enabled   bool     `yaml:"enabled" toml:"enabled"`
+	Interval  Duration `yaml:"interval" toml:"interval"`     // 检查到期文章的间隔
+	BatchSize int      `yaml:"batch_size" toml:"batch_size"` // 每次检查时定时发布和定时下线各自最多处理的文章数
+}
+
+// AdminConfig 初始管理员，服务启动时该用户名不存在则创建，已存在时不做任何修改
+// 注册接口创建的用户都是访客，新部署的第一个管理员通过这里创建
+type AdminConfig struct {
+	Username string `yaml:"username" toml:"username"` // 为空表示不创建
+	Password string `yaml:"password" toml:"password"` // 按密码策略校验，建议创建后登录修改并从配置中删除
+	Email    string `yaml:"email" toml:"email"`
+}
+
+// UploadConfig 文件上传配置
+type UploadConfig struct {
+	MaxSizeMB  int64    `yaml:"max_size_mb" toml:"max_size_mb"` // 单个文件大小上限（MB）
+	AllowedExt []string `yaml:"allowed_ext" toml:"allowed_ext"` // 允许上传的文件扩展名
+}
+
+// supportedImageExt 上传接口能够解码处理的图片格式
+var supportedImageExt = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true}
+
+// MaxSizeBytes 返回以字节为单位的上传大小上限
+func (u UploadConfig) MaxSizeBytes() int64 {
+	return u.MaxSizeMB * 1024 * 1024
+}
+
+// Duration 包装 time.Duration，使其可以从 "30m"、"720h" 这样的字符串解析
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，YAML 和 TOML 解析器都会调用它
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Default 返回带有默认值的配置，文件和环境变量中的值会覆盖这些默认值
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			StaticDir:         "./static",
+			ReadHeaderTimeout: Duration{10 * time.Second},
+			ReadTimeout:       Duration{60 * time.Second},
+			WriteTimeout:      Duration{60 * time.Second},
+			IdleTimeout:       Duration{120 * time.Second},
+			ShutdownTimeout:   Duration{30 * time.Second},
+			TLS: TLSConfig{
+				HSTS: HSTSConfig{MaxAge: Duration{365 * 24 * time.Hour}},
+			},
+		},
+		Database: DatabaseConfig{
+			Driver:          "mysql",
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration{time.Hour},
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  Duration{15 * time.Minute},
+			RefreshTokenTTL: Duration{30 * 24 * time.Hour},
+		},
+		Login: LoginConfig{
+			MaxFailures:     5,
+			LockoutDuration: Duration{15 * time.Minute},
+			FailureWindow:   Duration{15 * time.Minute},
+			DelayBase:       Duration{time.Second},
+			MaxDelay:        Duration{30 * time.Second},
+			IPRateLimit:     20,
+			IPRateWindow:    Duration{time.Minute},
+		},
+		Password: PasswordConfig{
+			MinLength:      6,
+			MaxLength:      30,
+			RequireLower:   true,
+			RequireUpper:   true,
+			RequireDigit:   true,
+			RequireSpecial: true,
+			HistorySize:    5,
+			Hash: PasswordHashConfig{
+				Algorithm:         "bcrypt",
+				BcryptCost:        10,
+				Argon2Memory:      64 * 1024,
+				Argon2Iterations:  3,
+				Argon2Parallelism: 2,
+			},
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        "Blog",
+			ChallengeTTL:  Duration{5 * time.Minute},
+			RecoveryCodes: 10,
+		},
+		Mail: MailConfig{
+			Driver:           "log",
+			From:             "noreply@localhost",
+			FileDir:          "./mail",
+			SMTP:             SMTPConfig{Port: 587, TLS: "starttls"},
+			ResetPasswordURL: "http://localhost:8080/reset-password",
+			VerifyEmailURL:   "http://localhost:8080/verify-email",
+			ResetTokenTTL:    Duration{30 * time.Minute},
+			VerifyTokenTTL:   Duration{24 * time.Hour},
+		},
+		Upload: UploadConfig{
+			MaxSizeMB:  20,
+			AllowedExt: []string{".jpg", ".jpeg", ".png", ".gif"},
+		},
+		I18n: I18nConfig{
+			DefaultLocale: i18n.ZhCN,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:   true,
+			Interval:  Duration{time.Minute},
+			BatchSize: 100,
+		},
+	}
+}
+
+// Init 加载配置并设置全局的 Conf 和 JwtSecret
+func Init(path string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	Conf = cfg
+	JwtSecret = []byte(cfg.JWT.Secret)
+	return nil
+}
+
+// Load 依次应用默认值、配置文件和环境变量，最后校验配置
+// path 为空时只使用默认值和环境变量；文件扩展名决定解析格式（.yaml/.yml/.toml）
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile 读取并解析配置文件
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s（仅支持 .yaml/.yml/.toml）", path)
+	}
+	if err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// envBinding 描述一个环境变量与配置项的对应关系
+type envBinding struct {
+	name string
+	set  func(value string) error
+}
+
+// envBindings 返回所有支持的环境变量，环境变量优先级高于配置文件
+func (c *Config) envBindings() []envBinding {
+	return []envBinding{
+		{"BLOG_SERVER_ADDR", setString(&c.Server.Addr)},
+		{"BLOG_STATIC_DIR", setString(&c.Server.StaticDir)},
+		{"BLOG_SERVER_READ_HEADER_TIMEOUT", setDuration(&c.Server.ReadHeaderTimeout)},
+		{"BLOG_SERVER_READ_TIMEOUT", setDuration(&c.Server.ReadTimeout)},
+		{"BLOG_SERVER_WRITE_TIMEOUT", setDuration(&c.Server.WriteTimeout)},
+		{"BLOG_SERVER_IDLE_TIMEOUT", setDuration(&c.Server.IdleTimeout)},
+		{"BLOG_SERVER_SHUTDOWN_TIMEOUT", setDuration(&c.Server.ShutdownTimeout)},
+		{"BLOG_SERVER_TRUSTED_PROXIES", setList(&c.Server.TrustedProxies)},
+		{"BLOG_TLS_ENABLED", setBool(&c.Server.TLS.Enabled)},
+		{"BLOG_TLS_CERT_FILE", setString(&c.Server.TLS.CertFile)},
+		{"BLOG_TLS_KEY_FILE", setString(&c.Server.TLS.KeyFile)},
+		{"BLOG_TLS_REDIRECT_ADDR", setString(&c.Server.TLS.RedirectAddr)},
+		{"BLOG_TLS_HSTS_MAX_AGE", setDuration(&c.Server.TLS.HSTS.MaxAge)},
+		{"BLOG_TLS_HSTS_INCLUDE_SUBDOMAINS", setBool(&c.Server.TLS.HSTS.IncludeSubdomains)},
+		{"BLOG_DB_DRIVER", setString(&c.Database.Driver)},
+		{"BLOG_DB_DSN", setString(&c.Database.DSN)},
+		{"BLOG_DB_MAX_OPEN_CONNS", setInt(&c.Database.MaxOpenConns)},
+		{"BLOG_DB_MAX_IDLE_CONNS", setInt(&c.Database.MaxIdleConns)},
+		{"BLOG_DB_CONN_MAX_LIFETIME", setDuration(&c.Database.ConnMaxLifetime)},
+		{"BLOG_DB_AUTO_MIGRATE", setBool(&c.Database.AutoMigrate)},
+		{"BLOG_JWT_SECRET", setString(&c.JWT.Secret)},
+		{"BLOG_JWT_ACCESS_TOKEN_TTL", setDuration(&c.JWT.AccessTokenTTL)},
+		{"BLOG_JWT_REFRESH_TOKEN_TTL", setDuration(&c.JWT.RefreshTokenTTL)},
+		{"BLOG_LOGIN_MAX_FAILURES", setInt(&c.Login.MaxFailures)},
+		{"BLOG_LOGIN_LOCKOUT_DURATION", setDuration(&c.Login.LockoutDuration)},
+		{"BLOG_LOGIN_FAILURE_WINDOW", setDuration(&c.Login.FailureWindow)},
+		{"BLOG_LOGIN_DELAY_BASE", setDuration(&c.Login.DelayBase)},
+		{"BLOG_LOGIN_MAX_DELAY", setDuration(&c.Login.MaxDelay)},
+		{"BLOG_LOGIN_IP_RATE_LIMIT", setInt(&c.Login.IPRateLimit)},
+		{"BLOG_LOGIN_IP_RATE_WINDOW", setDuration(&c.Login.IPRateWindow)},
+		{"BLOG_PASSWORD_MIN_LENGTH", setInt(&c.Password.MinLength)},
+		{"BLOG_PASSWORD_MAX_LENGTH", setInt(&c.Password.MaxLength)},
+		{"BLOG_PASSWORD_REQUIRE_LOWER", setBool(&c.Password.RequireLower)},
+		{"BLOG_PASSWORD_REQUIRE_UPPER", setBool(&c.Password.RequireUpper)},
+		{"BLOG_PASSWORD_REQUIRE_DIGIT", setBool(&c.Password.RequireDigit)},
+		{"BLOG_PASSWORD_REQUIRE_SPECIAL", setBool(&c.Password.RequireSpecial)},
+		{"BLOG_PASSWORD_COMMON_LIST_FILE", setString(&c.Password.CommonListFile)},
+		{"BLOG_PASSWORD_HISTORY_SIZE", setInt(&c.Password.HistorySize)},
+		{"BLOG_PASSWORD_HASH_ALGORITHM", setString(&c.Password.Hash.Algorithm)},
+		{"BLOG_PASSWORD_BCRYPT_COST", setInt(&c.Password.Hash.BcryptCost)},
+		{"BLOG_PASSWORD_ARGON2_MEMORY", setInt(&c.Password.Hash.Argon2Memory)},
+		{"BLOG_PASSWORD_ARGON2_ITERATIONS", setInt(&c.Password.Hash.Argon2Iterations)},
+		{"BLOG_PASSWORD_ARGON2_PARALLELISM", setInt(&c.Password.Hash.Argon2Parallelism)},
+		{"BLOG_TWO_FACTOR_ISSUER", setString(&c.TwoFactor.Issuer)},
+		{"BLOG_TWO_FACTOR_REQUIRE_FOR_ADMINS", setBool(&c.TwoFactor.RequireForAdmins)},
+		{"BLOG_TWO_FACTOR_CHALLENGE_TTL", setDuration(&c.TwoFactor.ChallengeTTL)},
+		{"BLOG_TWO_FACTOR_RECOVERY_CODES", setInt(&c.TwoFactor.RecoveryCodes)},
+		{"BLOG_MAIL_DRIVER", setString(&c.Mail.Driver)},
+		{"BLOG_MAIL_FROM", setString(&c.Mail.From)},
+		{"BLOG_MAIL_FILE_DIR", setString(&c.Mail.FileDir)},
+		{"BLOG_SMTP_HOST", setString(&c.Mail.SMTP.Host)},
+		{"BLOG_SMTP_PORT", setInt(&c.Mail.SMTP.Port)},
+		{"BLOG_SMTP_USERNAME", setString(&c.Mail.SMTP.Username)},
+		{"BLOG_SMTP_PASSWORD", setString(&c.Mail.SMTP.Password)},
+		{"BLOG_SMTP_TLS", setString(&c.Mail.SMTP.TLS)},
+		{"BLOG_MAIL_RESET_PASSWORD_URL", setString(&c.Mail.ResetPasswordURL)},
+		{"BLOG_MAIL_VERIFY_EMAIL_URL", setString(&c.Mail.VerifyEmailURL)},
+		{"BLOG_MAIL_RESET_TOKEN_TTL", setDuration(&c.Mail.ResetTokenTTL)},
+		{"BLOG_MAIL_VERIFY_TOKEN_TTL", setDuration(&c.Mail.VerifyTokenTTL)},
+		{"BLOG_UPLOAD_MAX_SIZE_MB", setInt64(&c.Upload.MaxSizeMB)},
+		{"BLOG_UPLOAD_ALLOWED_EXT", setList(&c.Upload.AllowedExt)},
+		{"BLOG_I18N_DEFAULT_LOCALE", setString(&c.I18n.DefaultLocale)},
+		{"BLOG_SCHEDULER_ENABLED", setBool(&c.Scheduler.Enabled)},
+		{"BLOG_SCHEDULER_INTERVAL", setDuration(&c.Scheduler.Interval)},
+		{"BLOG_SCHEDULER_BATCH_SIZE", setInt(&c.Scheduler.BatchSize)},
+		{"BLOG_ADMIN_USERNAME", setString(&c.Admin.Username)},
+		{"BLOG_ADMIN_PASSWORD", setString(&c.Admin.Password)},
+		{"BLOG_ADMIN_EMAIL", setString(&c.Admin.Email)},
+	}
+}
+
+// applyEnv 使用环境变量覆盖配置
+func (c *Config) applyEnv() error {
+	for _, b := range c.envBindings() {
+		value, ok := os.LookupEnv(b.name)
+		if !ok {
+			continue
+		}
+		if err := b.set(value); err != nil {
+			return fmt.Errorf("环境变量 %s 的值无效: %w", b.name, err)
+		}
+	}
+	return nil
+}
+
+func setString(p *string) func(string) error {
+	return func(v string) error {
+		*p = v
+		return nil
+	}
+}
+
+func setBool(p *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*p = b
+		return nil
+	}
+}
+
+func setInt(p *int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*p = n
+		return nil
+	}
+}
+
+func setInt64(p *int64) func(string) error {
+	return func(v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*p = n
+		return nil
+	}
+}
+
+func setDuration(p *Duration) func(string) error {
+	return func(v string) error {
+		return p.UnmarshalText([]byte(v))
+	}
+}
+
+// setList 解析以逗号分隔的列表
+func setList(p *[]string) func(string) error {
+	return func(v string) error {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*p = items
+		return nil
+	}
+}
+
+// ValidationError 汇总配置校验中发现的所有问题
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("配置校验失败，共 %d 个问题:", len(e.Problems)))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Validate 检查配置是否完整、合法，返回的错误中包含全部问题而不是第一个
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Addr == "" {
+		addf("server.addr 不能为空")
+	}
+	if c.Server.StaticDir == "" {
+		addf("server.static_dir 不能为空")
+	}
+	for _, t := range []struct {
+		name  string
+		value Duration
+	}{
+		{"read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"read_timeout", c.Server.ReadTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+	} {
+		if t.value.Duration < 0 {
+			addf("server.%s 不能为负数", t.name)
+		}
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		addf("server.shutdown_timeout 必须大于 0")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				addf("server.trusted_proxies 中的 %q 不是合法的 IP 地址或网段", proxy)
+			}
+		}
+	}
+	if c.Server.TLS.Enabled {
+		if c.Server.TLS.CertFile == "" {
+			addf("server.tls.cert_file 不能为空（已启用 TLS）")
+		} else if _, err := os.Stat(c.Server.TLS.CertFile); err != nil {
+			addf("server.tls.cert_file 无法访问: %v", err)
+		}
+		if c.Server.TLS.KeyFile == "" {
+			addf("server.tls.key_file 不能为空（已启用 TLS）")
+		} else if _, err := os.Stat(c.Server.TLS.KeyFile); err != nil {
+			addf("server.tls.key_file 无法访问: %v", err)
+		}
+		if c.Server.TLS.RedirectAddr != "" && c.Server.TLS.RedirectAddr == c.Server.Addr {
+			addf("server.tls.redirect_addr 不能与 server.addr 相同")
+		}
+		if c.Server.TLS.HSTS.MaxAge.Duration < 0 {
+			addf("server.tls.hsts.max_age 不能为负数")
+		}
+	}
+
+	switch c.Database.Driver {
+	case "mysql", "sqlite":
+		if c.Database.DSN == "" {
+			addf("database.dsn 不能为空")
+		}
+	case "memory":
+		// 内存存储不需要连接串
+	default:
+		addf("database.driver 不支持 %q（可选值: mysql, sqlite, memory）", c.Database.Driver)
+	}
+	if c.Database.MaxOpenConns < 0 {
+		addf("database.max_open_conns 不能为负数")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		addf("database.max_idle_conns 不能为负数")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		addf("database.max_idle_conns (%d) 不能大于 max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime.Duration < 0 {
+		addf("database.conn_max_lifetime 不能为负数")
+	}
+
+	if len(c.JWT.Secret) < 32 {
+		addf("jwt.secret 长度至少为 32 个字符")
+	}
+	if c.JWT.AccessTokenTTL.Duration <= 0 {
+		addf("jwt.access_token_ttl 必须大于 0")
+	}
+	if c.JWT.RefreshTokenTTL.Duration <= c.JWT.AccessTokenTTL.Duration {
+		addf("jwt.refresh_token_ttl (%s) 必须大于 access_token_ttl (%s)", c.JWT.RefreshTokenTTL.Duration, c.JWT.AccessTokenTTL.Duration)
+	}
+
+	if c.Login.MaxFailures < 0 {
+		addf("login.max_failures 不能为负数")
+	}
+	if c.Login.MaxFailures > 0 && c.Login.LockoutDuration.Duration <= 0 {
+		addf("login.lockout_duration 必须大于 0（已启用账号锁定）")
+	}
+	if c.Login.FailureWindow.Duration <= 0 {
+		addf("login.failure_window 必须大于 0")
+	}
+	if c.Login.DelayBase.Duration < 0 {
+		addf("login.delay_base 不能为负数")
+	}
+	if c.Login.MaxDelay.Duration < c.Login.DelayBase.Duration {
+		addf("login.max_delay (%s) 不能小于 delay_base (%s)", c.Login.MaxDelay.Duration, c.Login.DelayBase.Duration)
+	}
+	if c.Login.IPRateLimit < 0 {
+		addf("login.ip_rate_limit 不能为负数")
+	}
+	if c.Login.IPRateLimit > 0 && c.Login.IPRateWindow.Duration <= 0 {
+		addf("login.ip_rate_window 必须大于 0（已启用 IP 限流）")
+	}
+
+	if c.Password.MinLength < 1 {
+		addf("password.min_length 必须大于 0")
+	}
+	if c.Password.MaxLength < c.Password.MinLength || c.Password.MaxLength > 128 {
+		addf("password.max_length 必须在 min_length 和 128 之间")
+	}
+	if c.Password.HistorySize < 0 || c.Password.HistorySize > 24 {
+		addf("password.history_size 必须在 0-24 之间")
+	}
+	if c.Password.CommonListFile != "" {
+		if _, err := os.Stat(c.Password.CommonListFile); err != nil {
+			addf("password.common_list_file 无法读取: %v", err)
+		}
+	}
+	switch hash := c.Password.Hash; hash.Algorithm {
+	case "bcrypt":
+		// bcrypt 只使用前 72 个字节，更长的密码会被拒绝
+		if hash.BcryptCost < 4 || hash.BcryptCost > 31 {
+			addf("password.hash.bcrypt_cost 必须在 4-31 之间")
+		}
+		if c.Password.MaxLength > 72 {
+			addf("password.max_length 使用 bcrypt 时不能超过 72")
+		}
+	case "argon2id":
+		if hash.Argon2Iterations < 1 {
+			addf("password.hash.argon2_iterations 必须大于 0")
+		}
+		if hash.Argon2Parallelism < 1 || hash.Argon2Parallelism > 255 {
+			addf("password.hash.argon2_parallelism 必须在 1-255 之间")
+		}
+		if hash.Argon2Memory < 8*hash.Argon2Parallelism {
+			addf("password.hash.argon2_memory 不能小于 8 * argon2_parallelism（KiB）")
+		}
+	default:
+		addf("password.hash.algorithm 不支持 %q（可选值: bcrypt, argon2id）", hash.Algorithm)
+	}
+
+	if c.TwoFactor.Issuer == "" || strings.Contains(c.TwoFactor.Issuer, ":") {
+		addf("two_factor.issuer 不能为空，且不能包含冒号")
+	}
+	if c.TwoFactor.ChallengeTTL.Duration <= 0 {
+		addf("two_factor.challenge_ttl 必须大于 0")
+	}
+	if c.TwoFactor.RecoveryCodes < 1 || c.TwoFactor.RecoveryCodes > 50 {
+		addf("two_factor.recovery_codes 必须在 1-50 之间")
+	}
+
+	switch c.Mail.Driver {
+	case "smtp":
+		if c.Mail.SMTP.Host == "" {
+			addf("mail.smtp.host 不能为空（mail.driver 为 smtp）")
+		}
+		if c.Mail.SMTP.Port <= 0 || c.Mail.SMTP.Port > 65535 {
+			addf("mail.smtp.port 必须在 1-65535 之间")
+		}
+		switch c.Mail.SMTP.TLS {
+		case "starttls", "tls", "none":
+		default:
+			addf("mail.smtp.tls 不支持 %q（可选值: starttls, tls, none）", c.Mail.SMTP.TLS)
+		}
+	case "file":
+		if c.Mail.FileDir == "" {
+			addf("mail.file_dir 不能为空（mail.driver 为 file）")
+		}
+	case "log":
+	default:
+		addf("mail.driver 不支持 %q（可选值: smtp, file, log）", c.Mail.Driver)
+	}
+	if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+		addf("mail.from 不是合法的邮箱地址: %v", err)
+	}
+	for _, u := range []struct {
+		name  string
+		value string
+	}{
+		{"reset_password_url", c.Mail.ResetPasswordURL},
+		{"verify_email_url", c.Mail.VerifyEmailURL},
+	} {
+		if parsed, err := url.Parse(u.value); err != nil || !parsed.IsAbs() {
+			addf("mail.%s 必须是完整的 URL", u.name)
+		}
+	}
+	if c.Mail.ResetTokenTTL.Duration <= 0 {
+		addf("mail.reset_token_ttl 必须大于 0")
+	}
+	if c.Mail.VerifyTokenTTL.Duration <= 0 {
+		addf("mail.verify_token_ttl 必须大于 0")
+	}
+
+	if c.Upload.MaxSizeMB <= 0 {
+		addf("upload.max_size_mb 必须大于 0")
+	}
+	if len(c.Upload.AllowedExt) == 0 {
+		addf("upload.allowed_ext 不能为空")
+	}
+	for _, ext := range c.Upload.AllowedExt {
+		if !supportedImageExt[strings.ToLower(ext)] {
+			addf("upload.allowed_ext 中的 %q 不受支持（可选值: .jpg, .jpeg, .png, .gif）", ext)
+		}
+	}
+
+	if !i18n.IsSupported(c.I18n.DefaultLocale) {
+		addf("i18n.default_locale 不支持 %q（可选值: %s）", c.I18n.DefaultLocale, strings.Join(i18n.Supported, ", "))
+	}
+
+	if c.Scheduler.Enabled {
+		if c.Scheduler.Interval.Duration <= 0 {
+			addf("scheduler.interval 必须大于 0")
+		}
+		if c.Scheduler.BatchSize <= 0 {
+			addf("scheduler.batch_size 必须大于 0")
+		}
+	}
+
+	if c.Admin.Username != "" {
+		if n := len([]rune(c.Admin.Username)); n > 20 {
+			addf("admin.username 长度不能超过 20 个字符")
+		}
+		if c.Admin.Password == "" {
+			addf("设置 admin.username 时必须设置 admin.password")
+		}
+		if c.Admin.Email != "" {
+			if _, err := mail.ParseAddress(c.Admin.Email); err != nil {
+				addf("admin.email 格式错误: %v", err)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
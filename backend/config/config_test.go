@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// validConfig 返回一份能通过校验的配置，各测试在此基础上修改
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Database.Driver = "memory"
+	cfg.JWT.Secret = testSecret
+	return cfg
+}
+
+// writeFile 在临时目录中写入配置文件并返回路径
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateAcceptsValidConfig(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate() = %v，期望 nil", err)
+	}
+}
+
+func TestValidateCollectsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Addr = ""
+	cfg.Server.ShutdownTimeout = Duration{}
+	cfg.Server.TrustedProxies = []string{"not-an-ip"}
+	cfg.Password.MaxLength = 80
+	cfg.Admin.Username = "root"
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v，期望 *ValidationError", err)
+	}
+
+	want := []string{
+		"server.addr",
+		"server.shutdown_timeout",
+		"not-an-ip",
+		"password.max_length",
+		"admin.password",
+	}
+	for _, w := range want {
+		found := false
+		for _, p := range verr.Problems {
+			if strings.Contains(p, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("校验结果中缺少 %q 相关的问题: %v", w, verr.Problems)
+		}
+	}
+}
+
+func TestValidateBcryptMaxLength(t *testing.T) {
+	cfg := validConfig()
+	cfg.Password.MaxLength = 100
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("bcrypt 下 max_length 超过 72 应该校验失败")
+	}
+
+	cfg.Password.Hash.Algorithm = "argon2id"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("argon2id 下 max_length 为 100 应该通过校验: %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"YAML", "config.yaml", `
+server:
+  addr: ":9000"
+  shutdown_timeout: "5s"
+database:
+  driver: "memory"
+jwt:
+  secret: "` + testSecret + `"
+`},
+		{"TOML", "config.toml", `
+[server]
+addr = ":9000"
+shutdown_timeout = "5s"
+
+[database]
+driver = "memory"
+
+[jwt]
+secret = "` + testSecret + `"
+`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load(writeFile(t, tc.file, tc.content))
+			if err != nil {
+				t.Fatalf("Load() 出错: %v", err)
+			}
+			if cfg.Server.Addr != ":9000" {
+				t.Errorf("server.addr = %q，期望 %q", cfg.Server.Addr, ":9000")
+			}
+			if cfg.Server.ShutdownTimeout.Duration != 5*time.Second {
+				t.Errorf("server.shutdown_timeout = %s，期望 5s", cfg.Server.ShutdownTimeout.Duration)
+			}
+			// 文件中没有的配置项保留默认值
+			if cfg.Password.MinLength != 6 {
+				t.Errorf("password.min_length = %d，期望默认值 6", cfg.Password.MinLength)
+			}
+		})
+	}
+}
+
+func TestLoadUnsupportedExtension(t *testing.T) {
+	_, err := Load(writeFile(t, "config.json", "{}"))
+	if err == nil || !strings.Contains(err.Error(), "不支持的配置文件格式") {
+		t.Fatalf("Load() = %v，期望返回不支持的格式错误", err)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9000"
+database:
+  driver: "memory"
+jwt:
+  secret: "`+testSecret+`"
+`)
+	t.Setenv("BLOG_SERVER_ADDR", ":9999")
+	t.Setenv("BLOG_LOGIN_MAX_FAILURES", "3")
+	t.Setenv("BLOG_JWT_ACCESS_TOKEN_TTL", "5m")
+	t.Setenv("BLOG_SERVER_TRUSTED_PROXIES", "127.0.0.1, 10.0.0.0/8")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() 出错: %v", err)
+	}
+	if cfg.Server.Addr != ":9999" {
+		t.Errorf("server.addr = %q，期望环境变量的值 %q", cfg.Server.Addr, ":9999")
+	}
+	if cfg.Login.MaxFailures != 3 {
+		t.Errorf("login.max_failures = %d，期望 3", cfg.Login.MaxFailures)
+	}
+	if cfg.JWT.AccessTokenTTL.Duration != 5*time.Minute {
+		t.Errorf("jwt.access_token_ttl = %s，期望 5m", cfg.JWT.AccessTokenTTL.Duration)
+	}
+	if got := strings.Join(cfg.Server.TrustedProxies, ","); got != "127.0.0.1,10.0.0.0/8" {
+		t.Errorf("server.trusted_proxies = %q，期望 %q", got, "127.0.0.1,10.0.0.0/8")
+	}
+}
+
+func TestLoadInvalidEnvValue(t *testing.T) {
+	t.Setenv("BLOG_DB_DRIVER", "memory")
+	t.Setenv("BLOG_JWT_SECRET", testSecret)
+	t.Setenv("BLOG_LOGIN_MAX_FAILURES", "five")
+
+	_, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "BLOG_LOGIN_MAX_FAILURES") {
+		t.Fatalf("Load() = %v，期望指出无效的环境变量", err)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.WriteFile(f, []byte("placeholder"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name    string
+		modify  func(tls *TLSConfig)
+		problem string
+	}{
+		{"有效配置", func(tls *TLSConfig) {}, ""},
+		{"缺少证书", func(tls *TLSConfig) { tls.CertFile = "" }, "server.tls.cert_file"},
+		{"私钥不存在", func(tls *TLSConfig) { tls.KeyFile = filepath.Join(dir, "missing.pem") }, "server.tls.key_file"},
+		{"重定向地址与监听地址相同", func(tls *TLSConfig) { tls.RedirectAddr = ":8443" }, "server.tls.redirect_addr"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.Addr = ":8443"
+			cfg.Server.TLS = TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, RedirectAddr: ":8080"}
+			tc.modify(&cfg.Server.TLS)
+
+			err := cfg.Validate()
+			if tc.problem == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v，期望 nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.problem) {
+				t.Fatalf("Validate() = %v，期望包含 %q", err, tc.problem)
+			}
+		})
+	}
+}
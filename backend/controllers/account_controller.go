@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"backend/auth"
+	"backend/config"
+	"backend/mail"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountController 通过邮件完成的自助操作：找回密码和验证邮箱
+type AccountController struct {
+	users     repository.UserRepository
+	tokens    *auth.TokenService
+	guard     *auth.LoginGuard
+	passwords *auth.PasswordService
+	mailer    *mail.Queue
+	conf      config.MailConfig
+}
+
+// NewAccountController 创建账号自助控制器，conf 通常为 config.Conf.Mail
+func NewAccountController(users repository.UserRepository, tokens *auth.TokenService, guard *auth.LoginGuard, passwords *auth.PasswordService, mailer *mail.Queue, conf config.MailConfig) *AccountController {
+	return &AccountController{users: users, tokens: tokens, guard: guard, passwords: passwords, mailer: mailer, conf: conf}
+}
+
+// actionLink 把令牌附加到前端页面地址的 token 参数上
+func actionLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// ForgotPassword 向已验证的邮箱发送重置密码链接
+// 无论邮箱是否存在都返回相同的结果，避免通过该接口探测已注册的邮箱
+func (ctl *AccountController) ForgotPassword(c *gin.Context) {
+	var requestData struct {
+		Email string `json:"email" validate:"required,email" msg:"请输入正确的邮箱"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+
+	// 与登录共用 IP 限流，防止批量发送邮件
+	if err := ctl.guard.CheckIP(c.Request.Context(), c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+
+	users, err := ctl.users.ListByEmail(c.Request.Context(), requestData.Email)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	for _, user := range users {
+		// 只有验证过的邮箱可以找回密码，已注销的账号不能找回
+		if !user.VerifiedEmail || user.Status == models.UserStatusCancelled {
+			continue
+		}
+		token, err := auth.SignActionToken(auth.PurposePasswordReset, user.ID, auth.PasswordFingerprint(user.Password), ctl.conf.ResetTokenTTL.Duration)
+		if err != nil {
+			utils.Fail(c, fmt.Errorf("生成重置链接失败: %w", err))
+			return
+		}
+		link, err := actionLink(ctl.conf.ResetPasswordURL, token)
+		if err != nil {
+			utils.Fail(c, fmt.Errorf("生成重置链接失败: %w", err))
+			return
+		}
+		if err := ctl.mailer.Enqueue(mail.PasswordResetMessage(user.Email, user.Username, link, ctl.conf.ResetTokenTTL.Duration)); err != nil {
+			log.Printf("Failed to enqueue password reset mail for user %d: %v", user.ID, err)
+		}
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "如果该邮箱已绑定并通过验证，重置密码的链接将发送到该邮箱", nil)
+}
+
+// ResetForgottenPassword 使用邮件中的链接设置新密码
+// 令牌绑定了签发时的密码，密码修改后同一链接不能再次使用
+func (ctl *AccountController) ResetForgottenPassword(c *gin.Context) {
+	var requestData struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if requestData.Token == "" {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+	if !respondPasswordError(c, ctl.passwords.Validate(requestData.NewPassword)) {
+		return
+	}
+
+	claims, err := auth.ParseActionToken(auth.PurposePasswordReset, requestData.Token)
+	if err != nil {
+		utils.JSONResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	user, err := ctl.users.GetByID(c.Request.Context(), claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	currentHash, err := ctl.users.GetPasswordHash(c.Request.Context(), user.ID)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	if !auth.MatchBinding(claims, auth.PasswordFingerprint(currentHash)) || user.Status == models.UserStatusCancelled {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+
+	if !respondPasswordError(c, ctl.passwords.Set(c.Request.Context(), user.ID, requestData.NewPassword, false)) {
+		return
+	}
+
+	// 找回密码后之前的登录全部失效，同时清除登录失败记录，用户可以立即用新密码登录
+	if err := ctl.tokens.RevokeAll(c.Request.Context(), user.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("注销用户会话失败: %w", err))
+		return
+	}
+	if err := ctl.guard.Succeed(c.Request.Context(), user.Username); err != nil {
+		utils.Fail(c, fmt.Errorf("清除登录失败记录失败: %w", err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "密码重置成功，请使用新密码登录", nil)
+}
+
+// SendVerifyEmail 向当前用户的邮箱发送验证链接
+func (ctl *AccountController) SendVerifyEmail(c *gin.Context) {
+	user, err := ctl.users.GetByID(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	if user.Email == "" {
+		utils.JSONResponse(c, http.StatusBadRequest, "请先设置邮箱", nil)
+		return
+	}
+	if user.VerifiedEmail {
+		utils.JSONResponse(c, http.StatusBadRequest, "邮箱已验证", nil)
+		return
+	}
+	if err := ctl.guard.CheckIP(c.Request.Context(), c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+
+	token, err := auth.SignActionToken(auth.PurposeEmailVerify, user.ID, auth.EmailFingerprint(user.Email), ctl.conf.VerifyTokenTTL.Duration)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("生成验证链接失败: %w", err))
+		return
+	}
+	link, err := actionLink(ctl.conf.VerifyEmailURL, token)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("生成验证链接失败: %w", err))
+		return
+	}
+	if err := ctl.mailer.Enqueue(mail.VerifyEmailMessage(user.Email, user.Username, link, ctl.conf.VerifyTokenTTL.Duration)); err != nil {
+		utils.Fail(c, utils.NewError(http.StatusServiceUnavailable, "", "发送验证邮件失败，请稍后重试").WithCause(err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "验证邮件已发送，请查收", nil)
+}
+
+// VerifyEmail 使用邮件中的链接完成邮箱验证，不需要登录
+// 令牌绑定了签发时的邮箱，邮箱修改后旧链接失效
+func (ctl *AccountController) VerifyEmail(c *gin.Context) {
+	var requestData struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+
+	claims, err := auth.ParseActionToken(auth.PurposeEmailVerify, requestData.Token)
+	if err != nil {
+		utils.JSONResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	user, err := ctl.users.GetByID(c.Request.Context(), claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	if user.Email == "" || !auth.MatchBinding(claims, auth.EmailFingerprint(user.Email)) {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+
+	// 只在邮箱仍未被修改时标记为已验证
+	ok, err := ctl.users.SetEmailVerified(c.Request.Context(), user.ID, user.Email)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
+		return
+	}
+	if !ok {
+		utils.JSONResponse(c, http.StatusBadRequest, auth.ErrInvalidActionToken.Error(), nil)
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "邮箱验证成功", nil)
+}
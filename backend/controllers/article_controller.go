@@ -1,225 +1,362 @@
 package controllers
 
 import (
-	"backend/config"
+	"backend/markdown"
 	"backend/models"
+	"backend/repository"
 	"backend/utils"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"log"
 	"net/http"
 	"time"
 )
 
-// handleValidationErrorsForArticle 处理数据验证错误
-func handleValidationErrorsForArticle(c *gin.Context, err error) {
-	validationErrors := err.(validator.ValidationErrors)
-	for _, err := range validationErrors {
-		field := err.Field()
-		switch field {
-		case "Title":
-			utils.JSONResponse(c, http.StatusBadRequest, "文章名称不能为空，且长度在 1-50 位之间", nil)
-		case "Status":
-			utils.JSONResponse(c, http.StatusBadRequest, "文章状态设置错误", nil)
+// ArticleController 文章相关接口
+type ArticleController struct {
+	articles   repository.ArticleRepository
+	tags       repository.TermRepository // 用于检查文章的标签是否存在
+	categories repository.TermRepository // 用于检查文章的分类是否存在
+}
+
+// NewArticleController 创建文章控制器
+func NewArticleController(articles repository.ArticleRepository, tags, categories repository.TermRepository) *ArticleController {
+	return &ArticleController{articles: articles, tags: tags, categories: categories}
+}
+
+// initialArticleActions 创建文章时可以直接设置的状态及对应的操作，其他状态只能通过审核流程的接口设置
+var initialArticleActions = map[string]string{
+	models.ArticleStatusSubmitted: models.ArticleActionSubmit,
+	models.ArticleStatusPublished: models.ArticleActionPublish,
+}
+
+// authorizeArticleOwner 检查当前用户能否修改指定文章，返回修改前的文章
+// 文章创建人可以修改自己的文章，拥有 anyPermission 权限的用户（编辑、管理员）可以修改所有文章
+func (ctl *ArticleController) authorizeArticleOwner(c *gin.Context, id int, anyPermission, forbiddenMessage string) (*models.Article, bool) {
+	article, err := ctl.articles.GetByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusNotFound, "文章不存在", nil)
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	if article.CreatorID != c.GetInt("userID") && !models.HasPermission(c.GetString("role"), anyPermission) {
+		utils.JSONResponse(c, http.StatusForbidden, forbiddenMessage, nil)
+		return nil, false
+	}
+	return article, true
+}
+
+// checkContentEditable 检查当前用户能否修改文章内容，不能修改时返回 409
+// 已提交、审核通过或已发布的文章，只有可以编辑所有文章或审核文章的用户能修改内容；
+// 作者需要等待审核结果，或者先下线文章，修改后重新提交审核
+func checkContentEditable(c *gin.Context, existing, article *models.Article) bool {
+	if existing.SameContent(article) || !models.ContentUnderReview(existing.Status) {
+		return true
+	}
+	role := c.GetString("role")
+	if models.HasPermission(role, models.PermArticleEditAny) || models.HasPermission(role, models.PermArticleReview) {
+		return true
+	}
+	utils.Fail(c, utils.NewError(http.StatusConflict, models.CodeInvalidArticleTransition, "文章已提交审核或已发布，不能修改内容，请等待审核结果或先下线文章"))
+	return false
+}
+
+// canViewArticle 判断当前用户能否查看文章
+// 已发布且未到定时下线时间的文章所有人可见，其他文章只有创建人和拥有编辑或审核所有文章权限的用户可以查看
+func canViewArticle(c *gin.Context, article *models.Article) bool {
+	if article.IsPublic(time.Now()) {
+		return true
+	}
+	if userID := c.GetInt("userID"); userID != 0 && article.CreatorID == userID {
+		return true
+	}
+	return canViewAllArticles(c)
+}
+
+// canViewAllArticles 判断当前用户能否查看所有文章，包括未发布的文章
+func canViewAllArticles(c *gin.Context) bool {
+	role := c.GetString("role")
+	return models.HasPermission(role, models.PermArticleEditAny) || models.HasPermission(role, models.PermArticleReview)
+}
+
+// viewArticle 获取当前用户可以查看的文章并使阅读量加一，文章不存在或不可见时返回 404
+func (ctl *ArticleController) viewArticle(c *gin.Context, id int) (*models.Article, bool) {
+	article, err := ctl.articles.GetByID(c.Request.Context(), id)
+	if err == nil && !canViewArticle(c, article) {
+		err = repository.ErrNotFound // 不暴露未发布文章是否存在
+	}
+	if err == nil {
+		article, err = ctl.articles.View(c.Request.Context(), id)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("文章不存在"))
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	// 迁移前保存的文章没有渲染结果，第一次查看时渲染并保存
+	if article.ContentHTML == "" && article.Content != "" {
+		if err := renderArticle(article); err != nil {
+			utils.Fail(c, err)
+			return nil, false
 		}
-		break
+		if err := ctl.articles.SaveRendered(c.Request.Context(), article); err != nil {
+			log.Printf("Failed to save rendered content of article %d: %v", article.ID, err)
+		}
+	}
+	return article, true
+}
+
+// renderArticle 把 Markdown 正文渲染为过滤后的 HTML 并提取目录，保存文章前调用
+func renderArticle(article *models.Article) error {
+	doc, err := markdown.Render(article.Content)
+	if err != nil {
+		return fmt.Errorf("渲染文章正文失败: %w", err)
+	}
+	article.ContentHTML = doc.HTML
+	article.TOC = doc.TOC
+	return nil
+}
+
+// validateSchedule 检查定时下线时间晚于定时发布时间，两者都是固定宽度的时间字符串，可以直接比较
+func validateSchedule(c *gin.Context, article *models.Article) bool {
+	if article.PublishAt != "" && article.UnpublishAt != "" && article.UnpublishAt <= article.PublishAt {
+		utils.Fail(c, utils.Invalid("定时下线时间必须晚于定时发布时间", utils.FieldError{Field: "unpublish_at", Rule: "gtfield"}))
+		return false
 	}
+	return true
+}
+
+// checkArticleTerms 检查文章的标签和分类都存在，失败时已返回错误响应
+func (ctl *ArticleController) checkArticleTerms(c *gin.Context, article *models.Article) bool {
+	for _, f := range []struct {
+		terms   repository.TermRepository
+		ids     []int
+		field   string
+		message string
+	}{
+		{ctl.tags, article.TagIDs, "tag_ids", "标签不存在"},
+		{ctl.categories, article.CategoryIDs, "category_ids", "分类不存在"},
+	} {
+		missing, err := f.terms.Missing(c.Request.Context(), f.ids)
+		if err != nil {
+			utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+			return false
+		}
+		if len(missing) > 0 {
+			utils.Fail(c, utils.Invalid(f.message, utils.FieldError{Field: f.field, Rule: "exists", Param: fmt.Sprint(missing[0])}))
+			return false
+		}
+	}
+	return true
 }
 
 // AddArticle 添加文章
-func AddArticle(c *gin.Context) {
+func (ctl *ArticleController) AddArticle(c *gin.Context) {
 	var requestData models.Article
 	if err := c.ShouldBind(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
-	//	验证数据
-	if err := utils.GetValidator().Struct(requestData); err != nil {
-		handleValidationErrorsForArticle(c, err)
+	if !ctl.createArticle(c, &requestData) {
 		return
 	}
+	utils.JSONResponse(c, http.StatusOK, "添加成功", nil)
+}
+
+// createArticle 校验并保存新文章，创建人为当前登录用户，失败时已返回错误响应
+func (ctl *ArticleController) createArticle(c *gin.Context, article *models.Article) bool {
+	//	验证数据
+	if err := utils.Validate(article); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	if !validateSchedule(c, article) || !ctl.checkArticleTerms(c, article) {
+		return false
+	}
+	// 新文章默认为草稿，直接提交或发布时按审核流程检查权限，与先创建草稿再提交或发布一致
+	if article.Status == "" {
+		article.Status = models.ArticleStatusDraft
+	}
+	if article.Status != models.ArticleStatusDraft {
+		action, ok := initialArticleActions[article.Status]
+		if !ok {
+			respondTransitionError(c, models.ErrInvalidArticleTransition)
+			return false
+		}
+		if _, err := models.ArticleTransitionTarget(action, models.ArticleStatusDraft, c.GetString("role"), true); err != nil {
+			respondTransitionError(c, err)
+			return false
+		}
+	}
 	//设置默认数据
-	requestData.CreateTime = time.Now().Format("2006-01-02 15:04:05")
-	views := 0
+	article.CreateTime = time.Now().Format("2006-01-02 15:04:05")
+	article.Views = 0
+	article.PublishedAt = ""
+	if err := renderArticle(article); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	if article.Status == models.ArticleStatusPublished {
+		// 直接发布时忽略定时发布时间，与 Transition 发布后清空该时间一致
+		article.PublishedAt = article.CreateTime
+		article.PublishAt = ""
+	}
 	// 设置创建人id
 	if userID, ok := c.Get("userID"); ok {
-		requestData.CreatorID = userID.(int)
+		article.CreatorID = userID.(int)
 	}
 	//	数据库插入数据
-	sql := "INSERT INTO article (title,cover_image, intro,keywords,content,creator_id,create_time,status,views) VALUES (?,?,?,?,?,?,?,?,?)"
-	_, err := config.DB.Exec(sql, requestData.Title, requestData.CoverImage, requestData.Intro, requestData.Keywords, requestData.Content, requestData.CreatorID, requestData.CreateTime, requestData.Status, views)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库插入失败: %v", err), nil)
-		return
+	if err := ctl.articles.Create(c.Request.Context(), article); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库插入失败: %w", err))
+		return false
 	}
-	utils.JSONResponse(c, http.StatusOK, "添加成功", nil)
+	return true
 }
 
 // EditArticle 编辑文章
-func EditArticle(c *gin.Context) {
+func (ctl *ArticleController) EditArticle(c *gin.Context) {
 	var requestData models.Article
 	if err := c.ShouldBindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
-	if err := utils.GetValidator().Struct(requestData); err != nil {
-		handleValidationErrorsForArticle(c, err)
-		return
-	}
-	sql := "UPDATE article SET title=?,cover_image=?,intro=?,keywords=?,content=?,status=? WHERE id=?"
-	_, err := config.DB.Exec(sql, requestData.Title, requestData.CoverImage, requestData.Intro, requestData.Keywords, requestData.Content, requestData.Status, requestData.ID)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库更新失败: %v", err), nil)
+	if !ctl.updateArticle(c, &requestData) {
 		return
 	}
 	utils.JSONResponse(c, http.StatusOK, "更新成功", nil)
 }
 
+// updateArticle 校验权限和数据后更新文章，失败时已返回错误响应
+// 先检查文章是否存在以及是否有权编辑，没有权限时不会返回数据校验的结果
+func (ctl *ArticleController) updateArticle(c *gin.Context, article *models.Article) bool {
+	existing, ok := ctl.authorizeArticleOwner(c, article.ID, models.PermArticleEditAny, "只能编辑自己创建的文章")
+	if !ok {
+		return false
+	}
+	if err := utils.Validate(article); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	if !validateSchedule(c, article) || !ctl.checkArticleTerms(c, article) {
+		return false
+	}
+	// 编辑不能改变状态，避免跳过审核直接发布
+	if article.Status != "" && article.Status != existing.Status {
+		utils.Fail(c, utils.NewError(http.StatusConflict, models.CodeInvalidArticleTransition, "请通过提交、审核和发布接口修改文章状态"))
+		return false
+	}
+	if !checkContentEditable(c, existing, article) {
+		return false
+	}
+	if err := renderArticle(article); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	// 内容有变化时保存新版本，只修改定时设置时不保存
+	var revision *models.ArticleRevision
+	if !existing.SameContent(article) {
+		revision = models.NewArticleRevision(article, c.GetInt("userID"), time.Now().Format(models.TimeLayout))
+	}
+	if err := ctl.articles.Update(c.Request.Context(), article, revision); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
+		return false
+	}
+	return true
+}
+
 // GetArticleList 获取文章列表
-func GetArticleList(c *gin.Context) {
+func (ctl *ArticleController) GetArticleList(c *gin.Context) {
 	var requestData struct {
 		PageNum  *int   `json:"pageNum"`
 		PageSize *int   `json:"pageSize"`
 		Keyword  string `json:"keyword"`
 		Status   string `json:"status"`
+		Mine     bool   `json:"mine"`     // 只查询当前登录用户创建的文章
+		Tag      string `json:"tag"`      // 标签的别名
+		Category string `json:"category"` // 分类的别名
 	}
 	if err := c.ShouldBindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
-	// 查询列表数据
-	query := "SELECT article.id,article.title,article.intro,article.cover_image,article.keywords,article.views,article.creator_id,article.create_time,article.status,user.username FROM article JOIN user ON article.creator_id = user.id WHERE 1=1"
-	args := []interface{}{}
-	if requestData.Keyword != "" {
-		query += " AND (title LIKE ? OR intro LIKE ? OR keywords LIKE ?)"
-		args = append(args, "%"+requestData.Keyword+"%")
-		args = append(args, "%"+requestData.Keyword+"%")
-		args = append(args, "%"+requestData.Keyword+"%")
-	}
-	if requestData.Status != "" {
-		query += " AND article.status = ?"
-		args = append(args, requestData.Status)
-	}
-	query += " ORDER BY id DESC" // 添加按照 id 降序排列
-	if requestData.PageNum != nil && requestData.PageSize != nil {
-		offset := (*requestData.PageNum - 1) * *requestData.PageSize
-		query += " LIMIT ? OFFSET ?"
-		args = append(args, *requestData.PageSize, offset)
-	}
-	rows, err := config.DB.Query(query, args...)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询列表失败: %v", err), nil)
-		return
-	}
-	defer rows.Close()
-
-	type articleItem struct {
-		ID         int    `json:"id"`
-		Title      string `json:"title" validate:"required,min=1,max=20"`
-		Intro      string `json:"intro"`
-		CoverImage string `json:"cover_image"`
-		Keywords   string `json:"keywords"`
-		Views      int    `json:"views"`
-		CreatorID  int    `json:"creator_id"`
-		CreateTime string `json:"create_time"`
-		Status     string `json:"status" validate:"required,oneof=0 1 2"`
-		Creator    string `json:"creator"`
-	}
-
-	var articleList []articleItem = []articleItem{}
-	for rows.Next() {
-		var article articleItem
-		if err := rows.Scan(&article.ID, &article.Title, &article.Intro, &article.CoverImage, &article.Keywords, &article.Views, &article.CreatorID, &article.CreateTime, &article.Status, &article.Creator); err != nil {
-			utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据解析失败: %v", err), nil)
+	// 查询“我的文章”需要登录，userID 由 OptionalJWTAuthMiddleware 设置
+	creatorID := 0
+	if requestData.Mine {
+		creatorID = c.GetInt("userID")
+		if creatorID == 0 {
+			utils.JSONResponse(c, http.StatusUnauthorized, "请先登录", nil)
 			return
 		}
-		articleList = append(articleList, article)
 	}
 
-	// 获取总记录数
-	var total int
-	args2 := []interface{}{}
-	countQuery := "SELECT COUNT(*) FROM article WHERE 1=1"
-	if requestData.Keyword != "" {
-		countQuery += " AND (title LIKE ? OR intro LIKE ? OR keywords LIKE ?)"
-		args2 = append(args2, "%"+requestData.Keyword+"%")
-		args2 = append(args2, "%"+requestData.Keyword+"%")
-		args2 = append(args2, "%"+requestData.Keyword+"%")
-	}
-	if requestData.Status != "" {
-		countQuery += " AND article.status = ?"
-		args2 = append(args2, requestData.Status)
-	}
-	err = config.DB.QueryRow(countQuery, args2...).Scan(&total)
+	// 查询列表数据和总记录数
+	articleList, total, err := ctl.articles.List(c.Request.Context(), repository.ArticleQuery{
+		Pagination: repository.Pagination{PageNum: requestData.PageNum, PageSize: requestData.PageSize},
+		Keyword:    requestData.Keyword,
+		Status:     requestData.Status,
+		CreatorID:  creatorID,
+		Tag:        requestData.Tag,
+		Category:   requestData.Category,
+		// 查询自己的文章或有权查看所有文章时不限制状态，其他情况只返回公开的文章
+		Public: !requestData.Mine && !canViewAllArticles(c),
+	})
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询记录总数失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
 		return
 	}
 
 	// 返回查询结果
-	utils.JSONResponse(c, http.StatusOK, "项目列表获取成功", gin.H{
+	utils.JSONResponse(c, http.StatusOK, "文章列表获取成功", gin.H{
 		"total": total,
 		"list":  articleList,
 	})
 }
 
 // DeleteArticle 删除项目
-func DeleteArticle(c *gin.Context) {
+func (ctl *ArticleController) DeleteArticle(c *gin.Context) {
 	var requestData models.Article
 	if err := c.ShouldBindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
-	sql := "DELETE FROM article WHERE id=?"
-	_, err := config.DB.Exec(sql, requestData.ID)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库删除失败: %v", err), nil)
+	if !ctl.deleteArticle(c, requestData.ID) {
 		return
 	}
 	utils.JSONResponse(c, http.StatusOK, "删除文章成功", nil)
 }
 
-// GetArticleDetails 获取文章详情并使 views 字段自增一
-func GetArticleDetails(c *gin.Context) {
+// deleteArticle 检查权限后删除文章，失败时已返回错误响应
+func (ctl *ArticleController) deleteArticle(c *gin.Context, id int) bool {
+	if _, ok := ctl.authorizeArticleOwner(c, id, models.PermArticleDeleteAny, "只能删除自己创建的文章"); !ok {
+		return false
+	}
+	if err := ctl.articles.Delete(c.Request.Context(), id); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库删除失败: %w", err))
+		return false
+	}
+	return true
+}
+
+// GetArticleDetails 获取文章详情并使 views 字段自增一，content 为 Markdown 原文，content_html 和 toc 为渲染结果
+// 未发布或已到定时下线时间的文章只有创建人、编辑和审核人可以查看
+func (ctl *ArticleController) GetArticleDetails(c *gin.Context) {
 	var requestData struct {
 		ID int `json:"id"`
 	}
-	var article models.Article
 	if err := c.ShouldBindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
-		return
-	}
-	// 开启事务
-	tx, err := config.DB.Begin()
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("开启事务失败: %v", err), nil)
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
-	// 查询文章信息
-	sql := "SELECT * FROM article WHERE id=?"
-	err = tx.QueryRow(sql, requestData.ID).Scan(&article.ID, &article.Title, &article.CoverImage, &article.Intro, &article.Keywords, &article.Content, &article.Views, &article.CreatorID, &article.CreateTime, &article.Status)
-	if err != nil {
-		// 回滚事务
-		tx.Rollback()
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询失败: %v", err), nil)
-		return
-	}
-	// 更新 views 字段
-	updateSql := "UPDATE article SET views = views + 1 WHERE id =?"
-	_, err = tx.Exec(updateSql, requestData.ID)
-	if err != nil {
-		// 回滚事务
-		tx.Rollback()
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("更新 views 字段失败: %v", err), nil)
-		return
-	}
-	// 提交事务
-	err = tx.Commit()
-	if err != nil {
-		// 回滚事务
-		tx.Rollback()
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("提交事务失败: %v", err), nil)
+	article, ok := ctl.viewArticle(c, requestData.ID)
+	if !ok {
 		return
 	}
 	utils.JSONResponse(c, http.StatusOK, "获取文章信息成功", article)
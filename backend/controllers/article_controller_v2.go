@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListArticles GET /api/v2/articles 按查询字符串筛选文章并分页
+// 支持 keyword、status、creator_id、mine=true（只查询当前登录用户的文章）、tag 和 category（别名）以及 page、page_size
+// 没有查看所有文章权限时，除自己的文章外只返回已发布且未到定时下线时间的文章
+func (ctl *ArticleController) ListArticles(c *gin.Context) {
+	var query struct {
+		pageQuery
+		Keyword   string `form:"keyword" json:"keyword"`
+		Status    string `form:"status" json:"status" validate:"omitempty,oneof=0 1 2 3 4 5" msg:"文章状态设置错误"`
+		CreatorID int    `form:"creator_id" json:"creator_id"`
+		Mine      bool   `form:"mine" json:"mine"`
+		Tag       string `form:"tag" json:"tag"`
+		Category  string `form:"category" json:"category"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	// 查询“我的文章”需要登录，userID 由 OptionalJWTAuthMiddleware 设置
+	creatorID := query.CreatorID
+	if query.Mine {
+		creatorID = c.GetInt("userID")
+		if creatorID == 0 {
+			utils.JSONResponse(c, http.StatusUnauthorized, "请先登录", nil)
+			return
+		}
+	}
+
+	list, total, err := ctl.articles.List(c.Request.Context(), repository.ArticleQuery{
+		Pagination: query.pagination(),
+		Keyword:    query.Keyword,
+		Status:     query.Status,
+		CreatorID:  creatorID,
+		Tag:        query.Tag,
+		Category:   query.Category,
+		Public:     !query.Mine && !canViewAllArticles(c),
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+	listResponse(c, "文章列表获取成功", list, total, query.pageQuery)
+}
+
+// GetArticle GET /api/v2/articles/:id 获取文章详情并使阅读量加一，不可见的文章返回 404
+func (ctl *ArticleController) GetArticle(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	article, ok := ctl.viewArticle(c, id)
+	if !ok {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取文章信息成功", article)
+}
+
+// CreateArticle POST /api/v2/articles 新建文章，返回 201 和新建的文章
+func (ctl *ArticleController) CreateArticle(c *gin.Context) {
+	var article models.Article
+	if err := c.ShouldBindJSON(&article); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	article.ID = 0
+	if !ctl.createArticle(c, &article) {
+		return
+	}
+	createdResponse(c, "添加成功", "/api/v2/articles/"+strconv.Itoa(article.ID), article)
+}
+
+// ReplaceArticle PUT /api/v2/articles/:id 使用请求中的完整数据替换文章
+// 请求中没有 tag_ids 或 category_ids 时清空文章的标签或分类，只修改部分字段请使用 PATCH
+func (ctl *ArticleController) ReplaceArticle(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	var article models.Article
+	if err := c.ShouldBindJSON(&article); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	article.ID = id
+	if article.TagIDs == nil {
+		article.TagIDs = []int{}
+	}
+	if article.CategoryIDs == nil {
+		article.CategoryIDs = []int{}
+	}
+	ctl.saveArticle(c, &article)
+}
+
+// PatchArticle PATCH /api/v2/articles/:id 只更新请求中出现的字段
+func (ctl *ArticleController) PatchArticle(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	// 先检查是否有权编辑，再在原有数据上解析请求，未出现的字段保持不变
+	article, ok := ctl.authorizeArticleOwner(c, id, models.PermArticleEditAny, "只能编辑自己创建的文章")
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(article); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	article.ID = id
+	ctl.saveArticle(c, article)
+}
+
+// saveArticle 更新文章后返回更新后的数据
+func (ctl *ArticleController) saveArticle(c *gin.Context, article *models.Article) {
+	if !ctl.updateArticle(c, article) {
+		return
+	}
+	updated, err := ctl.articles.GetByID(c.Request.Context(), article.ID)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "更新成功", updated)
+}
+
+// RemoveArticle DELETE /api/v2/articles/:id 删除文章，成功时返回 204
+func (ctl *ArticleController) RemoveArticle(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	if !ctl.deleteArticle(c, id) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// revisionRequest 历史版本接口的参数
+// v1 接口在请求体中传 id 和 revision_id，v2 接口的 id 和 revision_id 在路径中，比较版本的 from 和 to 在查询字符串中
+type revisionRequest struct {
+	ID         int `json:"id" form:"-"`
+	RevisionID int `json:"revision_id" form:"-"`
+	From       int `json:"from" form:"from"` // 比较版本时的旧版本id
+	To         int `json:"to" form:"to"`     // 比较版本时的新版本id
+}
+
+// revisionFieldChange 比较版本时有变化的短字段
+type revisionFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// revisionDiff 两个版本之间的差异，正文为逐行差异
+type revisionDiff struct {
+	From    models.ArticleRevisionListItem `json:"from"`
+	To      models.ArticleRevisionListItem `json:"to"`
+	Fields  []revisionFieldChange          `json:"fields"`
+	Content []utils.DiffLine               `json:"content"`
+}
+
+// bindRevisionRequest 读取历史版本接口的参数，路径中的 id 和 revision_id 优先
+func bindRevisionRequest(c *gin.Context) (*revisionRequest, bool) {
+	var req revisionRequest
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&req)
+	} else if err = c.ShouldBindJSON(&req); errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return nil, false
+	}
+	params := []struct {
+		name   string
+		target *int
+	}{{"id", &req.ID}, {"revision_id", &req.RevisionID}}
+	for _, p := range params {
+		if c.Param(p.name) == "" {
+			continue
+		}
+		id, ok := pathID(c, p.name)
+		if !ok {
+			return nil, false
+		}
+		*p.target = id
+	}
+	return &req, true
+}
+
+// findRevision 获取文章的一个版本，不存在时返回 404
+func (ctl *ArticleController) findRevision(c *gin.Context, articleID, revisionID int) (*models.ArticleRevision, bool) {
+	revision, err := ctl.articles.GetRevision(c.Request.Context(), articleID, revisionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("版本不存在"))
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	return revision, true
+}
+
+// GetArticleRevisions 获取文章的历史版本列表，最新的在前，不包含正文
+// 文章创建人和拥有编辑或审核所有文章权限的用户可以查看
+func (ctl *ArticleController) GetArticleRevisions(c *gin.Context) {
+	req, ok := bindRevisionRequest(c)
+	if !ok {
+		return
+	}
+	article, ok := ctl.findArticleHistory(c, req.ID, "没有权限查看该文章的历史版本")
+	if !ok {
+		return
+	}
+	revisions, err := ctl.articles.ListRevisions(c.Request.Context(), article.ID)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取历史版本成功", revisions)
+}
+
+// GetArticleRevision 获取文章某个版本的完整内容
+func (ctl *ArticleController) GetArticleRevision(c *gin.Context) {
+	req, ok := bindRevisionRequest(c)
+	if !ok {
+		return
+	}
+	if _, ok := ctl.findArticleHistory(c, req.ID, "没有权限查看该文章的历史版本"); !ok {
+		return
+	}
+	revision, ok := ctl.findRevision(c, req.ID, req.RevisionID)
+	if !ok {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取历史版本成功", revision)
+}
+
+// DiffArticleRevisions 比较文章的两个版本，返回有变化的标题、封面、简介、关键词以及正文的逐行差异
+func (ctl *ArticleController) DiffArticleRevisions(c *gin.Context) {
+	req, ok := bindRevisionRequest(c)
+	if !ok {
+		return
+	}
+	if req.From <= 0 || req.To <= 0 {
+		utils.Fail(c, utils.Invalid("请指定要比较的两个版本", utils.FieldError{Field: "from", Rule: "required"}, utils.FieldError{Field: "to", Rule: "required"}))
+		return
+	}
+	if _, ok := ctl.findArticleHistory(c, req.ID, "没有权限查看该文章的历史版本"); !ok {
+		return
+	}
+	from, ok := ctl.findRevision(c, req.ID, req.From)
+	if !ok {
+		return
+	}
+	to, ok := ctl.findRevision(c, req.ID, req.To)
+	if !ok {
+		return
+	}
+
+	diff := revisionDiff{From: from.ListItem(), To: to.ListItem(), Fields: []revisionFieldChange{}, Content: utils.DiffLines(from.Content, to.Content)}
+	for _, f := range []revisionFieldChange{
+		{"title", from.Title, to.Title},
+		{"cover_image", from.CoverImage, to.CoverImage},
+		{"intro", from.Intro, to.Intro},
+		{"keywords", from.Keywords, to.Keywords},
+	} {
+		if f.Old != f.New {
+			diff.Fields = append(diff.Fields, f)
+		}
+	}
+	utils.JSONResponse(c, http.StatusOK, "比较版本成功", diff)
+}
+
+// RestoreArticleRevision 把文章恢复为某个历史版本的内容，并保存为一个新版本，原有版本保持不变
+// 权限与编辑文章相同，状态和定时设置不受影响；已提交审核或已发布的文章同样只有编辑和审核人可以恢复
+func (ctl *ArticleController) RestoreArticleRevision(c *gin.Context) {
+	req, ok := bindRevisionRequest(c)
+	if !ok {
+		return
+	}
+	article, ok := ctl.authorizeArticleOwner(c, req.ID, models.PermArticleEditAny, "只能编辑自己创建的文章")
+	if !ok {
+		return
+	}
+	old, ok := ctl.findRevision(c, article.ID, req.RevisionID)
+	if !ok {
+		return
+	}
+
+	existing := *article
+	old.ApplyTo(article)
+	if !checkContentEditable(c, &existing, article) {
+		return
+	}
+	if err := renderArticle(article); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	revision := models.NewArticleRevision(article, c.GetInt("userID"), time.Now().Format(models.TimeLayout))
+	revision.RestoredFrom = old.ID
+	if err := ctl.articles.Update(c.Request.Context(), article, revision); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "已恢复到该版本", article)
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transitionRequest 审核流程接口的请求参数
+// v1 接口在请求体中传 id，v2 接口的 id 在路径中，请求体可以为空
+type transitionRequest struct {
+	ID      int    `json:"id"`
+	Comment string `json:"comment" validate:"max=1000" msg:"审核意见不能超过 1000 个字符"`
+}
+
+// respondTransitionError 把状态机返回的错误转换为响应
+func respondTransitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidArticleTransition):
+		utils.Fail(c, utils.NewError(http.StatusConflict, models.CodeInvalidArticleTransition, err.Error()))
+	case errors.Is(err, models.ErrArticleTransitionForbidden):
+		utils.Fail(c, utils.NewError(http.StatusForbidden, "", err.Error()))
+	case errors.Is(err, repository.ErrArticleStatusChanged):
+		utils.Fail(c, utils.NewError(http.StatusConflict, "", err.Error()))
+	default:
+		utils.Fail(c, fmt.Errorf("修改文章状态失败: %w", err))
+	}
+}
+
+// bindTransitionRequest 读取审核流程接口的参数，路径中有 id 时使用路径中的 id
+func bindTransitionRequest(c *gin.Context) (*transitionRequest, bool) {
+	var req transitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Fail(c, utils.InvalidInput(err))
+		return nil, false
+	}
+	if c.Param("id") != "" {
+		id, ok := idParam(c)
+		if !ok {
+			return nil, false
+		}
+		req.ID = id
+	}
+	if err := utils.Validate(req); err != nil {
+		utils.Fail(c, err)
+		return nil, false
+	}
+	return &req, true
+}
+
+// transitionArticle 对文章执行审核流程中的操作，成功后返回变更后的文章
+func (ctl *ArticleController) transitionArticle(c *gin.Context, action, message string) {
+	req, ok := bindTransitionRequest(c)
+	if !ok {
+		return
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if action == models.ArticleActionReject && req.Comment == "" {
+		utils.Fail(c, utils.Invalid("驳回时必须填写审核意见", utils.FieldError{Field: "comment", Rule: "required"}))
+		return
+	}
+
+	article, err := ctl.articles.GetByID(c.Request.Context(), req.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("文章不存在"))
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+
+	userID := c.GetInt("userID")
+	to, err := models.ArticleTransitionTarget(action, article.Status, c.GetString("role"), article.CreatorID == userID)
+	if err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+	transition := &models.ArticleTransition{
+		ArticleID:  article.ID,
+		Action:     action,
+		FromStatus: article.Status,
+		ToStatus:   to,
+		ActorID:    userID,
+		Comment:    req.Comment,
+		CreatedAt:  time.Now().Format(models.TimeLayout),
+	}
+	if err := ctl.articles.Transition(c.Request.Context(), transition); err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+
+	article.Status = to
+	if to == models.ArticleStatusPublished {
+		article.PublishedAt = transition.CreatedAt
+	}
+	utils.JSONResponse(c, http.StatusOK, message, article)
+}
+
+// SubmitArticle 作者提交草稿、被驳回或已下线的文章进入审核
+func (ctl *ArticleController) SubmitArticle(c *gin.Context) {
+	ctl.transitionArticle(c, models.ArticleActionSubmit, "提交成功，请等待审核")
+}
+
+// ApproveArticle 审核通过已提交的文章，可以附带审核意见
+func (ctl *ArticleController) ApproveArticle(c *gin.Context) {
+	ctl.transitionArticle(c, models.ArticleActionApprove, "审核通过")
+}
+
+// RejectArticle 驳回已提交或审核通过的文章，必须填写审核意见
+func (ctl *ArticleController) RejectArticle(c *gin.Context) {
+	ctl.transitionArticle(c, models.ArticleActionReject, "已驳回")
+}
+
+// PublishArticle 发布文章，并记录发布时间
+func (ctl *ArticleController) PublishArticle(c *gin.Context) {
+	ctl.transitionArticle(c, models.ArticleActionPublish, "发布成功")
+}
+
+// UnpublishArticle 下线已发布的文章，文章进入归档状态
+func (ctl *ArticleController) UnpublishArticle(c *gin.Context) {
+	ctl.transitionArticle(c, models.ArticleActionUnpublish, "下线成功")
+}
+
+// findArticleHistory 获取文章并检查当前用户能否查看它的审核记录和历史版本，失败时已返回错误响应
+// 文章创建人和拥有编辑或审核所有文章权限的用户可以查看
+func (ctl *ArticleController) findArticleHistory(c *gin.Context, id int, forbiddenMessage string) (*models.Article, bool) {
+	article, err := ctl.articles.GetByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("文章不存在"))
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	if article.CreatorID != c.GetInt("userID") && !canViewAllArticles(c) {
+		utils.JSONResponse(c, http.StatusForbidden, forbiddenMessage, nil)
+		return nil, false
+	}
+	return article, true
+}
+
+// GetArticleTransitions 获取文章的状态变更记录，包括审核意见
+// 文章创建人和拥有编辑或审核所有文章权限的用户可以查看
+func (ctl *ArticleController) GetArticleTransitions(c *gin.Context) {
+	req, ok := bindTransitionRequest(c)
+	if !ok {
+		return
+	}
+	article, ok := ctl.findArticleHistory(c, req.ID, "没有权限查看该文章的审核记录")
+	if !ok {
+		return
+	}
+
+	transitions, err := ctl.articles.ListTransitions(c.Request.Context(), article.ID)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取审核记录成功", transitions)
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"backend/repository"
+	"backend/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditController 审计日志接口
+type AuditController struct {
+	audit repository.AuditRepository
+}
+
+// NewAuditController 创建审计日志控制器
+func NewAuditController(audit repository.AuditRepository) *AuditController {
+	return &AuditController{audit: audit}
+}
+
+// GetAuditList 获取审计日志列表
+func (ctl *AuditController) GetAuditList(c *gin.Context) {
+	var requestData struct {
+		PageNum  *int   `json:"pageNum"`
+		PageSize *int   `json:"pageSize"`
+		Action   string `json:"action"`
+		Target   string `json:"target"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+
+	logs, total, err := ctl.audit.List(c.Request.Context(), repository.AuditQuery{
+		Pagination: repository.Pagination{PageNum: requestData.PageNum, PageSize: requestData.PageSize},
+		Action:     requestData.Action,
+		Target:     requestData.Target,
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "审计日志获取成功", gin.H{
+		"total": total,
+		"list":  logs,
+	})
+}
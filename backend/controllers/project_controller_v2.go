@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListProjects GET /api/v2/projects 按项目名称筛选项目并分页，支持 name、page、page_size
+func (ctl *ProjectController) ListProjects(c *gin.Context) {
+	var query struct {
+		pageQuery
+		Name string `form:"name" json:"name"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	list, total, err := ctl.projects.List(c.Request.Context(), repository.ProjectQuery{
+		Pagination:  query.pagination(),
+		ProjectName: query.Name,
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+	listResponse(c, "项目列表获取成功", list, total, query.pageQuery)
+}
+
+// GetProject GET /api/v2/projects/:id 获取项目详情
+func (ctl *ProjectController) GetProject(c *gin.Context) {
+	project, ok := ctl.findProject(c)
+	if !ok {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取项目信息成功", project)
+}
+
+// CreateProject POST /api/v2/projects 新建项目，返回 201 和新建的项目
+func (ctl *ProjectController) CreateProject(c *gin.Context) {
+	var project models.Project
+	if err := c.ShouldBindJSON(&project); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(project); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	project.ID = 0
+	if err := ctl.projects.Create(c.Request.Context(), &project); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库插入失败: %w", err))
+		return
+	}
+	createdResponse(c, "添加成功", "/api/v2/projects/"+strconv.Itoa(project.ID), project)
+}
+
+// ReplaceProject PUT /api/v2/projects/:id 使用请求中的完整数据替换项目
+func (ctl *ProjectController) ReplaceProject(c *gin.Context) {
+	existing, ok := ctl.findProject(c)
+	if !ok {
+		return
+	}
+	var project models.Project
+	if err := c.ShouldBindJSON(&project); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	project.ID = existing.ID
+	ctl.saveProject(c, &project)
+}
+
+// PatchProject PATCH /api/v2/projects/:id 只更新请求中出现的字段
+func (ctl *ProjectController) PatchProject(c *gin.Context) {
+	project, ok := ctl.findProject(c)
+	if !ok {
+		return
+	}
+	id := project.ID
+	// 在原有数据上解析请求，未出现的字段保持不变
+	if err := c.ShouldBindJSON(project); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	project.ID = id
+	ctl.saveProject(c, project)
+}
+
+// saveProject 校验并更新项目，返回更新后的数据
+func (ctl *ProjectController) saveProject(c *gin.Context, project *models.Project) {
+	if err := utils.Validate(project); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	if err := ctl.projects.Update(c.Request.Context(), project); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "更新成功", project)
+}
+
+// RemoveProject DELETE /api/v2/projects/:id 删除项目，成功时返回 204
+func (ctl *ProjectController) RemoveProject(c *gin.Context) {
+	project, ok := ctl.findProject(c)
+	if !ok {
+		return
+	}
+	if err := ctl.projects.Delete(c.Request.Context(), project.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库删除失败: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// findProject 按路径中的 id 查询项目，不存在时返回 404
+func (ctl *ProjectController) findProject(c *gin.Context) (*models.Project, bool) {
+	id, ok := idParam(c)
+	if !ok {
+		return nil, false
+	}
+	project, err := ctl.projects.GetByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("项目不存在"))
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	return project, true
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"backend/repository"
+	"backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v2 接口的分页默认值，列表接口总是分页
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageQuery v2 列表接口的分页参数，例如 ?page=2&page_size=20
+type pageQuery struct {
+	Page     int `form:"page" json:"page" validate:"omitempty,min=1" msg:"页码必须大于 0"`
+	PageSize int `form:"page_size" json:"page_size" validate:"omitempty,min=1,max=100" msg:"每页数量在 1-100 之间"`
+}
+
+// pagination 转换为数据仓库的分页参数，未传时使用第一页和默认数量
+func (q *pageQuery) pagination() repository.Pagination {
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PageSize == 0 {
+		q.PageSize = defaultPageSize
+	}
+	return repository.Pagination{PageNum: &q.Page, PageSize: &q.PageSize}
+}
+
+// bindQuery 绑定并校验查询字符串参数，失败时已返回错误响应
+func bindQuery(c *gin.Context, query interface{}) bool {
+	if err := c.ShouldBindQuery(query); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return false
+	}
+	if err := utils.Validate(query); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	return true
+}
+
+// idParam 读取路径中的资源 id，不是正整数时返回 404
+func idParam(c *gin.Context) (int, bool) {
+	return pathID(c, "id")
+}
+
+// pathID 读取路径中名为 name 的 id 参数，不是正整数时返回 404
+func pathID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		utils.Fail(c, utils.NotFound("数据不存在"))
+		return 0, false
+	}
+	return id, true
+}
+
+// listResponse 返回 v2 列表接口的分页结果
+func listResponse(c *gin.Context, message string, list interface{}, total int, page pageQuery) {
+	utils.JSONResponse(c, http.StatusOK, message, gin.H{
+		"list":      list,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
+
+// createdResponse 返回 201 和新建的资源，Location 响应头指向该资源
+func createdResponse(c *gin.Context, message, location string, data interface{}) {
+	c.Header("Location", location)
+	utils.JSONResponse(c, http.StatusCreated, message, data)
+}
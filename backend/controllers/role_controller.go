@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleController 角色和权限相关接口
+type RoleController struct {
+	users repository.UserRepository
+}
+
+// NewRoleController 创建角色控制器
+func NewRoleController(users repository.UserRepository) *RoleController {
+	return &RoleController{users: users}
+}
+
+// GetRoleList 获取所有角色及其权限
+func (ctl *RoleController) GetRoleList(c *gin.Context) {
+	utils.JSONResponse(c, http.StatusOK, "角色列表获取成功", models.Roles)
+}
+
+// AssignRole 为用户分配角色
+func (ctl *RoleController) AssignRole(c *gin.Context) {
+	var requestData struct {
+		ID   int    `json:"id"`
+		Role string `json:"role" validate:"required,oneof=admin editor author viewer" msg:"用户角色设置错误"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	// 防止管理员误操作导致自己失去管理权限
+	if requestData.ID == c.GetInt("userID") {
+		utils.JSONResponse(c, http.StatusBadRequest, "不能修改自己的角色", nil)
+		return
+	}
+
+	// 检查用户是否存在
+	_, err := ctl.users.GetByID(c.Request.Context(), requestData.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusNotFound, "用户不存在", nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+
+	if err := ctl.users.UpdateRole(c.Request.Context(), requestData.ID, requestData.Role); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "角色分配成功", nil)
+}
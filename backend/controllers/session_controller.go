@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"backend/auth"
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController 登录会话管理接口
+// 用户可以管理自己的会话；拥有 user:manage 权限时可以通过 user_id 管理其他用户的会话
+type SessionController struct {
+	tokens *auth.TokenService
+}
+
+// NewSessionController 创建会话控制器
+func NewSessionController(tokens *auth.TokenService) *SessionController {
+	return &SessionController{tokens: tokens}
+}
+
+// targetUser 返回要操作的用户id，未指定 user_id 时为当前用户
+// 操作其他用户的会话需要用户管理权限，与 RequirePermission 一致，被限制的账号无论角色如何都会被拒绝
+func targetUser(c *gin.Context, userID int) (int, bool) {
+	currentUserID := c.GetInt("userID")
+	if userID == 0 || userID == currentUserID {
+		return currentUserID, true
+	}
+	if statusErr, ok := c.Get("accountStatus"); ok {
+		utils.JSONResponse(c, http.StatusForbidden, statusErr.(*models.AccountStatusError).Message, statusErr)
+		return 0, false
+	}
+	if !models.HasPermission(c.GetString("role"), models.PermUserManage) {
+		utils.JSONResponse(c, http.StatusForbidden, "没有权限管理其他用户的会话", nil)
+		return 0, false
+	}
+	return userID, true
+}
+
+// GetSessionList 获取用户当前有效的登录会话
+func (ctl *SessionController) GetSessionList(c *gin.Context) {
+	var requestData struct {
+		UserID int `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	userID, ok := targetUser(c, requestData.UserID)
+	if !ok {
+		return
+	}
+
+	sessions, err := ctl.tokens.Sessions(c.Request.Context(), userID, c.GetString("sessionID"))
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "会话列表获取成功", sessions)
+}
+
+// RevokeSession 注销一个登录会话，该会话的令牌立即失效
+// 被限制的账号也可以注销自己的会话，账号被盗用时仍然能够收回访问
+func (ctl *SessionController) RevokeSession(c *gin.Context) {
+	var requestData struct {
+		ID     string `json:"id" validate:"required" msg:"缺少会话id"`
+		UserID int    `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	userID, ok := targetUser(c, requestData.UserID)
+	if !ok {
+		return
+	}
+
+	err := ctl.tokens.RevokeSession(c.Request.Context(), userID, requestData.ID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		utils.JSONResponse(c, http.StatusNotFound, "会话不存在", nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("注销会话失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "会话已注销", nil)
+}
+
+// RevokeAllSessions 注销用户的所有登录会话，包括当前会话，被限制的账号也可以注销自己的会话
+func (ctl *SessionController) RevokeAllSessions(c *gin.Context) {
+	var requestData struct {
+		UserID int `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	userID, ok := targetUser(c, requestData.UserID)
+	if !ok {
+		return
+	}
+
+	if err := ctl.tokens.RevokeAll(c.Request.Context(), userID); err != nil {
+		utils.Fail(c, fmt.Errorf("注销会话失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "所有会话已注销", nil)
+}
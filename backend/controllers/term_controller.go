@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// termKind 区分标签和分类，提示信息分别写出完整的句子，便于翻译
+type termKind struct {
+	path       string // v2 接口的路径
+	notFound   string
+	nameExists string
+	slugExists string
+	listed     string
+	fetched    string
+}
+
+var (
+	tagKind = termKind{
+		path:       "/api/v2/tags/",
+		notFound:   "标签不存在",
+		nameExists: "标签名称已存在",
+		slugExists: "标签别名已存在",
+		listed:     "标签列表获取成功",
+		fetched:    "获取标签信息成功",
+	}
+	categoryKind = termKind{
+		path:       "/api/v2/categories/",
+		notFound:   "分类不存在",
+		nameExists: "分类名称已存在",
+		slugExists: "分类别名已存在",
+		listed:     "分类列表获取成功",
+		fetched:    "获取分类信息成功",
+	}
+)
+
+// TermController 标签和分类相关接口，两者的接口相同，分别创建一个控制器
+type TermController struct {
+	terms repository.TermRepository
+	kind  termKind
+}
+
+// NewTagController 创建标签控制器
+func NewTagController(tags repository.TermRepository) *TermController {
+	return &TermController{terms: tags, kind: tagKind}
+}
+
+// NewCategoryController 创建分类控制器
+func NewCategoryController(categories repository.TermRepository) *TermController {
+	return &TermController{terms: categories, kind: categoryKind}
+}
+
+// AddTerm 添加标签或分类，未填写别名时根据名称生成
+func (ctl *TermController) AddTerm(c *gin.Context) {
+	var requestData models.Term
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	requestData.ID = 0
+	if !ctl.saveTerm(c, &requestData) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "添加成功", requestData)
+}
+
+// EditTerm 编辑标签或分类，别名修改后原有的链接将失效
+func (ctl *TermController) EditTerm(c *gin.Context) {
+	var requestData models.Term
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if _, ok := ctl.findTerm(c, requestData.ID); !ok {
+		return
+	}
+	if !ctl.saveTerm(c, &requestData) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "更新成功", nil)
+}
+
+// GetTermList 获取标签或分类列表，每一项附带已发布的文章数
+func (ctl *TermController) GetTermList(c *gin.Context) {
+	var requestData struct {
+		PageNum  *int   `json:"pageNum"`
+		PageSize *int   `json:"pageSize"`
+		Name     string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+
+	list, total, err := ctl.terms.List(c.Request.Context(), repository.TermQuery{
+		Pagination: repository.Pagination{PageNum: requestData.PageNum, PageSize: requestData.PageSize},
+		Name:       requestData.Name,
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, ctl.kind.listed, gin.H{
+		"total": total,
+		"list":  list,
+	})
+}
+
+// DeleteTerm 删除标签或分类，同时从文章中移除，文章本身不受影响
+func (ctl *TermController) DeleteTerm(c *gin.Context) {
+	var requestData models.Term
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := ctl.terms.Delete(c.Request.Context(), requestData.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库删除失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "删除成功", nil)
+}
+
+// GetTermDetails 获取标签或分类详情
+func (ctl *TermController) GetTermDetails(c *gin.Context) {
+	var requestData struct {
+		ID int `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	term, ok := ctl.findTerm(c, requestData.ID)
+	if !ok {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, ctl.kind.fetched, term)
+}
+
+// findTerm 查询标签或分类，不存在时返回 404
+func (ctl *TermController) findTerm(c *gin.Context, id int) (*models.Term, bool) {
+	term, err := ctl.terms.GetByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound(ctl.kind.notFound))
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	return term, true
+}
+
+// saveTerm 校验并保存标签或分类，term.ID 为 0 时新增，失败时已返回错误响应
+// 名称和别名都不能与其他记录重复，重复时返回 409
+func (ctl *TermController) saveTerm(c *gin.Context, term *models.Term) bool {
+	term.Name = strings.TrimSpace(term.Name)
+	term.Slug = strings.TrimSpace(term.Slug)
+	if err := utils.Validate(term); err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	if term.Slug == "" {
+		// 英文名称可以直接生成别名，例如 Go Modules 生成 go-modules，中文名称需要手动填写
+		term.Slug = strings.ToLower(strings.Join(strings.Fields(term.Name), "-"))
+		if !utils.IsValidSlug(term.Slug) {
+			utils.Fail(c, utils.Invalid("无法根据名称生成别名，请填写别名", utils.FieldError{Field: "slug", Rule: "required"}))
+			return false
+		}
+	}
+
+	field, err := ctl.terms.Exists(c.Request.Context(), term)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return false
+	}
+	switch field {
+	case "name":
+		utils.Fail(c, utils.NewError(http.StatusConflict, "", ctl.kind.nameExists))
+		return false
+	case "slug":
+		utils.Fail(c, utils.NewError(http.StatusConflict, "", ctl.kind.slugExists))
+		return false
+	}
+
+	if term.ID == 0 {
+		err = ctl.terms.Create(c.Request.Context(), term)
+	} else {
+		err = ctl.terms.Update(c.Request.Context(), term)
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库保存失败: %w", err))
+		return false
+	}
+	return true
+}
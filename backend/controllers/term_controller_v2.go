@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTerms GET /api/v2/tags、/api/v2/categories 按名称或别名筛选并分页，支持 name、page、page_size
+func (ctl *TermController) ListTerms(c *gin.Context) {
+	var query struct {
+		pageQuery
+		Name string `form:"name" json:"name"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	list, total, err := ctl.terms.List(c.Request.Context(), repository.TermQuery{
+		Pagination: query.pagination(),
+		Name:       query.Name,
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+	listResponse(c, ctl.kind.listed, list, total, query.pageQuery)
+}
+
+// GetTerm GET /api/v2/tags/:id 获取标签或分类详情
+func (ctl *TermController) GetTerm(c *gin.Context) {
+	term, ok := ctl.findTermParam(c)
+	if !ok {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, ctl.kind.fetched, term)
+}
+
+// CreateTerm POST /api/v2/tags 新建标签或分类，返回 201 和新建的数据
+func (ctl *TermController) CreateTerm(c *gin.Context) {
+	var term models.Term
+	if err := c.ShouldBindJSON(&term); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	term.ID = 0
+	term.ArticleCount = 0
+	if !ctl.saveTerm(c, &term) {
+		return
+	}
+	createdResponse(c, "添加成功", ctl.kind.path+strconv.Itoa(term.ID), term)
+}
+
+// ReplaceTerm PUT /api/v2/tags/:id 使用请求中的完整数据替换标签或分类
+func (ctl *TermController) ReplaceTerm(c *gin.Context) {
+	existing, ok := ctl.findTermParam(c)
+	if !ok {
+		return
+	}
+	var term models.Term
+	if err := c.ShouldBindJSON(&term); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	term.ID = existing.ID
+	term.ArticleCount = existing.ArticleCount
+	if !ctl.saveTerm(c, &term) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "更新成功", term)
+}
+
+// PatchTerm PATCH /api/v2/tags/:id 只更新请求中出现的字段
+func (ctl *TermController) PatchTerm(c *gin.Context) {
+	term, ok := ctl.findTermParam(c)
+	if !ok {
+		return
+	}
+	id, count := term.ID, term.ArticleCount
+	// 在原有数据上解析请求，未出现的字段保持不变
+	if err := c.ShouldBindJSON(term); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	term.ID, term.ArticleCount = id, count
+	if !ctl.saveTerm(c, term) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "更新成功", term)
+}
+
+// RemoveTerm DELETE /api/v2/tags/:id 删除标签或分类，成功时返回 204
+func (ctl *TermController) RemoveTerm(c *gin.Context) {
+	term, ok := ctl.findTermParam(c)
+	if !ok {
+		return
+	}
+	if err := ctl.terms.Delete(c.Request.Context(), term.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库删除失败: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// findTermParam 按路径中的 id 查询标签或分类，不存在时返回 404
+func (ctl *TermController) findTermParam(c *gin.Context) (*models.Term, bool) {
+	id, ok := idParam(c)
+	if !ok {
+		return nil, false
+	}
+	return ctl.findTerm(c, id)
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"backend/auth"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorController 当前用户管理自己的两步验证，以及管理员重置其他用户的两步验证
+type TwoFactorController struct {
+	users     repository.UserRepository
+	twoFactor *auth.TwoFactorService
+	passwords *auth.PasswordService
+	guard     *auth.LoginGuard
+}
+
+// NewTwoFactorController 创建两步验证控制器
+func NewTwoFactorController(users repository.UserRepository, twoFactor *auth.TwoFactorService, passwords *auth.PasswordService, guard *auth.LoginGuard) *TwoFactorController {
+	return &TwoFactorController{users: users, twoFactor: twoFactor, passwords: passwords, guard: guard}
+}
+
+// respondTwoFactorError 把两步验证服务返回的错误转换为响应，err 为 nil 时返回 true
+func respondTwoFactorError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		utils.Fail(c, utils.NewError(http.StatusUnauthorized, models.CodeInvalidTwoFactorCode, err.Error()))
+	case errors.Is(err, auth.ErrTwoFactorRequired):
+		utils.Fail(c, utils.NewError(http.StatusForbidden, models.CodeTwoFactorMandatory, err.Error()))
+	case errors.Is(err, auth.ErrTwoFactorAlreadyEnabled):
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, models.CodeTwoFactorAlreadyEnabled, err.Error()))
+	case errors.Is(err, auth.ErrTwoFactorNotEnabled):
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, models.CodeTwoFactorNotEnabled, err.Error()))
+	case errors.Is(err, auth.ErrTwoFactorNotSetup):
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, models.CodeTwoFactorNotSetup, err.Error()))
+	default:
+		utils.Fail(c, fmt.Errorf("两步验证操作失败: %w", err))
+	}
+	return false
+}
+
+// currentUser 查询当前登录的用户
+func (ctl *TwoFactorController) currentUser(c *gin.Context) (*models.User, bool) {
+	user, err := ctl.users.GetByID(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return nil, false
+	}
+	return user, true
+}
+
+// GetStatus 查询当前用户的两步验证状态
+func (ctl *TwoFactorController) GetStatus(c *gin.Context) {
+	user, ok := ctl.currentUser(c)
+	if !ok {
+		return
+	}
+	status, err := ctl.twoFactor.Status(c.Request.Context(), user)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("查询两步验证状态失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取两步验证状态成功", status)
+}
+
+// Setup 生成新的密钥和二维码地址，提交验证码后才会开启
+func (ctl *TwoFactorController) Setup(c *gin.Context) {
+	user, ok := ctl.currentUser(c)
+	if !ok {
+		return
+	}
+	setup, err := ctl.twoFactor.Setup(c.Request.Context(), user)
+	if !respondTwoFactorError(c, err) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.JSONResponse(c, http.StatusOK, "请使用验证器扫描二维码，并提交验证器中的验证码", setup)
+}
+
+// Enable 提交验证器中的验证码开启两步验证，返回的恢复码只显示这一次
+func (ctl *TwoFactorController) Enable(c *gin.Context) {
+	var requestData struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.currentUser(c)
+	if !ok {
+		return
+	}
+	codes, err := ctl.twoFactor.Enable(c.Request.Context(), user, requestData.Code, c.ClientIP())
+	if !respondTwoFactorError(c, err) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.JSONResponse(c, http.StatusOK, "两步验证已开启，请妥善保存恢复码", gin.H{"recovery_codes": codes})
+}
+
+// Disable 关闭两步验证，需要同时提交密码和验证码（或恢复码）
+func (ctl *TwoFactorController) Disable(c *gin.Context) {
+	var requestData struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := ctl.guard.CheckTwoFactor(c.Request.Context(), user.ID, c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+	matched, err := ctl.passwords.Check(c.Request.Context(), user.ID, requestData.Password)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	if !matched {
+		if ctl.failStepUp(c, user) {
+			utils.JSONResponse(c, http.StatusUnauthorized, "密码不正确", nil)
+		}
+		return
+	}
+	if ctl.twoFactor.Required(user.Role) {
+		respondTwoFactorError(c, auth.ErrTwoFactorRequired)
+		return
+	}
+	if !ctl.verifyStepUp(c, user, requestData.Code) {
+		return
+	}
+	if !respondTwoFactorError(c, ctl.twoFactor.Disable(c.Request.Context(), user, c.ClientIP())) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "两步验证已关闭", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，需要提交验证器中的验证码
+func (ctl *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var requestData struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := ctl.guard.CheckTwoFactor(c.Request.Context(), user.ID, c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+	if !ctl.verifyStepUp(c, user, requestData.Code) {
+		return
+	}
+	codes, err := ctl.twoFactor.RegenerateRecoveryCodes(c.Request.Context(), user, c.ClientIP())
+	if !respondTwoFactorError(c, err) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.JSONResponse(c, http.StatusOK, "恢复码已重新生成，之前的恢复码全部失效", gin.H{"recovery_codes": codes})
+}
+
+// verifyStepUp 校验再次确认身份时提交的验证码或恢复码，调用前需要先通过 guard.CheckTwoFactor
+// 验证码错误时计入失败次数，与登录一样防止暴力猜测；失败时已返回错误响应
+func (ctl *TwoFactorController) verifyStepUp(c *gin.Context, user *models.User, code string) bool {
+	err := ctl.twoFactor.Verify(c.Request.Context(), user, code, c.ClientIP())
+	if errors.Is(err, auth.ErrInvalidTwoFactorCode) && !ctl.failStepUp(c, user) {
+		return false
+	}
+	if !respondTwoFactorError(c, err) {
+		return false
+	}
+	if err := ctl.guard.SucceedTwoFactor(c.Request.Context(), user.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("清除失败次数失败: %w", err))
+		return false
+	}
+	return true
+}
+
+// failStepUp 记录一次确认身份失败，记录失败时已返回错误响应并返回 false
+func (ctl *TwoFactorController) failStepUp(c *gin.Context, user *models.User) bool {
+	if err := ctl.guard.FailTwoFactor(c.Request.Context(), user.ID, user.Username, c.ClientIP()); err != nil {
+		utils.Fail(c, fmt.Errorf("记录失败次数失败: %w", err))
+		return false
+	}
+	return true
+}
+
+// Reset 管理员为丢失验证器和恢复码的用户关闭两步验证，用户可以登录后重新绑定
+func (ctl *TwoFactorController) Reset(c *gin.Context) {
+	var requestData struct {
+		ID int `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, err := ctl.users.GetByID(c.Request.Context(), requestData.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusNotFound, "用户不存在", nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	if !respondTwoFactorError(c, ctl.twoFactor.Reset(c.Request.Context(), user, c.GetInt("userID"), c.ClientIP())) {
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "两步验证已重置", nil)
+}
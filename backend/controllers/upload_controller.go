@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"backend/config"
 	"backend/utils"
 	"fmt"
 	"image"
@@ -11,12 +12,19 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 上传图片时返回的错误码
+const (
+	codeFileTooLarge        = "file_too_large"
+	codeUnsupportedFileType = "unsupported_file_type"
+)
+
 // formatFileSize 格式化文件大小
 func formatFileSize(size int64) string {
 	if size > 1024*1024 {
@@ -30,6 +38,16 @@ func getImageDimensions(img image.Image) (int, int) {
 	return img.Bounds().Dx(), img.Bounds().Dy()
 }
 
+// isAllowedExt 判断文件扩展名是否在允许列表中
+func isAllowedExt(ext string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(ext, a) {
+			return true
+		}
+	}
+	return false
+}
+
 // getProtocol 获取请求协议 (http/https)
 func getProtocol(c *gin.Context) string {
 	if c.Request.TLS != nil {
@@ -41,7 +59,7 @@ func getProtocol(c *gin.Context) string {
 // saveAndRespond 保存文件并响应
 func saveAndRespond(c *gin.Context, header *multipart.FileHeader, fileName, savePath string, err error) {
 	if saveErr := c.SaveUploadedFile(header, savePath); saveErr != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("保存文件失败: %v", saveErr), nil)
+		utils.Fail(c, fmt.Errorf("保存文件失败: %w", saveErr))
 		return
 	}
 	utils.JSONResponse(c, http.StatusOK, fmt.Sprintf("图片处理失败，但已保存原图: %v", err), gin.H{
@@ -54,32 +72,35 @@ func saveAndRespond(c *gin.Context, header *multipart.FileHeader, fileName, save
 func UploadImage(c *gin.Context) {
 	header, err := c.FormFile("file")
 	if err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("获取文件失败: %v", err), nil)
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, utils.CodeInvalidInput, "获取文件失败，请通过 file 字段上传图片").WithCause(err))
 		return
 	}
 
-	const maxUploadSize = 20 * 1024 * 1024 // 20MB
-	if header.Size > maxUploadSize {
-		utils.JSONResponse(c, http.StatusBadRequest, "文件大小不能超过20MB", nil)
+	uploadConf := config.Conf.Upload
+	if header.Size > uploadConf.MaxSizeBytes() {
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, codeFileTooLarge, fmt.Sprintf("文件大小不能超过%dMB", uploadConf.MaxSizeMB)).
+			WithParams(map[string]string{"max_mb": strconv.FormatInt(uploadConf.MaxSizeMB, 10)}))
 		return
 	}
 
 	fileExt := strings.ToLower(filepath.Ext(header.Filename))
-	if !strings.Contains(".jpg.jpeg.png.gif", fileExt) {
-		utils.JSONResponse(c, http.StatusBadRequest, "仅支持 jpg, jpeg, png, gif 格式的图片", nil)
+	if !isAllowedExt(fileExt, uploadConf.AllowedExt) {
+		allowed := strings.Join(uploadConf.AllowedExt, ", ")
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, codeUnsupportedFileType, fmt.Sprintf("仅支持 %s 格式的图片", allowed)).
+			WithParams(map[string]string{"allowed": allowed}))
 		return
 	}
 
 	fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), fileExt)
-	savePath := filepath.Join("./static/images", fileName)
+	savePath := filepath.Join(config.Conf.Server.StaticDir, "images", fileName)
 	if err := os.MkdirAll(filepath.Dir(savePath), os.ModePerm); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("创建文件夹失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("创建文件夹失败: %w", err))
 		return
 	}
 
 	file, err := header.Open()
 	if err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无法打开文件: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("无法打开文件: %w", err))
 		return
 	}
 	defer file.Close()
@@ -107,7 +128,7 @@ func UploadImage(c *gin.Context) {
 
 	outFile, err := os.Create(savePath)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("保存文件失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("保存文件失败: %w", err))
 		return
 	}
 	defer outFile.Close()
@@ -131,7 +152,7 @@ func UploadImage(c *gin.Context) {
 
 	compressedFileInfo, err := outFile.Stat()
 	if err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("获取压缩文件信息失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("获取压缩文件信息失败: %w", err))
 		return
 	}
 
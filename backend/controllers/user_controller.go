@@ -1,70 +1,70 @@
 package controllers
 
 import (
-	"backend/config"
+	"backend/auth"
+	"backend/i18n"
 	"backend/models"
+	"backend/repository"
 	"backend/utils"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
-// generateToken 生成 JWT 令牌，有效期为 30 天
-func generateToken(userID int) (string, error) {
-	claims := jwt.MapClaims{
-		"userID": userID,
-		"exp":    time.Now().Add(time.Hour * 24 * 30).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(config.JwtSecret)
+// UserController 用户相关接口
+type UserController struct {
+	users     repository.UserRepository
+	tokens    *auth.TokenService
+	guard     *auth.LoginGuard
+	twoFactor *auth.TwoFactorService
+	passwords *auth.PasswordService
 }
 
-// handleValidationErrorsForUser 处理数据验证错误
-func handleValidationErrorsForUser(c *gin.Context, err error) {
-	validationErrors := err.(validator.ValidationErrors)
-	for _, err := range validationErrors {
-		field := err.Field()
-		switch field {
-		case "Username":
-			utils.JSONResponse(c, http.StatusBadRequest, "用户名不能为空，且长度在 1-20 位之间", nil)
-		case "Password":
-			utils.JSONResponse(c, http.StatusBadRequest, "密码长度在 6-30 位之间，且必须包含大小写字母、数字和特殊字符", nil)
-		case "Status":
-			utils.JSONResponse(c, http.StatusBadRequest, "用户状态设置错误", nil)
-		case "Role":
-			utils.JSONResponse(c, http.StatusBadRequest, "用户角色设置错误", nil)
-		}
-		break
-	}
+// NewUserController 创建用户控制器
+func NewUserController(users repository.UserRepository, tokens *auth.TokenService, guard *auth.LoginGuard, twoFactor *auth.TwoFactorService, passwords *auth.PasswordService) *UserController {
+	return &UserController{users: users, tokens: tokens, guard: guard, twoFactor: twoFactor, passwords: passwords}
 }
 
-// validatePhoneAndEmail 验证手机号和邮箱格式
-func validatePhoneAndEmail(c *gin.Context, user *models.User) bool {
-	if user.PhoneNumber != "" && !models.IsValidPhoneNumber(user.PhoneNumber) {
-		utils.JSONResponse(c, http.StatusBadRequest, "请输入正确的手机号", nil)
-		return false
+// clientOf 读取发起请求的设备信息，记录在刷新令牌中
+func clientOf(c *gin.Context) auth.Client {
+	return auth.Client{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// normalizeLocale 把用户提交的语言偏好转换为支持的语言，无法识别时清空，按 Accept-Language 选择
+func normalizeLocale(locale string) string {
+	if normalized, ok := i18n.Normalize(locale); ok {
+		return normalized
 	}
-	if user.Email != "" && !models.IsValidEmail(user.Email) {
-		utils.JSONResponse(c, http.StatusBadRequest, "请输入正确的邮箱", nil)
-		return false
+	return ""
+}
+
+// respondPasswordError 把密码服务返回的错误转换为响应，err 为 nil 时返回 true
+// 不符合密码策略时返回策略说明，其他错误按服务器错误处理
+func respondPasswordError(c *gin.Context, err error) bool {
+	var policyErr *auth.PasswordPolicyError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &policyErr):
+		utils.Fail(c, utils.NewError(http.StatusBadRequest, policyErr.Code, policyErr.Message).WithParams(policyErr.Params))
+	default:
+		utils.Fail(c, fmt.Errorf("密码设置失败: %w", err))
 	}
-	return true
+	return false
 }
 
 // checkUsernameExists 检查用户名是否存在
-func checkUsernameExists(c *gin.Context, username string) bool {
-	var count int
-	err := config.DB.QueryRow("SELECT COUNT(*) FROM user WHERE username = ?", username).Scan(&count)
+func (ctl *UserController) checkUsernameExists(c *gin.Context, username string) bool {
+	exists, err := ctl.users.UsernameExists(c.Request.Context(), username)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库错误: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库错误: %w", err))
 		return true
 	}
-	if count > 0 {
+	if exists {
 		utils.JSONResponse(c, http.StatusBadRequest, "用户名已存在", nil)
 		return true
 	}
@@ -72,48 +72,46 @@ func checkUsernameExists(c *gin.Context, username string) bool {
 }
 
 // Register 处理用户注册
-func Register(c *gin.Context) {
+func (ctl *UserController) Register(c *gin.Context) {
 	var user models.User
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&user); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&user); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
-	// 设置默认值
+	// 设置默认值，注册的用户都是访客，初始管理员由配置 admin 在启动时创建，见 auth.EnsureAdmin
 	user.RegisterTime = time.Now().Format("2006-01-02 15:04:05")
 	user.Status = "0"
-	user.Role = "0"
+	user.Role = models.RoleViewer
 
 	// 验证用户数据
-	if err := utils.GetValidator().Struct(user); err != nil {
-		handleValidationErrorsForUser(c, err)
+	if err := utils.Validate(user); err != nil {
+		utils.Fail(c, err)
 		return
 	}
-
-	// 验证手机号和邮箱
-	if !validatePhoneAndEmail(c, &user) {
+	if !respondPasswordError(c, ctl.passwords.Validate(user.Password)) {
 		return
 	}
+	user.Locale = normalizeLocale(user.Locale)
 
 	// 检查用户名是否存在
-	if checkUsernameExists(c, user.Username) {
+	if ctl.checkUsernameExists(c, user.Username) {
 		return
 	}
 
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := ctl.passwords.Hash(user.Password)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("密码加密失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("密码加密失败: %w", err))
 		return
 	}
 
 	// 插入新用户数据
-	_, err = config.DB.Exec("INSERT INTO user (username, password, phone_number, email, real_name, register_time, avatar, status, role) VALUES (?,?,?,?,?,?,?,?,?)",
-		user.Username, string(hashedPassword), user.PhoneNumber, user.Email, user.RealName, user.RegisterTime, user.Avatar, user.Status, user.Role)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("用户注册失败: %v", err), nil)
+	user.Password = hashedPassword
+	if err := ctl.users.Create(c.Request.Context(), &user); err != nil {
+		utils.Fail(c, fmt.Errorf("用户注册失败: %w", err))
 		return
 	}
 
@@ -121,53 +119,328 @@ func Register(c *gin.Context) {
 }
 
 // Login 处理用户登录
-func Login(c *gin.Context) {
+func (ctl *UserController) Login(c *gin.Context) {
 	var user models.User
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&user); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&user); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+
+	// 检查 IP 限流、失败等待时间和账号锁定
+	ip := c.ClientIP()
+	if err := ctl.guard.Check(c.Request.Context(), user.Username, ip); err != nil {
+		respondLoginBlocked(c, err)
 		return
 	}
 
 	// 查询数据库中的用户信息
-	var (
-		hashedPassword string
-		userID         int
-		avatar         string
-	)
-	err := config.DB.QueryRow("SELECT id, password, avatar FROM user WHERE username = ?", user.Username).Scan(&userID, &hashedPassword, &avatar)
+	storedUser, err := ctl.users.GetByUsername(c.Request.Context(), user.Username)
+	if err != nil {
+		ctl.loginFailed(c, user.Username, ip)
+		return
+	}
+
+	// 验证密码，哈希的算法或参数与当前配置不一致时会顺便升级，storedUser.Password 随之更新
+	matched, err := ctl.passwords.Login(c.Request.Context(), storedUser, user.Password)
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("密码校验失败: %w", err))
+		return
+	}
+	if !matched {
+		ctl.loginFailed(c, user.Username, ip)
+		return
+	}
+	if storedUser.Locale != "" {
+		utils.SetLocale(c, storedUser.Locale)
+	}
+
+	// 已注销的账号不能登录
+	if statusErr := storedUser.StatusError(); statusErr != nil && statusErr.Code == models.CodeAccountCancelled {
+		utils.JSONResponse(c, http.StatusForbidden, statusErr.Message, statusErr)
+		return
+	}
+
+	// 开启了两步验证或者策略要求两步验证时，先返回临时令牌，验证通过后再签发令牌
+	// 此时不清除失败记录，验证码错误同样计入失败次数
+	challenge, err := ctl.twoFactor.Challenge(c.Request.Context(), storedUser)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusUnauthorized, "用户名或密码无效", nil)
+		utils.Fail(c, fmt.Errorf("查询两步验证设置失败: %w", err))
+		return
+	}
+	if challenge != nil {
+		message := "请输入两步验证码"
+		if challenge.EnrollmentRequired {
+			message = "当前角色必须开启两步验证，请先绑定验证器"
+		}
+		utils.JSONResponse(c, http.StatusOK, message, challenge)
 		return
 	}
 
-	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(user.Password)); err != nil {
-		utils.JSONResponse(c, http.StatusUnauthorized, "用户名或密码无效", nil)
+	ctl.completeLogin(c, storedUser, nil)
+}
+
+// completeLogin 清除登录失败记录，签发访问令牌和刷新令牌并返回登录结果，extra 中的字段会合并到返回数据中
+func (ctl *UserController) completeLogin(c *gin.Context, user *models.User, extra gin.H) {
+	if err := ctl.guard.Succeed(c.Request.Context(), user.Username); err != nil {
+		utils.Fail(c, fmt.Errorf("清除登录失败记录失败: %w", err))
+		return
+	}
+
+	// 已注销的账号不能登录；被限制的账号可以登录浏览，登录结果中返回限制信息
+	statusErr := user.StatusError()
+	if statusErr != nil && statusErr.Code == models.CodeAccountCancelled {
+		utils.JSONResponse(c, http.StatusForbidden, statusErr.Message, statusErr)
 		return
 	}
 
-	// 生成 JWT 令牌
-	token, err := generateToken(userID)
+	// 签发访问令牌和刷新令牌
+	pair, err := ctl.tokens.Issue(c.Request.Context(), user.ID, clientOf(c))
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("生成令牌失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("生成令牌失败: %w", err))
 		return
 	}
 
-	utils.JSONResponse(c, http.StatusOK, "登录成功", gin.H{
-		"token":  token,
-		"avatar": avatar,
-	})
+	data := gin.H{
+		"token":          pair.AccessToken,
+		"refresh_token":  pair.RefreshToken,
+		"expires_in":     pair.ExpiresIn,
+		"avatar":         user.Avatar,
+		"account_status": statusErr,
+		// 为 true 时前端应跳转到修改密码页面，修改前其他需要登录的接口都会返回 403
+		"must_change_password": user.MustChangePassword,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	utils.JSONResponse(c, http.StatusOK, "登录成功", data)
+}
+
+// twoFactorLoginRequest 登录第二步的请求参数
+type twoFactorLoginRequest struct {
+	InterimToken string `json:"interim_token"`
+	Code         string `json:"code"` // 验证器中的 6 位验证码，或者恢复码
+}
+
+// challengeUser 校验登录第二步的临时令牌，并检查该用户名的失败等待时间和锁定状态
+func (ctl *UserController) challengeUser(c *gin.Context, interimToken string) (*models.User, bool) {
+	user, err := ctl.twoFactor.ParseChallenge(c.Request.Context(), interimToken)
+	if errors.Is(err, auth.ErrInvalidActionToken) {
+		utils.JSONResponse(c, http.StatusUnauthorized, "登录已过期，请重新输入用户名和密码", nil)
+		return nil, false
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("校验临时令牌失败: %w", err))
+		return nil, false
+	}
+	if err := ctl.guard.Check(c.Request.Context(), user.Username, c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// LoginTwoFactor 登录第二步，提交验证码或恢复码后签发令牌
+func (ctl *UserController) LoginTwoFactor(c *gin.Context) {
+	var requestData twoFactorLoginRequest
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.challengeUser(c, requestData.InterimToken)
+	if !ok {
+		return
+	}
+
+	err := ctl.twoFactor.Verify(c.Request.Context(), user, requestData.Code, c.ClientIP())
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		if err := ctl.guard.Fail(c.Request.Context(), user.Username, c.ClientIP()); err != nil {
+			utils.Fail(c, fmt.Errorf("记录登录失败次数失败: %w", err))
+			return
+		}
+		utils.JSONResponse(c, http.StatusUnauthorized, err.Error(), nil)
+		return
+	case errors.Is(err, auth.ErrTwoFactorNotEnabled) && ctl.twoFactor.Required(user.Role):
+		utils.JSONResponse(c, http.StatusBadRequest, "当前角色必须开启两步验证，请先绑定验证器", gin.H{"code": models.CodeTwoFactorEnrollmentRequired})
+		return
+	case errors.Is(err, auth.ErrTwoFactorNotEnabled):
+		// 两次请求之间两步验证被管理员重置，需要重新登录
+		utils.JSONResponse(c, http.StatusUnauthorized, "登录已过期，请重新输入用户名和密码", nil)
+		return
+	case err != nil:
+		utils.Fail(c, fmt.Errorf("校验验证码失败: %w", err))
+		return
+	}
+
+	ctl.completeLogin(c, user, nil)
+}
+
+// LoginTwoFactorSetup 策略要求开启两步验证但尚未绑定时，使用临时令牌获取密钥
+func (ctl *UserController) LoginTwoFactorSetup(c *gin.Context) {
+	var requestData twoFactorLoginRequest
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.challengeUser(c, requestData.InterimToken)
+	if !ok {
+		return
+	}
+	if !ctl.twoFactor.Required(user.Role) {
+		utils.JSONResponse(c, http.StatusForbidden, "请登录后在账号设置中开启两步验证", nil)
+		return
+	}
+
+	setup, err := ctl.twoFactor.Setup(c.Request.Context(), user)
+	if errors.Is(err, auth.ErrTwoFactorAlreadyEnabled) {
+		utils.JSONResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("生成两步验证密钥失败: %w", err))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.JSONResponse(c, http.StatusOK, "请使用验证器扫描二维码，并提交验证器中的验证码", setup)
+}
+
+// LoginTwoFactorEnable 提交验证码完成绑定，开启两步验证后直接登录，返回结果中包含恢复码
+func (ctl *UserController) LoginTwoFactorEnable(c *gin.Context) {
+	var requestData twoFactorLoginRequest
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	user, ok := ctl.challengeUser(c, requestData.InterimToken)
+	if !ok {
+		return
+	}
+	if !ctl.twoFactor.Required(user.Role) {
+		utils.JSONResponse(c, http.StatusForbidden, "请登录后在账号设置中开启两步验证", nil)
+		return
+	}
+
+	codes, err := ctl.twoFactor.Enable(c.Request.Context(), user, requestData.Code, c.ClientIP())
+	if !respondTwoFactorError(c, err) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	ctl.completeLogin(c, user, gin.H{"recovery_codes": codes})
+}
+
+// loginFailed 记录一次登录失败并返回统一的错误信息，不区分用户名不存在和密码错误
+func (ctl *UserController) loginFailed(c *gin.Context, username, ip string) {
+	if err := ctl.guard.Fail(c.Request.Context(), username, ip); err != nil {
+		utils.Fail(c, fmt.Errorf("记录登录失败次数失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusUnauthorized, "用户名或密码无效", nil)
+}
+
+// respondLoginBlocked 登录被拦截时返回 429 和需要等待的秒数
+func respondLoginBlocked(c *gin.Context, err error) {
+	var blocked *auth.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		utils.Fail(c, fmt.Errorf("检查登录限制失败: %w", err))
+		return
+	}
+	c.Header("Retry-After", strconv.Itoa(blocked.RetryAfter))
+	utils.JSONResponse(c, http.StatusTooManyRequests, blocked.Message, blocked)
+}
+
+// UnlockUser 解除因连续登录失败导致的账号锁定
+func (ctl *UserController) UnlockUser(c *gin.Context) {
+	var requestData struct {
+		ID int `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+
+	user, err := ctl.users.GetByID(c.Request.Context(), requestData.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.JSONResponse(c, http.StatusNotFound, "用户不存在", nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+
+	if err := ctl.guard.Unlock(c.Request.Context(), user.Username, c.GetInt("userID"), c.ClientIP()); err != nil {
+		utils.Fail(c, fmt.Errorf("解除锁定失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "解除锁定成功", nil)
+}
+
+// refreshTokenRequest 刷新令牌和退出登录的请求参数
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" msg:"缺少刷新令牌"`
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌
+func (ctl *UserController) RefreshToken(c *gin.Context) {
+	var requestData refreshTokenRequest
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+
+	pair, err := ctl.tokens.Refresh(c.Request.Context(), requestData.RefreshToken, clientOf(c))
+	switch {
+	case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenExpired):
+		utils.JSONResponse(c, http.StatusUnauthorized, err.Error(), nil)
+		return
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		utils.JSONResponse(c, http.StatusUnauthorized, "刷新令牌已失效，请重新登录", nil)
+		return
+	case errors.Is(err, auth.ErrAccountCancelled):
+		utils.JSONResponse(c, http.StatusForbidden, err.Error(), gin.H{"code": models.CodeAccountCancelled})
+		return
+	case err != nil:
+		utils.Fail(c, fmt.Errorf("刷新令牌失败: %w", err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "刷新成功", pair)
+}
+
+// Logout 退出登录，注销刷新令牌所在的会话
+// 该会话中尚未过期的访问令牌会加入黑名单，之后的请求立即返回 401
+func (ctl *UserController) Logout(c *gin.Context) {
+	var requestData refreshTokenRequest
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+
+	if err := ctl.tokens.Revoke(c.Request.Context(), requestData.RefreshToken); err != nil {
+		utils.Fail(c, fmt.Errorf("退出登录失败: %w", err))
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, "已退出登录", nil)
 }
 
 // AddUser 添加新用户（需要 JWT 身份验证）
-func AddUser(c *gin.Context) {
+func (ctl *UserController) AddUser(c *gin.Context) {
 	var newUser models.User
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&newUser); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&newUser); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
@@ -176,25 +449,24 @@ func AddUser(c *gin.Context) {
 	newUser.Status = "0"
 
 	// 验证数据合法性
-	if err := utils.GetValidator().Struct(newUser); err != nil {
-		handleValidationErrorsForUser(c, err)
+	if err := utils.Validate(newUser); err != nil {
+		utils.Fail(c, err)
 		return
 	}
-
-	// 验证手机号和邮箱
-	if !validatePhoneAndEmail(c, &newUser) {
+	if !respondPasswordError(c, ctl.passwords.Validate(newUser.Password)) {
 		return
 	}
+	newUser.Locale = normalizeLocale(newUser.Locale)
 
 	// 检查用户名是否存在
-	if checkUsernameExists(c, newUser.Username) {
+	if ctl.checkUsernameExists(c, newUser.Username) {
 		return
 	}
 
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	hashedPassword, err := ctl.passwords.Hash(newUser.Password)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("密码加密失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("密码加密失败: %w", err))
 		return
 	}
 
@@ -204,10 +476,9 @@ func AddUser(c *gin.Context) {
 	}
 
 	// 插入新用户数据
-	_, err = config.DB.Exec("INSERT INTO user (username, password, phone_number, email, real_name, register_time, avatar, creator_id, status, role) VALUES (?,?,?,?,?,?,?,?,?,?)",
-		newUser.Username, string(hashedPassword), newUser.PhoneNumber, newUser.Email, newUser.RealName, newUser.RegisterTime, newUser.Avatar, newUser.CreatorID, newUser.Status, newUser.Role)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("用户添加失败: %v", err), nil)
+	newUser.Password = hashedPassword
+	if err := ctl.users.Create(c.Request.Context(), &newUser); err != nil {
+		utils.Fail(c, fmt.Errorf("用户添加失败: %w", err))
 		return
 	}
 
@@ -215,145 +486,124 @@ func AddUser(c *gin.Context) {
 }
 
 // UpdateUser 更新现有用户信息
-func UpdateUser(c *gin.Context) {
+func (ctl *UserController) UpdateUser(c *gin.Context) {
 	// UpdateUser 模型表示用户更新的数据结构
 	var updatedUser struct {
 		ID          int    `json:"id"`
-		Username    string `json:"username" validate:"required,min=1,max=20"`
-		PhoneNumber string `json:"phone_number"`
-		Email       string `json:"email"`
+		Username    string `json:"username" validate:"required,min=1,max=20" msg:"用户名不能为空，且长度在 1-20 位之间"`
+		PhoneNumber string `json:"phone_number" validate:"omitempty,phone" msg:"请输入正确的手机号"`
+		Email       string `json:"email" validate:"omitempty,email" msg:"请输入正确的邮箱"`
 		RealName    string `json:"real_name"`
 		Avatar      string `json:"avatar"`
-		Status      string `json:"status" validate:"required,oneof=0 1 2"`
-		Role        string `json:"role" validate:"required,oneof=0 1"`
+		Status      string `json:"status" validate:"required,oneof=0 1 2" msg:"用户状态设置错误"`
+		Role        string `json:"role" validate:"required,oneof=admin editor author viewer" msg:"用户角色设置错误"`
+		// 限制或注销的原因，以及限制的解除时间（格式 2006-01-02 15:04:05，为空表示长期限制）
+		StatusReason    string `json:"status_reason" validate:"max=255" msg:"状态原因不能超过 255 个字符"`
+		RestrictedUntil string `json:"restricted_until"`
 	}
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&updatedUser); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&updatedUser); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
 	// 验证数据合法性
-	if err := utils.GetValidator().Struct(updatedUser); err != nil {
-		handleValidationErrorsForUser(c, err)
+	if err := utils.Validate(updatedUser); err != nil {
+		utils.Fail(c, err)
 		return
 	}
 
-	// 验证手机号和邮箱
-	if !validatePhoneAndEmail(c, &models.User{
-		PhoneNumber: updatedUser.PhoneNumber,
-		Email:       updatedUser.Email,
-	}) {
-		return
+	// 解除时间只对限制状态有效，恢复正常时清空原因
+	switch updatedUser.Status {
+	case models.UserStatusNormal:
+		updatedUser.StatusReason = ""
+		updatedUser.RestrictedUntil = ""
+	case models.UserStatusCancelled:
+		updatedUser.RestrictedUntil = ""
+	}
+	if updatedUser.RestrictedUntil != "" {
+		until, err := time.ParseInLocation(models.TimeLayout, updatedUser.RestrictedUntil, time.Local)
+		if err != nil {
+			utils.JSONResponse(c, http.StatusBadRequest, "限制解除时间格式错误，应为 2006-01-02 15:04:05", nil)
+			return
+		}
+		if !until.After(time.Now()) {
+			utils.JSONResponse(c, http.StatusBadRequest, "限制解除时间必须晚于当前时间", nil)
+			return
+		}
 	}
 
-	// 检查用户是否存在
-	var count int
-	err := config.DB.QueryRow("SELECT COUNT(*) FROM user WHERE id = ?", updatedUser.ID).Scan(&count)
-	if err != nil || count == 0 {
+	// 检查用户是否存在，同时获取当前的用户名
+	currentUser, err := ctl.users.GetByID(c.Request.Context(), updatedUser.ID)
+	if errors.Is(err, repository.ErrNotFound) {
 		utils.JSONResponse(c, http.StatusNotFound, "用户不存在", nil)
 		return
 	}
-
-	// 获取当前用户的用户名
-	var currentUsername string
-	err = config.DB.QueryRow("SELECT username FROM user WHERE id = ?", updatedUser.ID).Scan(&currentUsername)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询错误: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库查询错误: %w", err))
 		return
 	}
 
 	// 如果用户名跟接收的用户名不一样，则判断用户名是否存在
-	if currentUsername != updatedUser.Username && checkUsernameExists(c, updatedUser.Username) {
+	if currentUser.Username != updatedUser.Username && ctl.checkUsernameExists(c, updatedUser.Username) {
 		return
 	}
 
 	// 更新用户信息
-	_, err = config.DB.Exec(
-		"UPDATE user SET username= IFNULL(?, username), phone_number = IFNULL(?, phone_number), email = IFNULL(?, email), real_name = IFNULL(?, real_name), avatar = IFNULL(?, avatar), status = IFNULL(?, status), role = IFNULL(?, role) WHERE id = ?",
-		updatedUser.Username, updatedUser.PhoneNumber, updatedUser.Email, updatedUser.RealName, updatedUser.Avatar, updatedUser.Status, updatedUser.Role, updatedUser.ID,
-	)
+	err = ctl.users.Update(c.Request.Context(), &models.User{
+		ID:              updatedUser.ID,
+		Username:        updatedUser.Username,
+		PhoneNumber:     updatedUser.PhoneNumber,
+		Email:           updatedUser.Email,
+		RealName:        updatedUser.RealName,
+		Avatar:          updatedUser.Avatar,
+		Status:          updatedUser.Status,
+		StatusReason:    updatedUser.StatusReason,
+		RestrictedUntil: updatedUser.RestrictedUntil,
+		Role:            updatedUser.Role,
+	})
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("用户信息更新失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("用户信息更新失败: %w", err))
 		return
 	}
 
+	// 用户被限制或注销时，让其所有登录立即失效
+	if updatedUser.Status != currentUser.Status && updatedUser.Status != "0" {
+		if err := ctl.tokens.RevokeAll(c.Request.Context(), updatedUser.ID); err != nil {
+			utils.Fail(c, fmt.Errorf("注销用户会话失败: %w", err))
+			return
+		}
+	}
+
 	utils.JSONResponse(c, http.StatusOK, "用户信息更新成功", nil)
 }
 
-// GetUserList 获取用户列表
-func GetUserList(c *gin.Context) {
+// GetUserList 获取用户列表，包含邮箱和账号状态，需要用户管理权限
+func (ctl *UserController) GetUserList(c *gin.Context) {
 	var requestData struct {
-		PageNum  *int   `json:"pageNum"`
-		PageSize *int   `json:"pageSize"`
-		Username string `json:"username"`
-		Status   string `json:"status"`
+		PageNum       *int   `json:"pageNum"`
+		PageSize      *int   `json:"pageSize"`
+		Username      string `json:"username"`
+		Status        string `json:"status"`
+		VerifiedEmail *bool  `json:"verified_email"` // 按邮箱是否已验证筛选，不传时不筛选
 	}
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
-	// 构建查询条件
-	query := "SELECT id, username, phone_number, email, real_name, register_time, avatar, status, role FROM user  WHERE 1=1"
-	args := []interface{}{}
-
-	if requestData.Username != "" {
-		query += " AND username LIKE ?"
-		args = append(args, "%"+requestData.Username+"%")
-	}
-
-	if requestData.Status != "" {
-		query += " AND status = ?"
-		args = append(args, requestData.Status)
-	}
-
-	query += " ORDER BY id DESC" // 添加按照 id 降序排列
-
-	if requestData.PageNum != nil && requestData.PageSize != nil {
-		// 计算偏移量
-		offset := (*requestData.PageNum - 1) * *requestData.PageSize
-		query += " LIMIT ? OFFSET ?"
-		args = append(args, *requestData.PageSize, offset)
-	}
-
-	// 执行查询
-	rows, err := config.DB.Query(query, args...)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询列表失败: %v", err), nil)
-		return
-	}
-	defer rows.Close()
-
-	// 解析结果
-	var users []models.User = []models.User{} // 初始化为空切片
-	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.PhoneNumber, &user.Email, &user.RealName, &user.RegisterTime, &user.Avatar, &user.Status, &user.Role); err != nil {
-			utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据解析失败: %v", err), nil)
-			return
-		}
-		users = append(users, user)
-	}
-
-	// 获取总记录数
-	var total int
-	args2 := []interface{}{}
-	countQuery := "SELECT COUNT(*) FROM user WHERE 1=1"
-	if requestData.Username != "" {
-		countQuery += " AND username LIKE ?"
-		args2 = append(args2, "%"+requestData.Username+"%")
-	}
-	if requestData.Status != "" {
-		countQuery += " AND status = ?"
-		args2 = append(args2, requestData.Status)
-	}
-	err = config.DB.QueryRow(countQuery, args2...).Scan(&total)
+	// 查询列表数据和总记录数
+	users, total, err := ctl.users.List(c.Request.Context(), repository.UserQuery{
+		Pagination:    repository.Pagination{PageNum: requestData.PageNum, PageSize: requestData.PageSize},
+		Username:      requestData.Username,
+		Status:        requestData.Status,
+		VerifiedEmail: requestData.VerifiedEmail,
+	})
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询记录总数失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
 		return
 	}
 
@@ -365,87 +615,82 @@ func GetUserList(c *gin.Context) {
 }
 
 // GetUserInfo 根据用户id查询单条用户信息
-func GetUserInfo(c *gin.Context) {
+func (ctl *UserController) GetUserInfo(c *gin.Context) {
 	var queryInfo struct {
 		ID int `json:"id"`
 	}
-	var userInfo models.User
 
 	// 绑定 JSON 数据
-	if err := c.BindJSON(&queryInfo); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&queryInfo); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
-	sql := "select id, username, phone_number,email,real_name,avatar,status,role from user where id = ?"
-	err := config.DB.QueryRow(sql, queryInfo.ID).Scan(&userInfo.ID, &userInfo.Username, &userInfo.PhoneNumber, &userInfo.Email, &userInfo.RealName, &userInfo.Avatar, &userInfo.Status, &userInfo.Role)
+	userInfo, err := ctl.users.GetByID(c.Request.Context(), queryInfo.ID)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
 		return
 	}
 	utils.JSONResponse(c, http.StatusOK, "获取用户信息成功", userInfo)
 }
 
 // 重置密码
-func ResetPassword(c *gin.Context) {
+func (ctl *UserController) ResetPassword(c *gin.Context) {
 	var requestData struct {
 		ID int `json:"id"`
 	}
-	if err := c.BindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 	// 检查用户是否存在
-	var count int
-	err := config.DB.QueryRow("SELECT COUNT(*) FROM user WHERE id = ?", requestData.ID).Scan(&count)
-	if err != nil || count == 0 {
+	if _, err := ctl.users.GetByID(c.Request.Context(), requestData.ID); errors.Is(err, repository.ErrNotFound) {
 		utils.JSONResponse(c, http.StatusNotFound, "用户不存在", nil)
 		return
+	} else if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
 	}
-	// 设置默认密码
-	defaultPassword := "123456"
-	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(defaultPassword), bcrypt.DefaultCost)
+	// 生成一次性临时密码并更新数据库，要求用户下次登录后修改
+	temporaryPassword, err := ctl.passwords.SetTemporary(c.Request.Context(), requestData.ID)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("密码加密失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("重置密码失败: %w", err))
 		return
 	}
 
-	// 更新数据库中的密码
-	_, err = config.DB.Exec("UPDATE user SET password =? WHERE id =?", string(hashedPassword), requestData.ID)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库更新失败：%v", err), nil)
+	// 密码重置后，用户之前的登录全部失效
+	if err := ctl.tokens.RevokeAll(c.Request.Context(), requestData.ID); err != nil {
+		utils.Fail(c, fmt.Errorf("注销用户会话失败: %w", err))
 		return
 	}
 
-	// 返回新密码给客户端
-	utils.JSONResponse(c, http.StatusOK, "密码重置成功", gin.H{"newPassword": defaultPassword})
+	// 临时密码只在本次响应中返回给管理员，不记录日志，也不允许缓存
+	c.Header("Cache-Control", "no-store")
+	utils.JSONResponse(c, http.StatusOK, "密码重置成功，请将临时密码告知用户", gin.H{"newPassword": temporaryPassword})
 }
 
-// ChangePassword 修改用户密码
-func ChangePassword(c *gin.Context) {
+// ChangePassword 修改用户密码，被限制的账号也可以修改自己的密码
+func (ctl *UserController) ChangePassword(c *gin.Context) {
 	var requestData struct {
-		Password    string `json:"password" validate:"required"`
-		NewPassword string `json:"newPassword" validate:"required,min=6,max=30,containsany=abcdefghijklmnopqrstuvwxyz,containsany=ABCDEFGHIJKLMNOPQRSTUVWXYZ,containsany=0123456789,containsany=.!@#$%^&*()"`
+		Password    string `json:"password" validate:"required" msg:"旧密码不能为空"`
+		NewPassword string `json:"newPassword"` // 按密码策略校验
 	}
 
-	if err := c.BindJSON(&requestData); err != nil {
-		utils.JSONResponse(c, http.StatusBadRequest, fmt.Sprintf("无效的输入: %v", err), nil)
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
 		return
 	}
 
 	// 验证请求数据
-	if err := utils.GetValidator().Struct(requestData); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		for _, err := range validationErrors {
-			field := err.Field()
-			switch field {
-			case "Password":
-				utils.JSONResponse(c, http.StatusBadRequest, "旧密码不能为空", nil)
-			case "NewPassword":
-				utils.JSONResponse(c, http.StatusBadRequest, "新密码长度在 6-30 位之间，且必须包含大小写字母、数字和特殊字符", nil)
-			}
-			break
-		}
+	if err := utils.Validate(requestData); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	if !respondPasswordError(c, ctl.passwords.Validate(requestData.NewPassword)) {
+		return
+	}
+
+	if requestData.NewPassword == requestData.Password {
+		utils.JSONResponse(c, http.StatusBadRequest, "新密码不能与旧密码相同", nil)
 		return
 	}
 
@@ -459,31 +704,62 @@ func ChangePassword(c *gin.Context) {
 	}
 
 	// 检测密码是否与数据库一致
-	var currentHashedPassword string
-	err := config.DB.QueryRow("SELECT password FROM user WHERE id = ?", currentUserID).Scan(&currentHashedPassword)
+	matched, err := ctl.passwords.Check(c.Request.Context(), currentUserID, requestData.Password)
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库查询失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(currentHashedPassword), []byte(requestData.Password)); err != nil {
+	if !matched {
 		utils.JSONResponse(c, http.StatusUnauthorized, "旧密码不正确", nil)
 		return
 	}
 
-	// 密码加密
-	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestData.NewPassword), bcrypt.DefaultCost)
-	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("新密码加密失败: %v", err), nil)
+	// 密码更新，新密码不能与最近使用过的密码相同
+	if !respondPasswordError(c, ctl.passwords.Set(c.Request.Context(), currentUserID, requestData.NewPassword, false)) {
 		return
 	}
 
-	// 密码更新
-	_, err = config.DB.Exec("UPDATE user SET password = ? WHERE id = ?", string(newHashedPassword), currentUserID)
+	// 注销所有登录，其他设备需要用新密码重新登录；当前设备直接换发新的令牌
+	if err := ctl.tokens.RevokeAll(c.Request.Context(), currentUserID); err != nil {
+		utils.Fail(c, fmt.Errorf("注销用户会话失败: %w", err))
+		return
+	}
+	pair, err := ctl.tokens.Issue(c.Request.Context(), currentUserID, clientOf(c))
 	if err != nil {
-		utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("数据库更新失败: %v", err), nil)
+		utils.Fail(c, fmt.Errorf("生成令牌失败: %w", err))
+		return
+	}
+
+	// 返回成功信息和新的令牌
+	utils.JSONResponse(c, http.StatusOK, "密码修改成功", pair)
+}
+
+// SetLocale 设置当前用户的语言偏好，之后的接口消息使用该语言；locale 为空时恢复按 Accept-Language 选择
+func (ctl *UserController) SetLocale(c *gin.Context) {
+	var requestData struct {
+		Locale string `json:"locale"`
+	}
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		utils.Fail(c, utils.InvalidInput(err))
+		return
+	}
+	locale := ""
+	if requestData.Locale != "" {
+		var ok bool
+		if locale, ok = i18n.Normalize(requestData.Locale); !ok {
+			utils.JSONResponse(c, http.StatusBadRequest, "不支持的语言", gin.H{"supported": i18n.Supported})
+			return
+		}
+	}
+	if err := ctl.users.UpdateLocale(c.Request.Context(), c.GetInt("userID"), locale); err != nil {
+		utils.Fail(c, fmt.Errorf("数据库更新失败: %w", err))
 		return
 	}
 
-	// 返回成功信息
-	utils.JSONResponse(c, http.StatusOK, "密码修改成功", nil)
+	// 本次响应就使用新的语言
+	if locale == "" {
+		locale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+	}
+	utils.SetLocale(c, locale)
+	utils.JSONResponse(c, http.StatusOK, "语言设置成功", gin.H{"locale": locale})
 }
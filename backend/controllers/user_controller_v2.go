@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUsers GET /api/v2/users 按查询字符串筛选用户并分页
+// 支持 username、status、verified_email（true 或 false）以及 page、page_size，需要用户管理权限
+func (ctl *UserController) ListUsers(c *gin.Context) {
+	var query struct {
+		pageQuery
+		Username      string `form:"username" json:"username"`
+		Status        string `form:"status" json:"status" validate:"omitempty,oneof=0 1 2" msg:"用户状态设置错误"`
+		VerifiedEmail *bool  `form:"verified_email" json:"verified_email"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	list, total, err := ctl.users.List(c.Request.Context(), repository.UserQuery{
+		Pagination:    query.pagination(),
+		Username:      query.Username,
+		Status:        query.Status,
+		VerifiedEmail: query.VerifiedEmail,
+	})
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询列表失败: %w", err))
+		return
+	}
+	listResponse(c, "用户列表获取成功", list, total, query.pageQuery)
+}
+
+// GetUser GET /api/v2/users/:id 获取用户信息，需要用户管理权限
+func (ctl *UserController) GetUser(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	user, err := ctl.users.GetByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.Fail(c, utils.NotFound("用户不存在"))
+		return
+	}
+	if err != nil {
+		utils.Fail(c, fmt.Errorf("数据库查询失败: %w", err))
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, "获取用户信息成功", user)
+}
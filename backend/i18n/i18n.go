@@ -0,0 +1,130 @@
+// Package i18n 接口消息的多语言支持
+//
+// 代码中的消息使用简体中文（SourceLocale）书写，其他语言的目录在 locales 目录中，构建时嵌入程序：
+//   - codes: 按错误码翻译，{name} 为参数占位符，消息没有对应的翻译时使用
+//   - messages: 按中文原文翻译，用于同一个错误码下更具体的提示和成功消息
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+)
+
+// SourceLocale 代码中的消息使用的语言
+const SourceLocale = ZhCN
+
+// Supported 支持的语言列表，Accept-Language 中没有匹配的语言时使用默认语言
+var Supported = []string{ZhCN, EnUS}
+
+//go:embed locales/*.json
+var files embed.FS
+
+// catalog 一种语言的消息目录
+type catalog struct {
+	Codes    map[string]string `json:"codes"`
+	Messages map[string]string `json:"messages"`
+}
+
+var (
+	catalogs      = map[string]*catalog{}
+	matcher       language.Matcher
+	defaultLocale = SourceLocale
+)
+
+func init() {
+	tags := make([]language.Tag, 0, len(Supported))
+	for _, locale := range Supported {
+		data, err := files.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: 读取 %s 失败: %v", locale, err))
+		}
+		var cat catalog
+		if err := json.Unmarshal(data, &cat); err != nil {
+			panic(fmt.Sprintf("i18n: 解析 %s 失败: %v", locale, err))
+		}
+		catalogs[locale] = &cat
+		tags = append(tags, language.MustParse(locale))
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// SetDefault 设置默认语言，locale 必须是支持的语言，通常为 config.Conf.I18n.DefaultLocale
+func SetDefault(locale string) {
+	if IsSupported(locale) {
+		defaultLocale = locale
+	}
+}
+
+// Default 返回默认语言
+func Default() string {
+	return defaultLocale
+}
+
+// IsSupported 判断是否为支持的语言，需要使用 Supported 中的写法
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Normalize 把语言标签（例如 en、zh-Hans、en_GB）转换为最接近的支持的语言
+// 无法识别或者没有接近的语言时返回 false
+func Normalize(tag string) (string, bool) {
+	t, err := language.Parse(strings.ReplaceAll(tag, "_", "-"))
+	if err != nil {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(t)
+	if confidence == language.No {
+		return "", false
+	}
+	return Supported[index], true
+}
+
+// Negotiate 根据 Accept-Language 请求头选择语言，没有匹配的语言时返回默认语言
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return defaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return defaultLocale
+	}
+	return Supported[index]
+}
+
+// Translate 把消息翻译为 locale 对应的语言
+// message 为代码中的中文消息，code 为错误码，params 用于替换错误码消息中的 {name} 占位符；
+// 依次使用中文原文的翻译、错误码的翻译，都没有时返回原文
+func Translate(locale, code, message string, params map[string]string) string {
+	if locale == "" {
+		locale = defaultLocale
+	}
+	cat, ok := catalogs[locale]
+	if !ok {
+		return message
+	}
+	if locale == SourceLocale && message != "" {
+		return message
+	}
+	if translated, ok := cat.Messages[message]; ok {
+		return translated
+	}
+	if translated, ok := cat.Codes[code]; ok {
+		for name, value := range params {
+			translated = strings.ReplaceAll(translated, "{"+name+"}", value)
+		}
+		return translated
+	}
+	return message
+}
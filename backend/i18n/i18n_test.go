@@ -0,0 +1,76 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{"", ZhCN},
+		{"en-US,en;q=0.9", EnUS},
+		{"en-GB", EnUS},
+		{"zh-TW,zh;q=0.9,en;q=0.8", ZhCN},
+		{"fr-FR,en;q=0.5", EnUS},
+		{"fr-FR", ZhCN},
+		{"not a language", ZhCN},
+	}
+	for _, tc := range cases {
+		if got := Negotiate(tc.header); got != tc.want {
+			t.Errorf("Negotiate(%q) = %q，期望 %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for tag, want := range map[string]string{"en": EnUS, "en_GB": EnUS, "zh-Hans": ZhCN, "zh-CN": ZhCN} {
+		if got, ok := Normalize(tag); !ok || got != want {
+			t.Errorf("Normalize(%q) = %q, %v，期望 %q", tag, got, ok, want)
+		}
+	}
+	if _, ok := Normalize("x-invalid-"); ok {
+		t.Error("无法识别的语言标签应该返回 false")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		name    string
+		locale  string
+		code    string
+		message string
+		params  map[string]string
+		want    string
+	}{
+		{"中文返回原文", ZhCN, "not_found", "用户不存在", nil, "用户不存在"},
+		{"错误码翻译", EnUS, "forbidden", "没有对应翻译的消息", nil, "Access denied"},
+		{"替换占位符", EnUS, "file_too_large", "文件过大", map[string]string{"max_mb": "5"}, "File size must not exceed 5MB"},
+		{"没有翻译时返回原文", EnUS, "unknown_code", "没有翻译的消息", nil, "没有翻译的消息"},
+		{"不支持的语言返回原文", "fr-FR", "forbidden", "没有权限访问", nil, "没有权限访问"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Translate(tc.locale, tc.code, tc.message, tc.params); got != tc.want {
+				t.Fatalf("Translate() = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCatalogsComplete 每种语言都要为所有错误码提供翻译
+func TestCatalogsComplete(t *testing.T) {
+	source := catalogs[SourceLocale]
+	for _, locale := range Supported {
+		cat := catalogs[locale]
+		for code := range source.Codes {
+			if _, ok := cat.Codes[code]; !ok {
+				t.Errorf("%s 缺少错误码 %q 的翻译", locale, code)
+			}
+		}
+		for code := range cat.Codes {
+			if _, ok := source.Codes[code]; !ok {
+				t.Errorf("%s 中的错误码 %q 在 %s 中不存在", locale, code, SourceLocale)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender 把邮件保存为 .eml 文件，用于本地开发和测试，不会真正发出邮件
+type FileSender struct {
+	from string
+	dir  string
+}
+
+// NewFileSender 创建文件发送器，dir 不存在时在第一次发送时创建
+func NewFileSender(from, dir string) *FileSender {
+	return &FileSender{from: from, dir: dir}
+}
+
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	data, err := build(s.from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix) + ".eml"
+	// 邮件中包含一次性链接，只允许当前用户读取
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0o600)
+}
+
+// LogSender 把邮件内容输出到日志，仅用于本地开发，邮件中的链接会出现在日志里
+type LogSender struct {
+	from string
+}
+
+// NewLogSender 创建日志发送器
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	if _, err := build(s.from, msg, time.Now()); err != nil {
+		return err
+	}
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package mail 负责发送系统邮件（找回密码、验证邮箱等）
+// 支持 SMTP 发送，也可以保存为文件或输出到日志，方便本地开发和测试
+package mail
+
+import (
+	"backend/config"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送接口
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender 根据 mail.driver 创建发送器，配置已在加载时校验
+func NewSender(conf config.MailConfig) (Sender, error) {
+	switch conf.Driver {
+	case "smtp":
+		return NewSMTPSender(conf.From, conf.SMTP), nil
+	case "file":
+		return NewFileSender(conf.From, conf.FileDir), nil
+	case "log":
+		return NewLogSender(conf.From), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件驱动: %s", conf.Driver)
+	}
+}
+
+// errHeaderInjection 收件人或主题中包含换行，可能被用来注入额外的邮件头
+var errHeaderInjection = errors.New("邮件头中不能包含换行符")
+
+// build 生成 RFC 5322 格式的邮件内容，主题和正文按 UTF-8 编码
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	for _, v := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, errHeaderInjection
+		}
+	}
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return nil, fmt.Errorf("收件人地址无效: %w", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// base64 每行不超过 76 个字符
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// sendTimeout 单封邮件的发送超时时间
+const sendTimeout = 30 * time.Second
+
+// ErrQueueClosed 服务正在关闭，不再接收新的邮件
+var ErrQueueClosed = errors.New("邮件队列已关闭")
+
+// Queue 在后台异步发送邮件，避免请求等待 SMTP 服务器响应，
+// 也避免通过响应时间判断邮箱是否已注册
+type Queue struct {
+	sender Sender
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewQueue 创建邮件队列
+func NewQueue(sender Sender) *Queue {
+	return &Queue{sender: sender}
+}
+
+// Enqueue 提交一封邮件，发送失败只记录日志
+func (q *Queue) Enqueue(msg Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := q.sender.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send mail %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+	return nil
+}
+
+// Close 停止接收新邮件，并等待已提交的邮件发送完成，作为服务关闭钩子使用
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
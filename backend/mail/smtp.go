@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"backend/config"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPSender 通过 SMTP 服务器发送邮件
+type SMTPSender struct {
+	from string
+	conf config.SMTPConfig
+}
+
+// NewSMTPSender 创建 SMTP 发送器
+func NewSMTPSender(from string, conf config.SMTPConfig) *SMTPSender {
+	return &SMTPSender{from: from, conf: conf}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	data, err := build(s.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	fromAddr, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return err
+	}
+	toAddr, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.conf.Host, strconv.Itoa(s.conf.Port))
+	tlsConf := &tls.Config{ServerName: s.conf.Host}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if s.conf.TLS == "tls" {
+		conn = tls.Client(conn, tlsConf)
+	}
+
+	client, err := smtp.NewClient(conn, s.conf.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	defer client.Close()
+
+	if s.conf.TLS == "starttls" {
+		if err := client.StartTLS(tlsConf); err != nil {
+			return fmt.Errorf("STARTTLS 失败: %w", err)
+		}
+	}
+	if s.conf.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.conf.Username, s.conf.Password, s.conf.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(fromAddr.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(toAddr.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
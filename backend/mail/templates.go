@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"fmt"
+	"time"
+)
+
+// PasswordResetMessage 找回密码邮件
+func PasswordResetMessage(to, username, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "重置密码",
+		Body: fmt.Sprintf("%s，你好：\n\n我们收到了重置你账号密码的请求，请在 %s 内打开下面的链接设置新密码：\n\n%s\n\n"+
+			"如果这不是你本人的操作，请忽略这封邮件，你的密码不会被修改。\n", username, formatTTL(ttl), link),
+	}
+}
+
+// VerifyEmailMessage 验证邮箱邮件
+func VerifyEmailMessage(to, username, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "验证邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请在 %s 内打开下面的链接完成邮箱验证：\n\n%s\n\n"+
+			"验证后可以通过该邮箱找回密码。如果这不是你本人的操作，请忽略这封邮件。\n", username, formatTTL(ttl), link),
+	}
+}
+
+// formatTTL 把有效期转换为便于阅读的中文描述
+func formatTTL(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d 小时", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%d 分钟", d/time.Minute)
+	default:
+		return fmt.Sprintf("%d 秒", d/time.Second)
+	}
+}
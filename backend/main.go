@@ -1,32 +1,134 @@
 package main
 
 import (
-	"backend/config"  // 引入配置包，初始化数据库连接
-	"backend/routers" // 引入路由包，设置 HTTP 路由
+	"backend/auth"       // 引入认证包，根据密码策略创建密码服务
+	"backend/config"     // 引入配置包，加载配置并初始化数据库连接
+	"backend/i18n"       // 引入多语言支持，设置接口消息的默认语言
+	"backend/mail"       // 引入邮件包，发送找回密码和验证邮箱的邮件
+	"backend/repository" // 引入数据仓库包，封装所有数据库访问
+	"backend/routers"    // 引入路由包，设置 HTTP 路由
+	"backend/scheduler"  // 引入定时任务包，按时发布和下线文章
+	"backend/server"     // 引入服务包，负责启动 HTTP/HTTPS 监听
+	"context"
+	"flag"
 	"log"
+	"os"
 )
 
 func main() {
+	// 解析命令行参数，配置文件路径也可以通过环境变量 BLOG_CONFIG 指定
+	configPath := flag.String("config", defaultConfigPath(), "配置文件路径（.yaml/.yml/.toml）")
+	flag.Parse()
+
+	// 加载配置，配置有误时列出全部问题并退出
+	if err := config.Init(resolveConfigPath(*configPath)); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	i18n.SetDefault(config.Conf.I18n.DefaultLocale)
+
+	// migrate 子命令只执行数据库迁移，不启动服务
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		runMigrate(args[1:])
+		return
+	}
+
+	// 根据配置的数据库驱动创建数据仓库
+	repos := openRepositories()
+
+	// 启动定时发布和下线文章的任务，服务关闭时在关闭数据库之前停止
+	startScheduler(repos)
+
+	// 创建邮件队列，服务关闭时先等待队列中的邮件发送完成，再关闭数据库
+	mailer := openMailer()
+
+	// 创建密码服务，常见密码列表在这里一次性加载
+	passwords, err := auth.NewPasswordService(config.Conf.Password, repos.Users, repos.PasswordHistory)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	// 配置了初始管理员且该用户不存在时创建，注册接口创建的用户都是访客
+	if err := auth.EnsureAdmin(context.Background(), config.Conf.Admin, repos.Users, passwords); err != nil {
+		log.Fatalf("Failed to create initial admin: %v", err)
+	}
+
+	// 设置 Gin 路由
+	// routers.SetupRouter 函数返回一个配置好的路由引擎
+	router := routers.SetupRouter(repos, mailer, passwords)
+
+	// 启动 HTTP 服务，配置启用 TLS 时启动 HTTPS 服务
+	// 收到 SIGINT/SIGTERM 时会等待进行中的请求完成后再退出
+	if err := server.Run(config.Conf.Server, router); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+// openRepositories 根据 database.driver 创建数据仓库
+// mysql 和 sqlite 驱动使用对应方言的 SQL 仓库，memory 驱动不连接数据库，数据只保存在内存中
+func openRepositories() *repository.Repositories {
+	driver := config.Conf.Database.Driver
+	if driver == "memory" {
+		log.Printf("Using in-memory storage, data will be lost on exit")
+		return repository.NewMemory()
+	}
+	dialect, err := repository.DialectFor(driver)
+	if err != nil {
+		log.Fatalf("Failed to open repositories: %v", err)
+	}
+
 	// 初始化数据库连接
 	// 通过 config 包的 ConnectDatabase 函数连接到数据库
 	config.ConnectDatabase()
+	// 服务关闭时最后关闭数据库连接池
+	server.OnShutdown("database", func(context.Context) error {
+		return config.DB.Close()
+	})
+	if config.Conf.Database.AutoMigrate {
+		autoMigrate()
+	}
+	return repository.NewSQL(config.DB, dialect)
+}
 
-	// 设置 Gin 路由
-	// routers.SetupRouter 函数返回一个配置好的路由引擎
-	router := routers.SetupRouter()
-
-	// 启动 HTTP 服务，监听端口 8080
-	// router.Run 会启动 Gin 的 HTTP 服务器，并开始处理请求
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start HTTP server: %v", err)
-	}
-
-	//// 指定证书和密钥文件路径
-	//certFile := "/www/ssl/micefind.com.pem" // SSL 证书文件路径
-	//keyFile := "/www/ssl/micefind.com.key"  // SSL 私钥文件路径
-	//
-	//// 启动 HTTPS 服务，监听端口 8080
-	//if err := router.RunTLS(":8080", certFile, keyFile); err != nil {
-	//	log.Fatalf("Failed to start HTTPS server: %v", err)
-	//}
+// openMailer 根据 mail.driver 创建邮件发送器，并注册关闭钩子
+func openMailer() *mail.Queue {
+	sender, err := mail.NewSender(config.Conf.Mail)
+	if err != nil {
+		log.Fatalf("Failed to create mail sender: %v", err)
+	}
+	if config.Conf.Mail.Driver == "log" {
+		log.Printf("Using log mail driver, mails will be printed to the log instead of being sent")
+	}
+	mailer := mail.NewQueue(sender)
+	server.OnShutdown("mail", mailer.Close)
+	return mailer
+}
+
+// startScheduler 根据 scheduler 配置启动文章定时任务，并注册关闭钩子
+func startScheduler(repos *repository.Repositories) {
+	if !config.Conf.Scheduler.Enabled {
+		return
+	}
+	s := scheduler.NewArticleScheduler(repos.Articles, config.Conf.Scheduler)
+	s.Start()
+	server.OnShutdown("scheduler", s.Close)
+}
+
+// defaultConfigPath 返回默认的配置文件路径
+func defaultConfigPath() string {
+	if path := os.Getenv("BLOG_CONFIG"); path != "" {
+		return path
+	}
+	return "config.yaml"
+}
+
+// resolveConfigPath 默认配置文件不存在时返回空字符串，此时只使用默认值和环境变量
+// 显式指定的配置文件不存在时仍然交给 config.Init 报错
+func resolveConfigPath(path string) string {
+	if path != "config.yaml" {
+		return path
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ""
+	}
+	return path
 }
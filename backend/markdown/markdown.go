@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading 目录中的一个标题
+type Heading struct {
+	Level int    `json:"level"` // 1-6，对应 h1-h6
+	Text  string `json:"text"`
+	ID    string `json:"id"` // HTML 中标题的 id，可以作为锚点跳转
+}
+
+// Document 渲染结果
+type Document struct {
+	HTML string
+	TOC  []Heading
+}
+
+// md 支持 GFM（表格、删除线、任务列表、自动链接）和脚注，标题自动生成 id
+// 正文中的 HTML 原样输出，最后统一由 policy 过滤
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// policy 在用户内容的默认规则上，保留代码高亮、标题锚点、脚注和任务列表需要的属性
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 代码块的语言，前端根据 language-xxx 高亮
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w.+#-]+$`)).OnElements("code")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_:-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(footnote-ref|footnote-backref|footnotes)$`)).OnElements("a", "div")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|backlink|endnotes)$`)).OnElements("a", "div")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render 把 Markdown 渲染为过滤后的 HTML，并按标题顺序提取目录
+func Render(source string) (*Document, error) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	toc := []Heading{}
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		toc = append(toc, Heading{Level: heading.Level, Text: nodeText(heading, src), ID: string(idBytes)})
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Document{HTML: policy.Sanitize(buf.String()), TOC: toc}, nil
+}
+
+// nodeText 拼接节点中的纯文本，忽略强调、链接等格式
+func nodeText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := child.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(src))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// headingIDs 生成标题的 id：保留各种语言的文字和数字，空白和连字符合并为一个 -，重复时追加序号
+// goldmark 默认只保留 ASCII 字母，中文标题会全部变成 heading
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: map[string]bool{}}
+}
+
+func (s *headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(string(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			dash = false
+		case (r == '-' || unicode.IsSpace(r)) && b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+	unique := id
+	for i := 1; s.used[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	s.used[unique] = true
+	return []byte(unique)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
@@ -0,0 +1,86 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderSanitizes(t *testing.T) {
+	cases := []struct {
+		name      string
+		source    string
+		forbidden []string // 渲染结果中不能出现的内容
+		keep      string   // 渲染结果中应该保留的内容
+	}{
+		{"script 标签", "正文\n\n<script>alert(1)</script>", []string{"<script", "alert(1)"}, "<p>正文</p>"},
+		{"行内 script", "文字 <script>alert(1)</script> 文字", []string{"<script"}, "文字"},
+		{"javascript 链接", "[点击](javascript:alert(1))", []string{"javascript:"}, "点击"},
+		{"HTML 中的 javascript 链接", `<a href="javascript:alert(1)">点击</a>`, []string{"javascript:"}, "点击"},
+		{"大小写混合的 javascript 链接", `<a href="JaVaScRiPt:alert(1)">点击</a>`, []string{"alert(1)"}, "点击"},
+		{"事件属性", `<img src="https://example.com/a.png" onerror="alert(1)">`, []string{"onerror", "alert(1)"}, `src="https://example.com/a.png"`},
+		{"其他 on 属性", `<p onclick="alert(1)" onmouseover="alert(2)">段落</p>`, []string{"onclick", "onmouseover"}, "段落"},
+		{"iframe", `<iframe src="https://evil.example.com"></iframe>`, []string{"<iframe"}, ""},
+		{"style 属性", `<p style="background:url(javascript:alert(1))">段落</p>`, []string{"style=", "javascript:"}, "段落"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := Render(tc.source)
+			if err != nil {
+				t.Fatalf("Render() 出错: %v", err)
+			}
+			lower := strings.ToLower(doc.HTML)
+			for _, f := range tc.forbidden {
+				if strings.Contains(lower, strings.ToLower(f)) {
+					t.Errorf("渲染结果中包含 %q: %s", f, doc.HTML)
+				}
+			}
+			if tc.keep != "" && !strings.Contains(doc.HTML, tc.keep) {
+				t.Errorf("渲染结果中缺少 %q: %s", tc.keep, doc.HTML)
+			}
+		})
+	}
+}
+
+func TestRenderKeepsFormatting(t *testing.T) {
+	source := "```go\nfmt.Println(1)\n```\n\n- [x] 完成\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n[链接](https://example.com)\n\n脚注[^1]\n\n[^1]: 说明"
+	doc, err := Render(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<code class="language-go">`,
+		`<input checked="" disabled="" type="checkbox"`,
+		"<table>",
+		`<a href="https://example.com" rel="nofollow">链接</a>`,
+		`class="footnote-ref"`,
+	} {
+		if !strings.Contains(doc.HTML, want) {
+			t.Errorf("渲染结果中缺少 %q: %s", want, doc.HTML)
+		}
+	}
+}
+
+func TestRenderTOC(t *testing.T) {
+	source := "# 介绍\n\n## Getting *Started*\n\n## Getting Started\n\n### 安装 Go 1.22\n\n## !!!"
+	doc, err := Render(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Heading{
+		{Level: 1, Text: "介绍", ID: "介绍"},
+		{Level: 2, Text: "Getting Started", ID: "getting-started"},
+		{Level: 2, Text: "Getting Started", ID: "getting-started-1"},
+		{Level: 3, Text: "安装 Go 1.22", ID: "安装-go-122"},
+		{Level: 2, Text: "!!!", ID: "heading"},
+	}
+	if !reflect.DeepEqual(doc.TOC, want) {
+		t.Fatalf("TOC = %+v\n期望 %+v", doc.TOC, want)
+	}
+	// HTML 中标题的 id 与目录一致，可以作为锚点
+	for _, h := range want {
+		if !strings.Contains(doc.HTML, `id="`+h.ID+`"`) {
+			t.Errorf("渲染结果中缺少 id=%q: %s", h.ID, doc.HTML)
+		}
+	}
+}
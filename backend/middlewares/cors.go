@@ -23,13 +23,13 @@ func CORSMiddleware() gin.HandlerFunc {
 		}
 
 		// 设置允许的 HTTP 方法（如 GET, POST 等），支持跨域的操作类型
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 
 		// 设置允许的请求头类型（如 Content-Type, Authorization 等），用于前端发送的自定义请求头
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID")
 
-		// 设置允许前端访问的响应头字段，例如文件长度和下载文件名
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Disposition")
+		// 设置允许前端访问的响应头字段，例如文件长度、下载文件名、请求 ID 和新建资源的地址
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Disposition, X-Request-ID, Location")
 
 		// 设置是否允许跨域请求携带凭证（如 cookies），这通常在需要认证的情况下使用
 		c.Header("Access-Control-Allow-Credentials", "true")
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"backend/repository"
+	"backend/utils"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorMiddleware 把控制器通过 utils.Fail 记录的错误统一转换为错误响应
+// *utils.AppError 按其中的状态码和错误码返回；数据不存在的错误返回 404；
+// 其他错误都是内部错误，客户端只能看到通用提示和请求 ID，具体原因记录在日志中
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		appErr := toAppError(err)
+		if appErr.Status >= 500 {
+			log.Printf("[%s] %s %s: %v", c.GetString(utils.RequestIDKey), c.Request.Method, c.Request.URL.Path, err)
+		}
+		utils.ErrorResponse(c, appErr)
+	}
+}
+
+// RecoveryHandler 处理请求时发生 panic 后返回内部错误，gin.CustomRecovery 已经记录了堆栈
+func RecoveryHandler(c *gin.Context, recovered any) {
+	log.Printf("[%s] %s %s: panic: %v", c.GetString(utils.RequestIDKey), c.Request.Method, c.Request.URL.Path, recovered)
+	utils.ErrorResponse(c, utils.Internal(nil))
+}
+
+// toAppError 把任意错误转换为返回给客户端的错误
+func toAppError(err error) *utils.AppError {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound("数据不存在")
+	default:
+		return utils.Internal(err)
+	}
+}
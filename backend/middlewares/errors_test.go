@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"backend/repository"
+	"backend/utils"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	out := log.Writer()
+	log.SetOutput(io.Discard) // 内部错误会写日志
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"业务错误", utils.NewError(http.StatusConflict, "title_taken", "标题已存在"), http.StatusConflict, "title_taken", "标题已存在"},
+		{"按状态码使用通用错误码", utils.NewError(http.StatusForbidden, "", "没有权限访问"), http.StatusForbidden, utils.CodeForbidden, "没有权限访问"},
+		{"数据不存在", repository.ErrNotFound, http.StatusNotFound, utils.CodeNotFound, "数据不存在"},
+		{"内部错误不返回原因", errors.New("dial tcp 10.0.0.1:3306: connection refused"), http.StatusInternalServerError, utils.CodeInternal, "服务器内部错误，请稍后重试"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestIDMiddleware(), ErrorMiddleware())
+			router.GET("/", func(c *gin.Context) { utils.Fail(c, tc.err) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var body struct {
+				Status    int    `json:"status"`
+				Code      string `json:"code"`
+				Message   string `json:"message"`
+				RequestID string `json:"request_id"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tc.status || body.Status != tc.status {
+				t.Errorf("状态码 = %d（响应中为 %d），期望 %d", w.Code, body.Status, tc.status)
+			}
+			if body.Code != tc.code || body.Message != tc.message {
+				t.Errorf("响应 = %s %q，期望 %s %q", body.Code, body.Message, tc.code, tc.message)
+			}
+			if body.RequestID == "" || body.RequestID != w.Header().Get(RequestIDHeader) {
+				t.Errorf("request_id = %q，响应头为 %q", body.RequestID, w.Header().Get(RequestIDHeader))
+			}
+			if strings.Contains(w.Body.String(), "connection refused") {
+				t.Error("响应中包含了内部错误的原因")
+			}
+		})
+	}
+}
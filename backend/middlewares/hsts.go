@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HSTSMiddleware 为 HTTPS 请求添加 Strict-Transport-Security 响应头
+// 浏览器收到后在 maxAge 时间内只会通过 HTTPS 访问本站；通过 HTTP 到达的请求不添加该头
+func HSTSMiddleware(maxAge time.Duration, includeSubdomains bool) gin.HandlerFunc {
+	value := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && maxAge > 0 {
+			c.Header("Strict-Transport-Security", value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHSTSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name              string
+		maxAge            time.Duration
+		includeSubdomains bool
+		https             bool
+		want              string
+	}{
+		{"HTTPS 请求", 24 * time.Hour, false, true, "max-age=86400"},
+		{"包含子域名", 24 * time.Hour, true, true, "max-age=86400; includeSubDomains"},
+		{"HTTP 请求不添加", 24 * time.Hour, false, false, ""},
+		{"max_age 为 0 不添加", 0, false, true, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(HSTSMiddleware(tc.maxAge, tc.includeSubdomains))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.https {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Strict-Transport-Security"); got != tc.want {
+				t.Errorf("Strict-Transport-Security = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,114 +1,99 @@
 package middlewares
 
 import (
-	"backend/config"               // 配置包，包含数据库和应用配置
-	"backend/models"               // 定义应用中的模型
-	"backend/utils"                // 实用函数包，包含响应格式化等
-	"errors"                       // 标准库中的错误处理包
-	"fmt"                          // 标准库中的格式化输出包
-	"github.com/gin-gonic/gin"     // Gin 框架，用于构建 Web API
-	"github.com/golang-jwt/jwt/v5" // 用于处理 JWT 令牌
-	"net/http"                     // 标准库中的 HTTP 处理包
-	"strings"                      // 标准库中的字符串处理包
-	"time"                         // 标准库中的时间处理包
+	"backend/auth"             // 令牌服务，负责解析令牌和检查令牌是否被注销
+	"backend/models"           // 模型，包含账号状态的定义
+	"backend/repository"       // 数据仓库，用于查询用户信息
+	"backend/utils"            // 实用函数包，包含响应格式化等
+	"fmt"                      // 标准库中的格式化输出包
+	"github.com/gin-gonic/gin" // Gin 框架，用于构建 Web API
+	"net/http"                 // 标准库中的 HTTP 处理包
+	"strings"                  // 标准库中的字符串处理包
 )
 
-// getUserByID 根据用户ID从数据库获取用户信息
-// c: Gin 上下文对象，保存请求相关的所有信息
-// id: 用户的唯一标识 ID
-// 返回值: 用户信息 (models.User) 和可能的错误
-func getUserByID(c *gin.Context, id int) (*models.User, error) {
-	var user struct { // 临时结构体，用于存储数据库查询结果
-		Username string `json:"username"` // 用户名字段
-		Role     string `json:"role"`     // 角色字段
+// authenticate 解析请求头中的 JWT 令牌并查询对应用户
+// 验证通过时在上下文中设置 userID、role 和 sessionID，账号被限制时还会设置 accountStatus；
+// 失败时返回失败原因且不修改上下文
+// allowPendingPasswordChange 为 false 时，需要修改密码的用户会被拒绝
+func authenticate(c *gin.Context, users repository.UserRepository, tokens *auth.TokenService, allowPendingPasswordChange bool) *utils.AppError {
+	// 从请求头中获取 Authorization 字段，该字段通常包含 "Bearer <token>"
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" { // 如果 Authorization 头不存在，直接返回 401 错误
+		return utils.NewError(http.StatusUnauthorized, "", "缺少令牌")
 	}
 
-	// SQL 查询语句，按用户ID获取用户名和角色
-	sql := `SELECT username, role FROM user WHERE id = ?`
+	// 去掉 "Bearer " 前缀，获取真正的令牌字符串
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	// 执行查询并将结果赋值给 user 结构体
-	err := config.DB.QueryRow(sql, id).Scan(&user.Username, &user.Role)
-
-	// 返回查询到的用户信息以及可能的错误
-	return &models.User{Username: user.Username, Role: user.Role}, err
-}
+	// 校验令牌签名和过期时间（exp 字段），并读取其中的用户ID
+	claims, err := auth.ParseAccessToken(tokenString)
+	if err != nil {
+		return utils.NewError(http.StatusUnauthorized, "", err.Error())
+	}
 
-// JWTAuthMiddleware 用于处理 JWT 验证的中间件函数
-// 返回值: Gin 中间件函数
-func JWTAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 从请求头中获取 Authorization 字段，该字段通常包含 "Bearer <token>"
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" { // 如果 Authorization 头不存在，直接返回 401 错误
-			utils.JSONResponse(c, http.StatusUnauthorized, "缺少令牌", nil)
-			c.Abort() // 终止请求处理链
-			return
-		}
+	// 从数据库查询用户信息
+	user, err := users.GetByID(c.Request.Context(), claims.UserID)
+	if err != nil { // 查询用户信息出错
+		return utils.Internal(fmt.Errorf("获取用户信息失败: %w", err))
+	}
+	// 用户设置了语言偏好时，之后的响应消息（包括下面的错误）使用该语言
+	if user.Locale != "" {
+		utils.SetLocale(c, user.Locale)
+	}
 
-		// 去掉 "Bearer " 前缀，获取真正的令牌字符串
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	// 修改密码、重置密码或注销账号后令牌版本会递增，之前签发的令牌不再有效
+	if claims.Version != user.TokenVersion {
+		return utils.NewError(http.StatusUnauthorized, "", "令牌已失效，请重新登录")
+	}
+	// 被注销的会话中尚未过期的令牌记录在黑名单中
+	denied, err := tokens.IsDenied(c.Request.Context(), claims)
+	if err != nil {
+		return utils.Internal(fmt.Errorf("校验令牌失败: %w", err))
+	}
+	if denied {
+		return utils.NewError(http.StatusUnauthorized, "", "令牌已失效，请重新登录")
+	}
 
-		// 使用配置中的 JWT 密钥解析令牌
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// 将密钥字符串转为 byte 切片并返回
-			return []byte(config.JwtSecret), nil
-		})
+	// 已注销的账号不能访问任何需要登录的接口；被限制的账号由 RequirePermission 和 RequireActiveAccount 拦截写操作
+	statusErr := user.StatusError()
+	if statusErr != nil && statusErr.Code == models.CodeAccountCancelled {
+		return utils.NewError(http.StatusForbidden, statusErr.Code, statusErr.Message).WithData(statusErr)
+	}
 
-		// 解析 JWT 令牌时出现错误处理
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) { // 判断是否为令牌过期错误
-				utils.JSONResponse(c, http.StatusUnauthorized, "令牌已过期", nil)
-			} else {
-				utils.JSONResponse(c, http.StatusUnauthorized, "无效的令牌", nil)
-			}
-			c.Abort() // 终止请求处理链
-			return
-		}
+	// 管理员重置密码后，用户必须先修改临时密码才能访问其他接口
+	if user.MustChangePassword && !allowPendingPasswordChange {
+		return utils.NewError(http.StatusForbidden, models.CodePasswordChangeRequired, "请先修改密码").WithData(gin.H{"code": models.CodePasswordChangeRequired})
+	}
 
-		// 验证令牌是否有效以及提取声明（Claims）
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// 检查令牌的过期时间（exp 字段）
-			if exp, ok := claims["exp"].(float64); ok {
-				// 将 exp 转为时间戳并判断是否已过期
-				if time.Unix(int64(exp), 0).Before(time.Now()) {
-					utils.JSONResponse(c, http.StatusUnauthorized, "令牌已过期", nil)
-					c.Abort()
-					return
-				}
-			}
+	if statusErr != nil {
+		c.Set("accountStatus", statusErr)
+	}
 
-			// 获取并验证用户ID（userID 字段）
-			if id, ok := claims["userID"].(float64); ok {
-				// 将浮点数形式的 ID 转为整型
-				userID := int(id)
-				// 在上下文中设置 userID，供后续处理使用
-				c.Set("userID", userID)
+	// 在上下文中设置 userID、用户角色和会话，供后续处理和 RequirePermission 使用
+	c.Set("userID", claims.UserID)
+	c.Set("role", user.Role)
+	c.Set("sessionID", claims.SessionID)
+	return nil
+}
 
-				// 从数据库查询用户信息
-				user, err := getUserByID(c, userID)
-				if err != nil { // 查询用户信息出错
-					utils.JSONResponse(c, http.StatusInternalServerError, fmt.Sprintf("获取用户信息失败: %v", err), nil)
-					c.Abort()
-					return
-				}
+// JWTAuthMiddleware 用于处理 JWT 验证的中间件函数
+// users: 用户数据仓库，用于查询令牌对应的用户
+// tokens: 令牌服务，用于检查令牌是否已被注销
+// 返回值: Gin 中间件函数
+func JWTAuthMiddleware(users repository.UserRepository, tokens *auth.TokenService) gin.HandlerFunc {
+	return jwtAuth(users, tokens, false)
+}
 
-				// 检查用户角色，假设角色为 "1" 表示权限受限
-				if user.Role == "1" {
-					utils.JSONResponse(c, http.StatusForbidden, "没有权限访问", nil)
-					c.Abort()
-					return
-				}
+// PasswordChangeAuthMiddleware 与 JWTAuthMiddleware 相同，但允许需要修改密码的用户通过
+// 只用于修改密码等完成密码修改所必需的接口
+func PasswordChangeAuthMiddleware(users repository.UserRepository, tokens *auth.TokenService) gin.HandlerFunc {
+	return jwtAuth(users, tokens, true)
+}
 
-			} else {
-				// 无效的用户ID
-				utils.JSONResponse(c, http.StatusUnauthorized, "无效的用户ID", nil)
-				c.Abort()
-				return
-			}
-		} else {
-			// 无效的令牌
-			utils.JSONResponse(c, http.StatusUnauthorized, "无效的令牌", nil)
-			c.Abort()
+func jwtAuth(users repository.UserRepository, tokens *auth.TokenService, allowPendingPasswordChange bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authErr := authenticate(c, users, tokens, allowPendingPasswordChange); authErr != nil {
+			utils.Fail(c, authErr) // 终止请求处理链，由 ErrorMiddleware 返回错误响应
 			return
 		}
 
@@ -116,3 +101,14 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalJWTAuthMiddleware 用于公开接口的 JWT 验证中间件
+// 携带有效令牌时与 JWTAuthMiddleware 一样设置 userID 和 role，令牌缺失或无效时按未登录用户继续处理
+func OptionalJWTAuthMiddleware(users repository.UserRepository, tokens *auth.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c, users, tokens, false)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"backend/i18n"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 根据 Accept-Language 请求头选择响应消息的语言
+// 登录用户设置了语言偏好时，JWT 中间件会用语言偏好覆盖这里的选择
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		utils.SetLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
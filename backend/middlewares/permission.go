@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"backend/models"
+	"backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 检查当前用户的角色是否拥有指定权限
+// 需要放在 JWTAuthMiddleware 之后使用，由 JWTAuthMiddleware 在上下文中设置 role
+// 需要权限的接口都是写操作，被限制的账号无论角色如何都会被拒绝
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectRestricted(c) {
+			return
+		}
+		if !models.HasPermission(c.GetString("role"), permission) {
+			utils.JSONResponse(c, http.StatusForbidden, "没有权限访问", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireActiveAccount 拒绝被限制的账号，用于不需要特定权限、但会修改账号数据的接口，例如两步验证和语言偏好
+// 需要放在 JWTAuthMiddleware 或 PasswordChangeAuthMiddleware 之后使用；
+// 注销会话和修改密码只会收紧账号的访问，被限制的账号也可以使用，这些接口不使用该中间件
+func RequireActiveAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectRestricted(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// rejectRestricted 账号被限制时返回 403 并中止请求，返回是否已拒绝
+func rejectRestricted(c *gin.Context) bool {
+	statusErr, ok := c.Get("accountStatus")
+	if !ok {
+		return false
+	}
+	utils.JSONResponse(c, http.StatusForbidden, statusErr.(*models.AccountStatusError).Message, statusErr)
+	c.Abort()
+	return true
+}
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware 为每个请求分配请求 ID，写入响应头并保存在上下文中，错误响应和错误日志都会带上它
+// 反向代理已经设置了合法的 X-Request-ID 时沿用该 ID，便于串联代理和服务的日志
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(utils.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID 只接受不超过 64 位的字母、数字、- 和 _，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID 生成 16 字节的随机请求 ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// LogFormatter 请求日志格式，在 Gin 默认格式的基础上加上请求 ID
+func LogFormatter(param gin.LogFormatterParams) string {
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v | %s | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.Keys[utils.RequestIDKey],
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"backend/config"
+	"backend/migrations"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// migrateUsage migrate 子命令的使用说明
+const migrateUsage = `用法: backend [-config 配置文件] migrate <命令>
+
+命令:
+  up          执行所有未执行的迁移
+  down [n]    回滚最近的 n 个迁移（默认 1 个）
+  status      查看所有迁移的执行状态`
+
+// runMigrate 执行 migrate 子命令，args 为 migrate 之后的参数
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+	if config.Conf.Database.Driver == "memory" {
+		log.Fatal("memory 驱动不需要执行迁移")
+	}
+
+	config.ConnectDatabase()
+	defer config.DB.Close()
+
+	migrator, err := migrations.New(config.DB, config.Conf.Database.Driver)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx)
+		for _, m := range done {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("回滚数量必须是正整数: %s", args[1])
+			}
+		}
+		done, err := migrator.Down(ctx, steps)
+		for _, m := range done {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// autoMigrate 在启动时执行未执行的迁移，由配置项 database.auto_migrate 控制
+func autoMigrate() {
+	migrator, err := migrations.New(config.DB, config.Conf.Database.Driver)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	done, err := migrator.Up(context.Background())
+	for _, m := range done {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Auto migration failed: %v", err)
+	}
+}
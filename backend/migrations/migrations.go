@@ -0,0 +1,286 @@
+// Package migrations 管理数据库表结构的版本
+//
+// 迁移文件按数据库方言存放在 mysql/ 和 sqlite/ 目录下，编译时嵌入到程序中，
+// 文件名格式为 <版本号>_<名称>.up.sql 和 <版本号>_<名称>.down.sql。
+// 每个文件可以包含多条语句，语句以行尾的分号结束；整行的 -- 注释会被忽略。
+// 已执行的版本记录在 schema_migrations 表中。
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// lockName MySQL 上用于防止多个实例同时执行迁移的命名锁
+const lockName = "blog_schema_migrations"
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt string
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load 读取指定方言（mysql 或 sqlite）的全部迁移，按版本号升序排列
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("没有 %s 方言的迁移文件: %w", dialect, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := files.ReadFile(path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("版本 %d 存在多个不同名称的迁移: %s, %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("版本 %d_%s 缺少 up 迁移", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator 在数据库上执行迁移
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+}
+
+// New 创建迁移执行器，dialect 为 mysql 或 sqlite
+func New(db *sql.DB, dialect string) (*Migrator, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Up 按顺序执行所有未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, conn, mig, mig.Up, true); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("版本 %d_%s 没有 down 迁移，无法回滚", mig.Version, mig.Name)
+			}
+			if err := m.run(ctx, conn, mig, mig.Down, false); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status 返回所有迁移及其执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses = append(statuses, Status{Migration: mig, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+// withLock 在同一个连接上加锁并执行 fn，避免多个实例同时启动时重复执行迁移
+// MySQL 使用 GET_LOCK 命名锁；SQLite 是单文件数据库，由事务本身保证写入串行
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect == "mysql" {
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", lockName).Scan(&got); err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if !got.Valid || got.Int64 != 1 {
+			return fmt.Errorf("获取迁移锁超时，可能有其他实例正在执行迁移")
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	}
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureTable 创建 schema_migrations 表
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version    BIGINT       NOT NULL PRIMARY KEY,
+  name       VARCHAR(255) NOT NULL,
+  applied_at VARCHAR(32)  NOT NULL
+)`)
+	return err
+}
+
+// applied 返回已执行的版本及执行时间
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]string{}
+	for rows.Next() {
+		var (
+			version int
+			at      string
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// run 在事务中执行一个迁移文件并更新 schema_migrations
+// 注意 MySQL 的 DDL 语句会隐式提交，失败时已执行的 DDL 不会回滚，需要手动修复后重试
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mig Migration, script string, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("执行迁移 %d_%s 失败: %w\n语句: %s", mig.Version, mig.Name, err, stmt)
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			mig.Version, mig.Name, time.Now().Format("2006-01-02 15:04:05"))
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitStatements 将迁移文件拆分为单条语句
+// MySQL 驱动默认不允许一次执行多条语句，因此需要逐条执行
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,163 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "blog.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tables 返回数据库中除 SQLite 内部表以外的所有表名
+func tables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"mysql", "sqlite"} {
+		t.Run(dialect, func(t *testing.T) {
+			migrations, err := Load(dialect)
+			if err != nil {
+				t.Fatalf("Load() 出错: %v", err)
+			}
+			for i, m := range migrations {
+				if m.Version != i+1 {
+					t.Fatalf("第 %d 个迁移的版本号为 %d，期望从 1 开始连续编号", i, m.Version)
+				}
+				if m.Down == "" {
+					t.Errorf("迁移 %d_%s 缺少 down 文件", m.Version, m.Name)
+				}
+			}
+		})
+	}
+
+	// 两种方言的迁移版本必须一一对应
+	mysql, _ := Load("mysql")
+	sqlite, _ := Load("sqlite")
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("mysql 有 %d 个迁移，sqlite 有 %d 个", len(mysql), len(sqlite))
+	}
+	for i := range mysql {
+		if mysql[i].Name != sqlite[i].Name {
+			t.Errorf("版本 %d 的名称不一致: %s, %s", mysql[i].Version, mysql[i].Name, sqlite[i].Name)
+		}
+	}
+
+	if _, err := Load("postgres"); err == nil {
+		t.Error("Load(postgres) 应该返回错误")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 创建表
+CREATE TABLE a (
+  id INT -- 行尾注释保留
+);
+
+INSERT INTO a VALUES (1);
+INSERT INTO a VALUES (2)`
+	want := []string{
+		"CREATE TABLE a (\n  id INT -- 行尾注释保留\n)",
+		"INSERT INTO a VALUES (1)",
+		"INSERT INTO a VALUES (2)",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitStatements() = %q，期望 %q", got, want)
+	}
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := New(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := len(m.migrations)
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() 出错: %v", err)
+	}
+	if len(done) != total {
+		t.Fatalf("Up() 执行了 %d 个迁移，期望 %d", len(done), total)
+	}
+	full := tables(t, db)
+
+	// 再次执行不会重复迁移
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("重复执行 Up() = %d 个, %v，期望 0 个", len(done), err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() 出错: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt == "" {
+			t.Errorf("迁移 %d_%s 应为已执行", s.Version, s.Name)
+		}
+	}
+
+	// 回滚最近一个迁移后状态随之变化，重新执行可以恢复
+	done, err = m.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down(1) 出错: %v", err)
+	}
+	if len(done) != 1 || done[0].Version != total {
+		t.Fatalf("Down(1) 回滚了 %v，期望版本 %d", done, total)
+	}
+	statuses, _ = m.Status(ctx)
+	if statuses[total-1].Applied {
+		t.Errorf("回滚后版本 %d 仍为已执行", total)
+	}
+	if done, err := m.Up(ctx); err != nil || len(done) != 1 {
+		t.Fatalf("回滚后重新执行 Up() = %d 个, %v，期望 1 个", len(done), err)
+	}
+	if got := tables(t, db); !reflect.DeepEqual(got, full) {
+		t.Fatalf("重新执行后的表 = %v，期望 %v", got, full)
+	}
+
+	// 全部回滚后只剩下 schema_migrations 表
+	done, err = m.Down(ctx, total)
+	if err != nil {
+		t.Fatalf("Down(%d) 出错: %v", total, err)
+	}
+	if len(done) != total {
+		t.Fatalf("Down(%d) 回滚了 %d 个迁移", total, len(done))
+	}
+	if got := tables(t, db); !reflect.DeepEqual(got, []string{"schema_migrations"}) {
+		t.Fatalf("全部回滚后剩余的表 = %v，期望只有 schema_migrations", got)
+	}
+
+	// 全部回滚后可以从头执行
+	if done, err := m.Up(ctx); err != nil || len(done) != total {
+		t.Fatalf("从头执行 Up() = %d 个, %v，期望 %d 个", len(done), err, total)
+	}
+}
@@ -0,0 +1,40 @@
+-- 初始表结构，与 blog_db.sql 一致
+-- 使用 IF NOT EXISTS，已经用 blog_db.sql 建好表的数据库执行后只会记录版本
+
+CREATE TABLE IF NOT EXISTS `article` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `title` varchar(255) NOT NULL COMMENT '标题',
+  `cover_image` varchar(255) NULL DEFAULT NULL COMMENT '封面',
+  `intro` varchar(255) NULL DEFAULT NULL COMMENT '简介',
+  `keywords` varchar(255) NULL DEFAULT NULL COMMENT '关键词',
+  `content` longtext NULL COMMENT '内容',
+  `views` int NOT NULL COMMENT '阅读量',
+  `creator_id` int NOT NULL COMMENT '创建人id',
+  `create_time` varchar(255) NOT NULL COMMENT '创建时间',
+  `status` varchar(255) NOT NULL COMMENT '状态0草稿1提交2发布',
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE IF NOT EXISTS `project` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `project_name` varchar(255) NOT NULL,
+  `description` varchar(255) NULL DEFAULT NULL,
+  `logo` varchar(255) NULL DEFAULT NULL,
+  `url` varchar(255) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `phone_number` varchar(255) NULL DEFAULT NULL,
+  `email` varchar(255) NULL DEFAULT NULL,
+  `real_name` varchar(255) NULL DEFAULT NULL,
+  `register_time` varchar(255) NULL DEFAULT NULL,
+  `avatar` varchar(255) NULL DEFAULT NULL,
+  `creator_id` int NULL DEFAULT NULL COMMENT '创建人id',
+  `status` varchar(255) NOT NULL COMMENT '状态0正常1限制2注销',
+  `role` varchar(255) NOT NULL COMMENT '角色0管理员1游客',
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
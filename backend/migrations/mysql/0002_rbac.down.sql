@@ -0,0 +1,4 @@
+-- 编辑和作者角色在旧版本中没有对应的值，回滚为游客
+UPDATE `user` SET role = '0' WHERE role = 'admin';
+UPDATE `user` SET role = '1' WHERE role IN ('editor', 'author', 'viewer');
+ALTER TABLE `user` MODIFY `role` varchar(255) NOT NULL COMMENT '角色0管理员1游客';
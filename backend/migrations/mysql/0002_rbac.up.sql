@@ -0,0 +1,4 @@
+-- 角色由 0（管理员）/ 1（游客）改为角色名称
+UPDATE `user` SET role = 'admin' WHERE role = '0';
+UPDATE `user` SET role = 'viewer' WHERE role = '1';
+ALTER TABLE `user` MODIFY `role` varchar(255) NOT NULL COMMENT '角色admin管理员editor编辑author作者viewer访客';
@@ -0,0 +1,15 @@
+CREATE TABLE `refresh_token` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '用户id',
+  `family_id` varchar(64) NOT NULL COMMENT '令牌家族，同一次登录轮换出的令牌共用',
+  `token_hash` varchar(64) NOT NULL COMMENT '令牌的 SHA-256 哈希',
+  `user_agent` varchar(255) NOT NULL DEFAULT '' COMMENT '登录设备',
+  `ip` varchar(64) NOT NULL DEFAULT '' COMMENT '登录 IP',
+  `created_at` varchar(32) NOT NULL,
+  `expires_at` varchar(32) NOT NULL,
+  `revoked_at` varchar(32) NULL DEFAULT NULL COMMENT '撤销时间，为空表示有效',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_family_id` (`family_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
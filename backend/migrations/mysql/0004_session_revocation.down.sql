@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `token_denylist`;
+ALTER TABLE `refresh_token` DROP COLUMN `access_jti`;
+ALTER TABLE `user` DROP COLUMN `token_version`;
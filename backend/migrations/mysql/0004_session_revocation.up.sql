@@ -0,0 +1,8 @@
+ALTER TABLE `user` ADD COLUMN `token_version` int NOT NULL DEFAULT 0 COMMENT '令牌版本，递增后之前签发的访问令牌全部失效';
+ALTER TABLE `refresh_token` ADD COLUMN `access_jti` varchar(64) NOT NULL DEFAULT '' COMMENT '同时签发的访问令牌 jti';
+CREATE TABLE `token_denylist` (
+  `jti` varchar(64) NOT NULL COMMENT '被撤销的访问令牌 jti',
+  `expires_at` varchar(32) NOT NULL COMMENT '访问令牌的过期时间，过期后记录可以删除',
+  PRIMARY KEY (`jti`) USING BTREE,
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
@@ -0,0 +1,2 @@
+ALTER TABLE `user` ADD COLUMN `status_reason` varchar(255) NOT NULL DEFAULT '' COMMENT '限制或注销的原因';
+ALTER TABLE `user` ADD COLUMN `restricted_until` varchar(32) NOT NULL DEFAULT '' COMMENT '限制的解除时间，为空表示长期限制';
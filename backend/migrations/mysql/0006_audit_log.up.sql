@@ -0,0 +1,12 @@
+CREATE TABLE `audit_log` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `action` varchar(64) NOT NULL COMMENT '操作类型',
+  `actor_id` int NOT NULL DEFAULT 0 COMMENT '操作人id，系统自动触发时为 0',
+  `target` varchar(255) NOT NULL DEFAULT '' COMMENT '操作对象',
+  `ip` varchar(64) NOT NULL DEFAULT '',
+  `detail` varchar(1024) NOT NULL DEFAULT '',
+  `created_at` varchar(32) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  KEY `idx_action` (`action`),
+  KEY `idx_target` (`target`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `must_change_password` tinyint(1) NOT NULL DEFAULT 0 COMMENT '下次登录后必须修改密码，管理员重置密码后设置';
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `verified_email` tinyint(1) NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证，修改邮箱后重置为 0';
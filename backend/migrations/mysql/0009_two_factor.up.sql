@@ -0,0 +1,17 @@
+CREATE TABLE `user_two_factor` (
+  `user_id` int NOT NULL COMMENT '用户id',
+  `secret` varchar(64) NOT NULL COMMENT 'Base32 编码的 TOTP 密钥',
+  `enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '验证过一次验证码后开启',
+  `last_step` bigint NOT NULL DEFAULT 0 COMMENT '最后一次使用的时间步，防止验证码重放',
+  `enabled_at` varchar(32) NOT NULL DEFAULT '',
+  PRIMARY KEY (`user_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE `user_recovery_code` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '用户id',
+  `code_hash` varchar(64) NOT NULL COMMENT '恢复码的 SHA-256 哈希',
+  `used_at` varchar(32) NULL DEFAULT NULL COMMENT '使用时间，为空表示未使用',
+  PRIMARY KEY (`id`) USING BTREE,
+  KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
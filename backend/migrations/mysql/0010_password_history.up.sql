@@ -0,0 +1,8 @@
+CREATE TABLE `password_history` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '用户id',
+  `password_hash` varchar(255) NOT NULL COMMENT '用户之前使用过的密码哈希',
+  `created_at` varchar(32) NOT NULL COMMENT '被替换的时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
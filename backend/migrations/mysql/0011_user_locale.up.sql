@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `locale` varchar(10) NOT NULL DEFAULT '' COMMENT '界面和接口消息的语言偏好，为空时按 Accept-Language 选择';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `article_transition`;
+-- 新增的状态退回到原有的三种状态
+UPDATE `article` SET `status` = '1' WHERE `status` = '3';
+UPDATE `article` SET `status` = '0' WHERE `status` IN ('4', '5');
+ALTER TABLE `article` DROP COLUMN `published_at`;
+ALTER TABLE `article` MODIFY `status` varchar(255) NOT NULL COMMENT '状态0草稿1提交2发布';
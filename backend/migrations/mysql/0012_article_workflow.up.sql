@@ -0,0 +1,17 @@
+ALTER TABLE `article` MODIFY `status` varchar(255) NOT NULL COMMENT '状态0草稿1提交2发布3通过4驳回5归档';
+ALTER TABLE `article` ADD COLUMN `published_at` varchar(32) NOT NULL DEFAULT '' COMMENT '最近一次发布的时间';
+-- 已发布的文章没有记录发布时间，使用创建时间
+UPDATE `article` SET `published_at` = `create_time` WHERE `status` = '2';
+
+CREATE TABLE `article_transition` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `article_id` int NOT NULL COMMENT '文章id',
+  `action` varchar(32) NOT NULL COMMENT '操作create/submit/approve/reject/publish/unpublish',
+  `from_status` varchar(8) NOT NULL DEFAULT '' COMMENT '变更前的状态',
+  `to_status` varchar(8) NOT NULL COMMENT '变更后的状态',
+  `actor_id` int NOT NULL COMMENT '操作人id',
+  `comment` varchar(1000) NOT NULL DEFAULT '' COMMENT '审核意见',
+  `created_at` varchar(32) NOT NULL COMMENT '操作时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  KEY `idx_article_id` (`article_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
@@ -0,0 +1,4 @@
+DROP INDEX `idx_article_unpublish_at` ON `article`;
+DROP INDEX `idx_article_publish_at` ON `article`;
+ALTER TABLE `article` DROP COLUMN `unpublish_at`;
+ALTER TABLE `article` DROP COLUMN `publish_at`;
@@ -0,0 +1,4 @@
+ALTER TABLE `article` ADD COLUMN `publish_at` varchar(32) NOT NULL DEFAULT '' COMMENT '定时发布时间，审核通过后到期自动发布';
+ALTER TABLE `article` ADD COLUMN `unpublish_at` varchar(32) NOT NULL DEFAULT '' COMMENT '定时下线时间，已发布的文章到期自动下线';
+CREATE INDEX `idx_article_publish_at` ON `article` (`status`, `publish_at`);
+CREATE INDEX `idx_article_unpublish_at` ON `article` (`status`, `unpublish_at`);
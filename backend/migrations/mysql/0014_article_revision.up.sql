@@ -0,0 +1,18 @@
+CREATE TABLE `article_revision` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `article_id` int NOT NULL COMMENT '文章id',
+  `title` varchar(255) NOT NULL COMMENT '标题',
+  `cover_image` varchar(255) NOT NULL DEFAULT '' COMMENT '封面',
+  `intro` varchar(255) NOT NULL DEFAULT '' COMMENT '简介',
+  `keywords` varchar(255) NOT NULL DEFAULT '' COMMENT '关键词',
+  `content` longtext NOT NULL COMMENT '内容',
+  `author_id` int NOT NULL COMMENT '修改人id',
+  `restored_from` int NOT NULL DEFAULT 0 COMMENT '从哪个版本恢复，0 表示普通编辑',
+  `created_at` varchar(32) NOT NULL COMMENT '保存时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  KEY `idx_article_id` (`article_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+-- 以文章当前的内容作为已有文章的第一个版本
+INSERT INTO `article_revision` (`article_id`, `title`, `cover_image`, `intro`, `keywords`, `content`, `author_id`, `created_at`)
+SELECT `id`, `title`, COALESCE(`cover_image`, ''), COALESCE(`intro`, ''), COALESCE(`keywords`, ''), COALESCE(`content`, ''), `creator_id`, `create_time` FROM `article`;
@@ -0,0 +1,3 @@
+-- 已有文章的渲染结果为空，查看时重新渲染并保存
+ALTER TABLE `article` ADD COLUMN `content_html` longtext NOT NULL COMMENT '正文渲染后的 HTML';
+ALTER TABLE `article` ADD COLUMN `toc` text NOT NULL COMMENT '按标题提取的目录（JSON）';
@@ -0,0 +1,58 @@
+CREATE TABLE `tag` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(50) NOT NULL COMMENT '名称，不区分大小写',
+  `slug` varchar(64) NOT NULL COMMENT '别名，用于 URL',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE KEY `uk_name` (`name`),
+  UNIQUE KEY `uk_slug` (`slug`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE `category` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(50) NOT NULL COMMENT '名称，不区分大小写',
+  `slug` varchar(64) NOT NULL COMMENT '别名，用于 URL',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE KEY `uk_name` (`name`),
+  UNIQUE KEY `uk_slug` (`slug`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE `article_tag` (
+  `article_id` int NOT NULL COMMENT '文章id',
+  `tag_id` int NOT NULL COMMENT '标签id',
+  PRIMARY KEY (`article_id`, `tag_id`) USING BTREE,
+  KEY `idx_tag_id` (`tag_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+CREATE TABLE `article_category` (
+  `article_id` int NOT NULL COMMENT '文章id',
+  `category_id` int NOT NULL COMMENT '分类id',
+  PRIMARY KEY (`article_id`, `category_id`) USING BTREE,
+  KEY `idx_category_id` (`category_id`)
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci ROW_FORMAT = Dynamic;
+
+-- 把已有文章的关键词拆分为标签，关键词之间可以用中英文逗号、顿号或分号分隔
+CREATE TEMPORARY TABLE `keyword_split` (
+  `article_id` int NOT NULL,
+  `name` varchar(50) NOT NULL
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci;
+INSERT INTO `keyword_split` (`article_id`, `name`)
+WITH RECURSIVE `split` (`article_id`, `name`, `rest`) AS (
+  SELECT `id`, CAST('' AS CHAR(255) CHARACTER SET utf8mb4),
+    CAST(CONCAT(REPLACE(REPLACE(REPLACE(REPLACE(COALESCE(`keywords`, ''), '，', ','), '、', ','), '；', ','), ';', ','), ',') AS CHAR(1024) CHARACTER SET utf8mb4) FROM `article`
+  UNION ALL
+  SELECT `article_id`, TRIM(SUBSTRING_INDEX(`rest`, ',', 1)), SUBSTRING(`rest`, LOCATE(',', `rest`) + 1) FROM `split` WHERE `rest` <> ''
+)
+SELECT `article_id`, TRIM(LEFT(`name`, 50)) FROM `split` WHERE `name` <> '';
+
+-- 先使用 tag-<id> 作为别名，名称本身符合别名格式且没有冲突时再改为小写的名称
+INSERT INTO `tag` (`name`, `slug`) SELECT MIN(`name`), UUID() FROM `keyword_split` GROUP BY `name`;
+UPDATE `tag` SET `slug` = CONCAT('tag-', `id`);
+UPDATE `tag` SET `slug` = LOWER(`name`)
+WHERE REGEXP_LIKE(LOWER(`name`), '^[a-z0-9]+(-[a-z0-9]+)*$', 'c')
+  AND LOWER(`name`) NOT IN (SELECT `slug` FROM (SELECT `slug` FROM `tag`) AS `t`);
+
+INSERT INTO `article_tag` (`article_id`, `tag_id`)
+SELECT DISTINCT `k`.`article_id`, `t`.`id` FROM `keyword_split` `k` JOIN `tag` `t` ON `t`.`name` = `k`.`name`;
+DROP TEMPORARY TABLE `keyword_split`;
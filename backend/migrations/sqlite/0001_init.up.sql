@@ -0,0 +1,36 @@
+-- 初始表结构，与 MySQL 版本的 0001_init 一致
+
+CREATE TABLE IF NOT EXISTS article (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  title       TEXT    NOT NULL,            -- 标题
+  cover_image TEXT    NULL DEFAULT NULL,   -- 封面
+  intro       TEXT    NULL DEFAULT NULL,   -- 简介
+  keywords    TEXT    NULL DEFAULT NULL,   -- 关键词
+  content     TEXT    NULL,                -- 内容
+  views       INTEGER NOT NULL,            -- 阅读量
+  creator_id  INTEGER NOT NULL,            -- 创建人id
+  create_time TEXT    NOT NULL,            -- 创建时间
+  status      TEXT    NOT NULL             -- 状态0草稿1提交2发布
+);
+
+CREATE TABLE IF NOT EXISTS project (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  project_name TEXT NOT NULL,
+  description  TEXT NULL DEFAULT NULL,
+  logo         TEXT NULL DEFAULT NULL,
+  url          TEXT NULL DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user (
+  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+  username      TEXT    NOT NULL,
+  password      TEXT    NOT NULL,
+  phone_number  TEXT    NULL DEFAULT NULL,
+  email         TEXT    NULL DEFAULT NULL,
+  real_name     TEXT    NULL DEFAULT NULL,
+  register_time TEXT    NULL DEFAULT NULL,
+  avatar        TEXT    NULL DEFAULT NULL,
+  creator_id    INTEGER NULL DEFAULT NULL,  -- 创建人id
+  status        TEXT    NOT NULL,           -- 状态0正常1限制2注销
+  role          TEXT    NOT NULL            -- 角色0管理员1游客
+);
@@ -0,0 +1,3 @@
+-- 角色由 0（管理员）/ 1（游客）改为角色名称
+UPDATE user SET role = 'admin' WHERE role = '0';
+UPDATE user SET role = 'viewer' WHERE role = '1';
@@ -0,0 +1,13 @@
+CREATE TABLE refresh_token (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id    INTEGER NOT NULL,            -- 用户id
+  family_id  TEXT    NOT NULL,            -- 令牌家族，同一次登录轮换出的令牌共用
+  token_hash TEXT    NOT NULL UNIQUE,     -- 令牌的 SHA-256 哈希
+  user_agent TEXT    NOT NULL DEFAULT '', -- 登录设备
+  ip         TEXT    NOT NULL DEFAULT '', -- 登录 IP
+  created_at TEXT    NOT NULL,
+  expires_at TEXT    NOT NULL,
+  revoked_at TEXT    NULL DEFAULT NULL    -- 撤销时间，为空表示有效
+);
+CREATE INDEX idx_refresh_token_family_id ON refresh_token (family_id);
+CREATE INDEX idx_refresh_token_user_id ON refresh_token (user_id);
@@ -0,0 +1,9 @@
+-- 令牌版本，递增后之前签发的访问令牌全部失效
+ALTER TABLE user ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+-- 同时签发的访问令牌 jti
+ALTER TABLE refresh_token ADD COLUMN access_jti TEXT NOT NULL DEFAULT '';
+CREATE TABLE token_denylist (
+  jti        TEXT NOT NULL PRIMARY KEY, -- 被撤销的访问令牌 jti
+  expires_at TEXT NOT NULL              -- 访问令牌的过期时间，过期后记录可以删除
+);
+CREATE INDEX idx_token_denylist_expires_at ON token_denylist (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE user DROP COLUMN restricted_until;
+ALTER TABLE user DROP COLUMN status_reason;
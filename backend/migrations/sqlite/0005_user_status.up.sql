@@ -0,0 +1,4 @@
+-- 限制或注销的原因
+ALTER TABLE user ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+-- 限制的解除时间，为空表示长期限制
+ALTER TABLE user ADD COLUMN restricted_until TEXT NOT NULL DEFAULT '';
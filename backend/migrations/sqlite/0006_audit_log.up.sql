@@ -0,0 +1,11 @@
+CREATE TABLE audit_log (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  action     TEXT    NOT NULL,            -- 操作类型
+  actor_id   INTEGER NOT NULL DEFAULT 0,  -- 操作人id，系统自动触发时为 0
+  target     TEXT    NOT NULL DEFAULT '', -- 操作对象
+  ip         TEXT    NOT NULL DEFAULT '',
+  detail     TEXT    NOT NULL DEFAULT '',
+  created_at TEXT    NOT NULL
+);
+CREATE INDEX idx_audit_log_action ON audit_log (action);
+CREATE INDEX idx_audit_log_target ON audit_log (target);
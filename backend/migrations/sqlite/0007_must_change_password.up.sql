@@ -0,0 +1,2 @@
+-- 下次登录后必须修改密码，管理员重置密码后设置
+ALTER TABLE user ADD COLUMN must_change_password INTEGER NOT NULL DEFAULT 0;
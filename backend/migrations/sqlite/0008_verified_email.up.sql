@@ -0,0 +1,2 @@
+-- 邮箱是否已验证，修改邮箱后重置为 0
+ALTER TABLE user ADD COLUMN verified_email INTEGER NOT NULL DEFAULT 0;
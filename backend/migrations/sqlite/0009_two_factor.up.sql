@@ -0,0 +1,15 @@
+CREATE TABLE user_two_factor (
+  user_id    INTEGER PRIMARY KEY,         -- 用户id
+  secret     TEXT    NOT NULL,            -- Base32 编码的 TOTP 密钥
+  enabled    INTEGER NOT NULL DEFAULT 0,  -- 验证过一次验证码后开启
+  last_step  INTEGER NOT NULL DEFAULT 0,  -- 最后一次使用的时间步，防止验证码重放
+  enabled_at TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE user_recovery_code (
+  id        INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id   INTEGER NOT NULL,         -- 用户id
+  code_hash TEXT    NOT NULL,         -- 恢复码的 SHA-256 哈希
+  used_at   TEXT    NULL DEFAULT NULL -- 使用时间，为空表示未使用
+);
+CREATE INDEX idx_user_recovery_code_user_id ON user_recovery_code (user_id);
@@ -0,0 +1,7 @@
+CREATE TABLE password_history (
+  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id       INTEGER NOT NULL, -- 用户id
+  password_hash TEXT    NOT NULL, -- 用户之前使用过的密码哈希
+  created_at    TEXT    NOT NULL  -- 被替换的时间
+);
+CREATE INDEX idx_password_history_user_id ON password_history (user_id);
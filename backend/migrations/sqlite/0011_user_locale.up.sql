@@ -0,0 +1,2 @@
+-- 界面和接口消息的语言偏好，为空时按 Accept-Language 选择
+ALTER TABLE user ADD COLUMN locale TEXT NOT NULL DEFAULT '';
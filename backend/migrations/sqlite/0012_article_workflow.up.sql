@@ -0,0 +1,16 @@
+-- 状态新增 3 通过、4 驳回、5 归档
+-- 最近一次发布的时间，已发布的文章没有记录发布时间，使用创建时间
+ALTER TABLE article ADD COLUMN published_at TEXT NOT NULL DEFAULT '';
+UPDATE article SET published_at = create_time WHERE status = '2';
+
+CREATE TABLE article_transition (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  article_id  INTEGER NOT NULL,             -- 文章id
+  action      TEXT    NOT NULL,             -- 操作create/submit/approve/reject/publish/unpublish
+  from_status TEXT    NOT NULL DEFAULT '',  -- 变更前的状态
+  to_status   TEXT    NOT NULL,             -- 变更后的状态
+  actor_id    INTEGER NOT NULL,             -- 操作人id
+  comment     TEXT    NOT NULL DEFAULT '',  -- 审核意见
+  created_at  TEXT    NOT NULL              -- 操作时间
+);
+CREATE INDEX idx_article_transition_article_id ON article_transition (article_id);
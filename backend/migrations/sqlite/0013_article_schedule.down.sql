@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_article_unpublish_at;
+DROP INDEX IF EXISTS idx_article_publish_at;
+ALTER TABLE article DROP COLUMN unpublish_at;
+ALTER TABLE article DROP COLUMN publish_at;
@@ -0,0 +1,17 @@
+CREATE TABLE article_revision (
+  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+  article_id    INTEGER NOT NULL,             -- 文章id
+  title         TEXT    NOT NULL,             -- 标题
+  cover_image   TEXT    NOT NULL DEFAULT '',  -- 封面
+  intro         TEXT    NOT NULL DEFAULT '',  -- 简介
+  keywords      TEXT    NOT NULL DEFAULT '',  -- 关键词
+  content       TEXT    NOT NULL,             -- 内容
+  author_id     INTEGER NOT NULL,             -- 修改人id
+  restored_from INTEGER NOT NULL DEFAULT 0,   -- 从哪个版本恢复，0 表示普通编辑
+  created_at    TEXT    NOT NULL              -- 保存时间
+);
+CREATE INDEX idx_article_revision_article_id ON article_revision (article_id);
+
+-- 以文章当前的内容作为已有文章的第一个版本
+INSERT INTO article_revision (article_id, title, cover_image, intro, keywords, content, author_id, created_at)
+SELECT id, title, COALESCE(cover_image, ''), COALESCE(intro, ''), COALESCE(keywords, ''), COALESCE(content, ''), creator_id, create_time FROM article;
@@ -0,0 +1,2 @@
+ALTER TABLE article DROP COLUMN toc;
+ALTER TABLE article DROP COLUMN content_html;
@@ -0,0 +1,4 @@
+-- 正文渲染后的 HTML，已有文章为空，查看时重新渲染并保存
+ALTER TABLE article ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
+-- 按标题提取的目录（JSON）
+ALTER TABLE article ADD COLUMN toc TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE article_category;
+DROP TABLE article_tag;
+DROP TABLE category;
+DROP TABLE tag;
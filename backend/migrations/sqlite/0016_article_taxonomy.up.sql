@@ -0,0 +1,51 @@
+-- 标签和分类，名称不区分大小写
+CREATE TABLE tag (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  name        TEXT    NOT NULL COLLATE NOCASE UNIQUE, -- 名称
+  slug        TEXT    NOT NULL UNIQUE,                -- 别名，用于 URL
+  description TEXT    NOT NULL DEFAULT ''             -- 描述
+);
+CREATE TABLE category (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  name        TEXT    NOT NULL COLLATE NOCASE UNIQUE, -- 名称
+  slug        TEXT    NOT NULL UNIQUE,                -- 别名，用于 URL
+  description TEXT    NOT NULL DEFAULT ''             -- 描述
+);
+
+-- 文章与标签、分类的关联
+CREATE TABLE article_tag (
+  article_id INTEGER NOT NULL, -- 文章id
+  tag_id     INTEGER NOT NULL, -- 标签id
+  PRIMARY KEY (article_id, tag_id)
+);
+CREATE INDEX idx_article_tag_tag_id ON article_tag (tag_id);
+CREATE TABLE article_category (
+  article_id  INTEGER NOT NULL, -- 文章id
+  category_id INTEGER NOT NULL, -- 分类id
+  PRIMARY KEY (article_id, category_id)
+);
+CREATE INDEX idx_article_category_category_id ON article_category (category_id);
+
+-- 把已有文章的关键词拆分为标签，关键词之间可以用中英文逗号、顿号或分号分隔
+CREATE TEMP TABLE keyword_split (
+  article_id INTEGER NOT NULL,
+  name       TEXT    NOT NULL COLLATE NOCASE
+);
+INSERT INTO keyword_split (article_id, name)
+WITH RECURSIVE split (article_id, name, rest) AS (
+  SELECT id, '', REPLACE(REPLACE(REPLACE(REPLACE(COALESCE(keywords, ''), '，', ','), '、', ','), '；', ','), ';', ',') || ',' FROM article
+  UNION ALL
+  SELECT article_id, TRIM(SUBSTR(rest, 1, INSTR(rest, ',') - 1)), SUBSTR(rest, INSTR(rest, ',') + 1) FROM split WHERE rest <> ''
+)
+SELECT article_id, TRIM(SUBSTR(name, 1, 50)) FROM split WHERE name <> '';
+
+-- 先使用 tag-<id> 作为别名，名称本身符合别名格式且没有冲突时再改为小写的名称
+INSERT INTO tag (name, slug) SELECT MIN(name), 'tag-tmp-' || MIN(rowid) FROM keyword_split GROUP BY name;
+UPDATE tag SET slug = 'tag-' || id;
+UPDATE tag SET slug = LOWER(name)
+WHERE LOWER(name) NOT GLOB '*[^a-z0-9-]*' AND name NOT GLOB '-*' AND name NOT GLOB '*-' AND name NOT GLOB '*--*'
+  AND NOT EXISTS (SELECT 1 FROM tag t WHERE t.slug = LOWER(tag.name));
+
+INSERT INTO article_tag (article_id, tag_id)
+SELECT DISTINCT k.article_id, t.id FROM keyword_split k JOIN tag t ON t.name = k.name;
+DROP TABLE keyword_split;
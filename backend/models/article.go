@@ -1,14 +1,96 @@
 package models
 
+import (
+	"backend/markdown"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type Article struct {
 	ID         int    `json:"id"`
-	Title      string `json:"title" validate:"required,min=1,max=50"`
+	Title      string `json:"title" validate:"required,min=1,max=50" msg:"文章名称不能为空，且长度在 1-50 位之间"`
 	CoverImage string `json:"cover_image"`
 	Intro      string `json:"intro"`
 	Keywords   string `json:"keywords"`
-	Content    string `json:"content"`
-	Views      int    `json:"views"`
-	CreatorID  int    `json:"creator_id"`
-	CreateTime string `json:"create_time"`
-	Status     string `json:"status" validate:"required,oneof=0 1 2"`
+	Content    string `json:"content"` // Markdown 原文
+	// 正文渲染后的 HTML 和按标题提取的目录，保存文章时由服务端生成，请求中传入的值会被忽略
+	ContentHTML string     `json:"content_html"`
+	TOC         ArticleTOC `json:"toc"`
+	Views       int        `json:"views"`
+	CreatorID   int        `json:"creator_id"`
+	CreateTime  string     `json:"create_time"`
+	// 状态只能通过审核流程的接口修改，编辑文章时保持不变，见 article_workflow.go
+	Status      string `json:"status" validate:"omitempty,oneof=0 1 2 3 4 5" msg:"文章状态设置错误"`
+	PublishedAt string `json:"published_at"` // 最近一次发布的时间，未发布过时为空
+	// 定时发布和下线的时间（格式 2006-01-02 15:04:05），为空表示不定时
+	// 审核通过的文章在 publish_at 到期后自动发布，已发布的文章在 unpublish_at 到期后自动下线，执行后清空
+	PublishAt   string `json:"publish_at" validate:"omitempty,datetime=2006-01-02 15:04:05" msg:"定时发布时间格式错误，应为 2006-01-02 15:04:05"`
+	UnpublishAt string `json:"unpublish_at" validate:"omitempty,datetime=2006-01-02 15:04:05" msg:"定时下线时间格式错误，应为 2006-01-02 15:04:05"`
+	// 文章的标签和分类id，保存时不传表示保持不变，传空数组表示全部移除
+	TagIDs      []int `json:"tag_ids" validate:"max=20,unique" msg:"标签最多 20 个，且不能重复"`
+	CategoryIDs []int `json:"category_ids" validate:"max=5,unique" msg:"分类最多 5 个，且不能重复"`
+	// 查询时附带的标签和分类，保存时以 TagIDs 和 CategoryIDs 为准，请求中传入的值会被忽略
+	Tags       []TermRef `json:"tags"`
+	Categories []TermRef `json:"categories"`
+}
+
+// IsPublic 判断文章是否对所有人可见：已发布，且没有到定时下线的时间
+// 定时任务执行前已到期的文章同样不可见
+func (a *Article) IsPublic(now time.Time) bool {
+	return a.Status == ArticleStatusPublished && (a.UnpublishAt == "" || a.UnpublishAt > now.Format(TimeLayout))
+}
+
+// ArticleTOC 文章目录，以 JSON 保存在 article.toc 字段中
+type ArticleTOC []markdown.Heading
+
+// Value 实现 driver.Valuer，空目录保存为 []
+func (t ArticleTOC) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]markdown.Heading(t))
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner，解析 article.toc 字段
+func (t *ArticleTOC) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = ArticleTOC{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 解析为文章目录", src)
+	}
+	if len(data) == 0 {
+		*t = ArticleTOC{}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]markdown.Heading)(t))
+}
+
+// ArticleListItem 文章列表项，不包含正文，附带创建人用户名
+type ArticleListItem struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Intro       string `json:"intro"`
+	CoverImage  string `json:"cover_image"`
+	Keywords    string `json:"keywords"`
+	Views       int    `json:"views"`
+	CreatorID   int    `json:"creator_id"`
+	CreateTime  string `json:"create_time"`
+	Status      string `json:"status"`
+	PublishedAt string `json:"published_at"`
+	PublishAt   string `json:"publish_at"`
+	UnpublishAt string `json:"unpublish_at"`
+	Creator     string `json:"creator"`
+	// 文章的标签和分类，按 id 排序
+	Tags       []TermRef `json:"tags"`
+	Categories []TermRef `json:"categories"`
 }
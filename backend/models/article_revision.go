@@ -0,0 +1,70 @@
+package models
+
+// ArticleRevision 文章的一个历史版本，保存当时的完整内容，创建后不再修改
+// 创建文章和每次修改标题、封面、简介、关键词或正文时都会保存一个版本
+type ArticleRevision struct {
+	ID           int    `json:"id"`
+	ArticleID    int    `json:"article_id"`
+	Title        string `json:"title"`
+	CoverImage   string `json:"cover_image"`
+	Intro        string `json:"intro"`
+	Keywords     string `json:"keywords"`
+	Content      string `json:"content"`
+	AuthorID     int    `json:"author_id"`     // 保存该版本的用户id
+	Author       string `json:"author"`        // 保存该版本的用户名，查询时填充
+	RestoredFrom int    `json:"restored_from"` // 由哪个版本恢复而来，0 表示普通编辑
+	CreatedAt    string `json:"created_at"`
+}
+
+// ArticleRevisionListItem 版本列表项，不包含正文
+type ArticleRevisionListItem struct {
+	ID           int    `json:"id"`
+	ArticleID    int    `json:"article_id"`
+	Title        string `json:"title"`
+	AuthorID     int    `json:"author_id"`
+	Author       string `json:"author"`
+	RestoredFrom int    `json:"restored_from"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// NewArticleRevision 以文章当前的内容创建一个版本
+func NewArticleRevision(article *Article, authorID int, createdAt string) *ArticleRevision {
+	return &ArticleRevision{
+		ArticleID:  article.ID,
+		Title:      article.Title,
+		CoverImage: article.CoverImage,
+		Intro:      article.Intro,
+		Keywords:   article.Keywords,
+		Content:    article.Content,
+		AuthorID:   authorID,
+		CreatedAt:  createdAt,
+	}
+}
+
+// ListItem 返回不包含正文的版本信息
+func (r *ArticleRevision) ListItem() ArticleRevisionListItem {
+	return ArticleRevisionListItem{
+		ID:           r.ID,
+		ArticleID:    r.ArticleID,
+		Title:        r.Title,
+		AuthorID:     r.AuthorID,
+		Author:       r.Author,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// SameContent 判断两篇文章的标题、封面、简介、关键词和正文是否相同，相同时编辑不保存新版本
+func (a *Article) SameContent(other *Article) bool {
+	return a.Title == other.Title && a.CoverImage == other.CoverImage && a.Intro == other.Intro &&
+		a.Keywords == other.Keywords && a.Content == other.Content
+}
+
+// ApplyTo 把该版本的内容写回文章，状态和定时设置保持不变
+func (r *ArticleRevision) ApplyTo(article *Article) {
+	article.Title = r.Title
+	article.CoverImage = r.CoverImage
+	article.Intro = r.Intro
+	article.Keywords = r.Keywords
+	article.Content = r.Content
+}
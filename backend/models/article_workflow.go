@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"slices"
+)
+
+// 文章状态，对应 article.status 字段；0、1、2 与原有数据保持一致
+const (
+	ArticleStatusDraft     = "0" // 草稿
+	ArticleStatusSubmitted = "1" // 已提交，等待审核
+	ArticleStatusPublished = "2" // 已发布，公开可见
+	ArticleStatusApproved  = "3" // 审核通过，等待发布
+	ArticleStatusRejected  = "4" // 审核驳回，修改后可以重新提交
+	ArticleStatusArchived  = "5" // 已下线归档，可以重新提交
+)
+
+// 文章状态变更的操作，记录在 article_transition.action 中
+const (
+	ArticleActionCreate    = "create" // 创建文章，from_status 为空
+	ArticleActionSubmit    = "submit"
+	ArticleActionApprove   = "approve"
+	ArticleActionReject    = "reject"
+	ArticleActionPublish   = "publish"
+	ArticleActionUnpublish = "unpublish"
+)
+
+// CodeInvalidArticleTransition 文章当前状态不允许执行该操作
+const CodeInvalidArticleTransition = "invalid_article_transition"
+
+var (
+	// ErrInvalidArticleTransition 文章当前状态不允许执行该操作
+	ErrInvalidArticleTransition = errors.New("文章当前状态不能执行该操作")
+	// ErrArticleTransitionForbidden 当前用户没有执行该操作的权限
+	ErrArticleTransitionForbidden = errors.New("没有权限执行该操作")
+)
+
+// articleTransitionRule 一条允许的状态变更
+// 拥有 Permission 权限的用户可以操作任何人的文章，Owner 为 true 时文章创建人也可以操作自己的文章
+type articleTransitionRule struct {
+	Action     string
+	From       []string
+	To         string
+	Permission string
+	Owner      bool
+}
+
+// articleWorkflow 文章状态机，同一个操作可以有多条规则，按顺序匹配第一条允许的规则
+var articleWorkflow = []articleTransitionRule{
+	// 作者提交草稿、被驳回或已下线的文章进入审核
+	{ArticleActionSubmit, []string{ArticleStatusDraft, ArticleStatusRejected, ArticleStatusArchived}, ArticleStatusSubmitted, PermArticleEditAny, true},
+	// 审核人通过或驳回已提交的文章，通过后发布前仍可驳回
+	{ArticleActionApprove, []string{ArticleStatusSubmitted}, ArticleStatusApproved, PermArticleReview, false},
+	{ArticleActionReject, []string{ArticleStatusSubmitted, ArticleStatusApproved}, ArticleStatusRejected, PermArticleReview, false},
+	// 审核通过的文章可以由作者或有发布权限的用户发布；有发布权限的用户可以跳过审核直接发布
+	{ArticleActionPublish, []string{ArticleStatusApproved}, ArticleStatusPublished, PermArticlePublish, true},
+	{ArticleActionPublish, []string{ArticleStatusDraft, ArticleStatusSubmitted}, ArticleStatusPublished, PermArticlePublish, false},
+	{ArticleActionUnpublish, []string{ArticleStatusPublished}, ArticleStatusArchived, PermArticlePublish, true},
+}
+
+// ArticleTransitionTarget 检查用户能否对处于 status 状态的文章执行 action，返回变更后的状态
+// isOwner 表示当前用户是否为文章创建人；状态不允许时返回 ErrInvalidArticleTransition，没有权限时返回 ErrArticleTransitionForbidden
+func ArticleTransitionTarget(action, status, role string, isOwner bool) (string, error) {
+	err := ErrInvalidArticleTransition
+	for _, rule := range articleWorkflow {
+		if rule.Action != action || !slices.Contains(rule.From, status) {
+			continue
+		}
+		if HasPermission(role, rule.Permission) || (rule.Owner && isOwner) {
+			return rule.To, nil
+		}
+		err = ErrArticleTransitionForbidden
+	}
+	return "", err
+}
+
+// ContentUnderReview 判断文章的内容是否已经提交审核：已提交、审核通过或已发布
+// 这些状态的文章由没有审核权限的用户修改内容，会使未经审核的内容被发布
+func ContentUnderReview(status string) bool {
+	return status == ArticleStatusSubmitted || status == ArticleStatusApproved || status == ArticleStatusPublished
+}
+
+// ArticleTransition 文章状态变更记录，创建后不再修改
+type ArticleTransition struct {
+	ID         int    `json:"id"`
+	ArticleID  int    `json:"article_id"`
+	Action     string `json:"action"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ActorID    int    `json:"actor_id"` // 操作人id，定时发布和下线时为 0
+	Actor      string `json:"actor"`    // 操作人用户名，查询时填充
+	Comment    string `json:"comment"`  // 审核意见，驳回时必填
+	CreatedAt  string `json:"created_at"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArticleTransitionTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  string
+		from    string
+		role    string
+		isOwner bool
+		want    string
+		wantErr error
+	}{
+		// 提交
+		{"作者提交草稿", ArticleActionSubmit, ArticleStatusDraft, RoleAuthor, true, ArticleStatusSubmitted, nil},
+		{"作者重新提交被驳回的文章", ArticleActionSubmit, ArticleStatusRejected, RoleAuthor, true, ArticleStatusSubmitted, nil},
+		{"作者重新提交已下线的文章", ArticleActionSubmit, ArticleStatusArchived, RoleAuthor, true, ArticleStatusSubmitted, nil},
+		{"编辑提交他人的草稿", ArticleActionSubmit, ArticleStatusDraft, RoleEditor, false, ArticleStatusSubmitted, nil},
+		{"作者不能提交他人的草稿", ArticleActionSubmit, ArticleStatusDraft, RoleAuthor, false, "", ErrArticleTransitionForbidden},
+		{"已提交的文章不能再次提交", ArticleActionSubmit, ArticleStatusSubmitted, RoleAdmin, true, "", ErrInvalidArticleTransition},
+		{"已发布的文章不能提交", ArticleActionSubmit, ArticleStatusPublished, RoleAuthor, true, "", ErrInvalidArticleTransition},
+
+		// 审核
+		{"编辑通过已提交的文章", ArticleActionApprove, ArticleStatusSubmitted, RoleEditor, false, ArticleStatusApproved, nil},
+		{"作者不能通过自己的文章", ArticleActionApprove, ArticleStatusSubmitted, RoleAuthor, true, "", ErrArticleTransitionForbidden},
+		{"草稿不能通过", ArticleActionApprove, ArticleStatusDraft, RoleAdmin, false, "", ErrInvalidArticleTransition},
+		{"编辑驳回已提交的文章", ArticleActionReject, ArticleStatusSubmitted, RoleEditor, false, ArticleStatusRejected, nil},
+		{"发布前可以驳回审核通过的文章", ArticleActionReject, ArticleStatusApproved, RoleAdmin, false, ArticleStatusRejected, nil},
+		{"作者不能驳回", ArticleActionReject, ArticleStatusSubmitted, RoleAuthor, true, "", ErrArticleTransitionForbidden},
+		{"已发布的文章不能驳回", ArticleActionReject, ArticleStatusPublished, RoleAdmin, false, "", ErrInvalidArticleTransition},
+
+		// 发布
+		{"作者发布审核通过的文章", ArticleActionPublish, ArticleStatusApproved, RoleAuthor, true, ArticleStatusPublished, nil},
+		{"作者不能发布他人审核通过的文章", ArticleActionPublish, ArticleStatusApproved, RoleAuthor, false, "", ErrArticleTransitionForbidden},
+		{"作者不能跳过审核发布草稿", ArticleActionPublish, ArticleStatusDraft, RoleAuthor, true, "", ErrArticleTransitionForbidden},
+		{"作者不能跳过审核发布已提交的文章", ArticleActionPublish, ArticleStatusSubmitted, RoleAuthor, true, "", ErrArticleTransitionForbidden},
+		{"编辑直接发布草稿", ArticleActionPublish, ArticleStatusDraft, RoleEditor, false, ArticleStatusPublished, nil},
+		{"编辑直接发布已提交的文章", ArticleActionPublish, ArticleStatusSubmitted, RoleEditor, false, ArticleStatusPublished, nil},
+		{"被驳回的文章不能发布", ArticleActionPublish, ArticleStatusRejected, RoleAdmin, false, "", ErrInvalidArticleTransition},
+		{"已下线的文章不能直接发布", ArticleActionPublish, ArticleStatusArchived, RoleAdmin, false, "", ErrInvalidArticleTransition},
+
+		// 下线
+		{"作者下线自己的文章", ArticleActionUnpublish, ArticleStatusPublished, RoleAuthor, true, ArticleStatusArchived, nil},
+		{"编辑下线他人的文章", ArticleActionUnpublish, ArticleStatusPublished, RoleEditor, false, ArticleStatusArchived, nil},
+		{"作者不能下线他人的文章", ArticleActionUnpublish, ArticleStatusPublished, RoleAuthor, false, "", ErrArticleTransitionForbidden},
+		{"草稿不能下线", ArticleActionUnpublish, ArticleStatusDraft, RoleAdmin, false, "", ErrInvalidArticleTransition},
+
+		// 其他
+		{"访客不能提交他人的文章", ArticleActionSubmit, ArticleStatusDraft, RoleViewer, false, "", ErrArticleTransitionForbidden},
+		{"未知的操作", "delete", ArticleStatusDraft, RoleAdmin, true, "", ErrInvalidArticleTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ArticleTransitionTarget(tt.action, tt.from, tt.role, tt.isOwner)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ArticleTransitionTarget() error = %v，期望 %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ArticleTransitionTarget() = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentUnderReview(t *testing.T) {
+	tests := map[string]bool{
+		ArticleStatusDraft:     false,
+		ArticleStatusSubmitted: true,
+		ArticleStatusPublished: true,
+		ArticleStatusApproved:  true,
+		ArticleStatusRejected:  false,
+		ArticleStatusArchived:  false,
+	}
+	for status, want := range tests {
+		if got := ContentUnderReview(status); got != want {
+			t.Errorf("ContentUnderReview(%q) = %v，期望 %v", status, got, want)
+		}
+	}
+}
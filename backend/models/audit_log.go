@@ -0,0 +1,23 @@
+package models
+
+// 审计日志的操作类型
+const (
+	AuditLoginLocked   = "login_locked"   // 连续登录失败导致账号被锁定
+	AuditLoginUnlocked = "login_unlocked" // 管理员解除账号锁定
+
+	AuditTwoFactorEnabled     = "two_factor_enabled"     // 开启两步验证
+	AuditTwoFactorDisabled    = "two_factor_disabled"    // 关闭两步验证，管理员重置时 actor_id 为管理员
+	AuditRecoveryCodeUsed     = "recovery_code_used"     // 使用恢复码登录
+	AuditRecoveryCodesRenewed = "recovery_codes_renewed" // 重新生成恢复码
+)
+
+// AuditLog 审计日志，记录安全相关的操作
+type AuditLog struct {
+	ID        int    `json:"id"`
+	Action    string `json:"action"`
+	ActorID   int    `json:"actor_id"` // 操作人id，系统自动触发时为 0
+	Target    string `json:"target"`   // 操作对象，例如被锁定的用户名
+	IP        string `json:"ip"`
+	Detail    string `json:"detail"`
+	CreatedAt string `json:"created_at"`
+}
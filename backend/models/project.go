@@ -2,8 +2,8 @@ package models
 
 type Project struct {
 	ID          int    `json:"id"`
-	ProjectName string `json:"project_name" validate:"required,min=1,max=20"`
-	Description string `json:"description" validate:"min=0,max=100"`
+	ProjectName string `json:"project_name" validate:"required,min=1,max=20" msg:"项目名称不能为空，且长度在 1-20 位之间"`
+	Description string `json:"description" validate:"min=0,max=100" msg:"项目描述在100字以下"`
 	Logo        string `json:"logo"`
 	Url         string `json:"url"`
 }
@@ -0,0 +1,26 @@
+package models
+
+// RefreshToken 服务端保存的刷新令牌，只保存令牌的哈希值
+// 同一次登录后轮换出的所有刷新令牌属于同一个家族（FamilyID）
+type RefreshToken struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	FamilyID  string `json:"family_id"`
+	TokenHash string `json:"-"`
+	UserAgent string `json:"user_agent"`
+	IP        string `json:"ip"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+	RevokedAt string `json:"revoked_at"` // 为空表示仍然有效，轮换或注销后记录时间
+	AccessJTI string `json:"-"`          // 与该刷新令牌一起签发的访问令牌 jti，注销会话时加入黑名单
+}
+
+// Session 用户的一个登录会话，对应一个仍然有效的刷新令牌家族
+type Session struct {
+	ID           string `json:"id"` // 令牌家族 id
+	UserAgent    string `json:"user_agent"`
+	IP           string `json:"ip"`
+	LastActiveAt string `json:"last_active_at"` // 最近一次登录或刷新的时间
+	ExpiresAt    string `json:"expires_at"`
+	Current      bool   `json:"current"` // 是否为发起请求的会话
+}
@@ -0,0 +1,86 @@
+package models
+
+// 角色名称，保存在 user.role 字段中
+const (
+	RoleAdmin  = "admin"  // 管理员，拥有全部权限
+	RoleEditor = "editor" // 编辑，可以管理所有文章和项目
+	RoleAuthor = "author" // 作者，可以撰写文章
+	RoleViewer = "viewer" // 访客，只能浏览
+)
+
+// 权限字符串，格式为 资源:操作
+const (
+	PermArticleCreate    = "article:create"
+	PermArticleEdit      = "article:edit"       // 编辑自己创建的文章
+	PermArticleEditAny   = "article:edit_any"   // 编辑任何人创建的文章
+	PermArticleDelete    = "article:delete"     // 删除自己创建的文章
+	PermArticleDeleteAny = "article:delete_any" // 删除任何人创建的文章
+	PermArticlePublish   = "article:publish"
+	PermArticleReview    = "article:review" // 审核（通过或驳回）已提交的文章
+	PermProjectManage    = "project:manage"
+	PermTaxonomyManage   = "taxonomy:manage" // 管理标签和分类
+	PermUploadImage      = "upload:image"
+	PermUserManage       = "user:manage"
+	PermRoleManage       = "role:manage"
+)
+
+// Role 角色及其拥有的权限
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// Roles 系统内置的全部角色
+var Roles = []Role{
+	{
+		Name:        RoleAdmin,
+		Description: "管理员",
+		Permissions: []string{
+			PermArticleCreate, PermArticleEdit, PermArticleEditAny, PermArticleDelete, PermArticleDeleteAny, PermArticlePublish, PermArticleReview,
+			PermProjectManage, PermTaxonomyManage, PermUploadImage, PermUserManage, PermRoleManage,
+		},
+	},
+	{
+		Name:        RoleEditor,
+		Description: "编辑",
+		Permissions: []string{
+			PermArticleCreate, PermArticleEdit, PermArticleEditAny, PermArticleDelete, PermArticleDeleteAny, PermArticlePublish, PermArticleReview,
+			PermProjectManage, PermTaxonomyManage, PermUploadImage,
+		},
+	},
+	{
+		Name:        RoleAuthor,
+		Description: "作者",
+		Permissions: []string{PermArticleCreate, PermArticleEdit, PermArticleDelete, PermUploadImage},
+	},
+	{
+		Name:        RoleViewer,
+		Description: "访客",
+		Permissions: []string{},
+	},
+}
+
+// rolePermissions 角色到权限集合的索引，由 Roles 生成
+var rolePermissions = func() map[string]map[string]bool {
+	index := make(map[string]map[string]bool, len(Roles))
+	for _, role := range Roles {
+		perms := make(map[string]bool, len(role.Permissions))
+		for _, p := range role.Permissions {
+			perms[p] = true
+		}
+		index[role.Name] = perms
+	}
+	return index
+}()
+
+// IsValidRole 判断角色名称是否存在
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有指定权限
+func HasPermission(role, permission string) bool {
+	return rolePermissions[role][permission]
+}
@@ -0,0 +1,18 @@
+package models
+
+// Term 标签或分类，两者结构相同，分别保存在 tag 和 category 表中
+// 一篇文章可以有多个标签和多个分类
+type Term struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name" validate:"required,max=50" msg:"名称不能为空，且长度不能超过 50 个字符"`
+	Slug         string `json:"slug" validate:"omitempty,max=64,slug" msg:"别名只能包含小写字母、数字和连字符，且长度不能超过 64 个字符"` // 用于 URL，例如 /tags/golang
+	Description  string `json:"description" validate:"max=255" msg:"描述不能超过 255 个字符"`
+	ArticleCount int    `json:"article_count"` // 已发布的文章数，查询时统计
+}
+
+// TermRef 文章详情和列表中的标签或分类
+type TermRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// 登录时需要两步验证返回的错误码
+const (
+	CodeTwoFactorRequired           = "two_factor_required"            // 已开启两步验证，需要提交验证码
+	CodeTwoFactorEnrollmentRequired = "two_factor_enrollment_required" // 当前角色必须开启两步验证，需要先绑定验证器
+)
+
+// 管理两步验证时返回的错误码
+const (
+	CodeInvalidTwoFactorCode    = "invalid_two_factor_code"    // 验证码或恢复码错误，或者验证码已经使用过
+	CodeTwoFactorMandatory      = "two_factor_mandatory"       // 当前角色必须开启两步验证，不能关闭
+	CodeTwoFactorAlreadyEnabled = "two_factor_already_enabled" // 已开启两步验证
+	CodeTwoFactorNotEnabled     = "two_factor_not_enabled"     // 未开启两步验证
+	CodeTwoFactorNotSetup       = "two_factor_not_setup"       // 开启前需要先获取密钥
+)
+
+// TwoFactor 用户的两步验证（TOTP）设置
+type TwoFactor struct {
+	UserID    int    `json:"user_id"`
+	Secret    string `json:"-"`          // Base32 编码的 TOTP 密钥
+	Enabled   bool   `json:"enabled"`    // 获取密钥后需要提交一次正确的验证码才会开启
+	LastStep  int64  `json:"-"`          // 最后一次使用的时间步，同一个验证码不能重复使用
+	EnabledAt string `json:"enabled_at"` // 开启时间
+}
+
+// IsAdminRole 判断角色是否属于管理员角色（可以管理用户或角色），用于要求管理员开启两步验证
+func IsAdminRole(role string) bool {
+	return HasPermission(role, PermUserManage) || HasPermission(role, PermRoleManage)
+}
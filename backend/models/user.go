@@ -1,30 +1,22 @@
 package models
 
-import "regexp"
-
 // User 模型表示用户的数据结构
 type User struct {
-	ID           int    `json:"id"`
-	Username     string `json:"username" validate:"required,min=1,max=20"`
-	Password     string `json:"password" validate:"required,min=6,max=30,containsany=abcdefghijklmnopqrstuvwxyz,containsany=ABCDEFGHIJKLMNOPQRSTUVWXYZ,containsany=0123456789,containsany=.!@#$%^&*()"`
-	PhoneNumber  string `json:"phone_number"`
-	Email        string `json:"email"`
-	RealName     string `json:"real_name"`
-	RegisterTime string `json:"register_time"`
-	Avatar       string `json:"avatar"`
-	CreatorID    int    `json:"creator_id"`
-	Status       string `json:"status" `
-	Role         string `json:"role" validate:"required,oneof=0 1"`
-}
-
-// IsValidPhoneNumber 验证手机号格式
-func IsValidPhoneNumber(phoneNumber string) bool {
-	phoneRegex := regexp.MustCompile(`^1[3-9]\d{9}$`)
-	return phoneRegex.MatchString(phoneNumber)
-}
-
-// IsValidEmail 验证邮箱格式
-func IsValidEmail(email string) bool {
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9_.+-]+@[a-zA-Z0-9-]+\.[a-zA-Z0-9-.]+$`)
-	return emailRegex.MatchString(email)
+	ID                 int    `json:"id"`
+	Username           string `json:"username" validate:"required,min=1,max=20" msg:"用户名不能为空，且长度在 1-20 位之间"`
+	Password           string `json:"password"` // 创建用户时按密码策略校验
+	PhoneNumber        string `json:"phone_number" validate:"omitempty,phone" msg:"请输入正确的手机号"`
+	Email              string `json:"email" validate:"omitempty,email" msg:"请输入正确的邮箱"`
+	RealName           string `json:"real_name"`
+	RegisterTime       string `json:"register_time"`
+	Avatar             string `json:"avatar"`
+	CreatorID          int    `json:"creator_id"`
+	Status             string `json:"status" `
+	StatusReason       string `json:"status_reason"`    // 限制或注销的原因
+	RestrictedUntil    string `json:"restricted_until"` // 限制的解除时间，为空表示长期限制
+	Role               string `json:"role" validate:"required,oneof=admin editor author viewer" msg:"用户角色设置错误"`
+	MustChangePassword bool   `json:"must_change_password"` // 管理员重置密码后为 true，用户修改密码前只能访问修改密码接口
+	VerifiedEmail      bool   `json:"verified_email"`       // 邮箱是否已通过验证，只有已验证的邮箱可以用来找回密码
+	Locale             string `json:"locale"`               // 语言偏好（zh-CN 或 en-US），为空时按 Accept-Language 选择
+	TokenVersion       int    `json:"-"`                    // 令牌版本，修改密码、重置密码或注销账号时递增，使已签发的访问令牌失效
 }
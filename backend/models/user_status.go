@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// 用户状态，对应 user.status 字段
+const (
+	UserStatusNormal     = "0" // 正常
+	UserStatusRestricted = "1" // 限制：可以登录和浏览，不能进行需要权限的写操作
+	UserStatusCancelled  = "2" // 注销：不能登录，已签发的令牌全部失效
+)
+
+// 账号状态不允许当前操作时返回的错误码，前端根据错误码展示原因
+const (
+	CodeAccountRestricted = "account_restricted"
+	CodeAccountCancelled  = "account_cancelled"
+	// CodePasswordChangeRequired 使用临时密码登录后需要先修改密码
+	CodePasswordChangeRequired = "password_change_required"
+)
+
+// TimeLayout 数据库中时间字段的格式
+const TimeLayout = "2006-01-02 15:04:05"
+
+// AccountStatusError 账号被限制或注销时返回给前端的详细信息
+type AccountStatusError struct {
+	Code            string `json:"code"`
+	Message         string `json:"-"`
+	Reason          string `json:"reason"`                     // 管理员填写的原因
+	RestrictedUntil string `json:"restricted_until,omitempty"` // 限制的解除时间，为空表示长期限制
+}
+
+// ErrorCode 返回错误码，用于填充响应中的 code
+func (e *AccountStatusError) ErrorCode() string {
+	return e.Code
+}
+
+// ApplyRestrictionExpiry 临时限制到期后按正常状态处理，并清空限制信息
+// 到期的限制不需要修改数据库，读取用户时调用即可自动解除
+func (u *User) ApplyRestrictionExpiry(now time.Time) {
+	if u.Status == UserStatusRestricted && u.RestrictedUntil != "" && u.RestrictedUntil <= now.Format(TimeLayout) {
+		u.Status = UserStatusNormal
+		u.StatusReason = ""
+		u.RestrictedUntil = ""
+	}
+}
+
+// StatusError 返回账号当前状态对应的错误信息，状态正常时返回 nil
+func (u *User) StatusError() *AccountStatusError {
+	switch u.Status {
+	case UserStatusRestricted:
+		return &AccountStatusError{
+			Code:            CodeAccountRestricted,
+			Message:         "账号已被限制，暂时只能浏览",
+			Reason:          u.StatusReason,
+			RestrictedUntil: u.RestrictedUntil,
+		}
+	case UserStatusCancelled:
+		return &AccountStatusError{
+			Code:    CodeAccountCancelled,
+			Message: "账号已注销",
+			Reason:  u.StatusReason,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// articleSchedule 一种定时状态变更，column 为保存到期时间的字段
+type articleSchedule struct {
+	action  string
+	column  string
+	from    string
+	to      string
+	comment string
+}
+
+// articleSchedules 定时任务处理的状态变更，与审核流程中作者本人可以执行的操作一致
+var articleSchedules = []articleSchedule{
+	{models.ArticleActionPublish, "publish_at", models.ArticleStatusApproved, models.ArticleStatusPublished, "定时发布"},
+	{models.ArticleActionUnpublish, "unpublish_at", models.ArticleStatusPublished, models.ArticleStatusArchived, "定时下线"},
+}
+
+// ErrArticleStatusChanged 变更状态时文章已不是预期的状态，通常是被其他请求同时修改
+var ErrArticleStatusChanged = errors.New("文章状态已被修改，请刷新后重试")
+
+// ArticleQuery 文章列表查询条件
+type ArticleQuery struct {
+	Pagination
+	Keyword   string // 匹配标题、简介或关键词
+	Status    string
+	CreatorID int    // 大于 0 时只查询该用户创建的文章
+	Public    bool   // 只查询对所有人可见的文章，见 models.Article.IsPublic
+	Tag       string // 只查询带有该别名标签的文章
+	Category  string // 只查询属于该别名分类的文章
+}
+
+// ArticleRepository 文章数据访问接口
+type ArticleRepository interface {
+	// Create 新增文章，成功后回填 article.ID，同时以创建人的身份记录一条 create 状态变更和第一个版本
+	// TagIDs 和 CategoryIDs 不为 nil 时关联对应的标签和分类，成功后回填 Tags 和 Categories
+	Create(ctx context.Context, article *models.Article) error
+	// Update 更新文章的标题、封面、简介、关键词、正文（包括渲染结果）以及定时发布和下线时间，状态只能通过 Transition 修改
+	// revision 不为空时在同一个事务中保存该版本并回填 revision.ID，内容没有变化时调用方可以传 nil
+	// TagIDs 或 CategoryIDs 不为 nil 时替换文章的标签或分类，为 nil 时保持不变，成功后回填 Tags 和 Categories
+	Update(ctx context.Context, article *models.Article, revision *models.ArticleRevision) error
+	// SaveRendered 只保存正文的渲染结果和目录，不保存版本；正文已被修改时不做任何操作
+	SaveRendered(ctx context.Context, article *models.Article) error
+	Delete(ctx context.Context, id int) error
+	// GetByID 获取文章详情，不增加阅读量；GetByID、View 和 List 返回的文章都附带标签和分类
+	GetByID(ctx context.Context, id int) (*models.Article, error)
+	// List 按条件查询文章列表，同时返回符合条件的总数
+	List(ctx context.Context, query ArticleQuery) ([]models.ArticleListItem, int, error)
+	// View 获取文章详情并使阅读量加一，返回的是加一之前的数据
+	View(ctx context.Context, id int) (*models.Article, error)
+	// Transition 把文章从 t.FromStatus 变更为 t.ToStatus 并记录变更，成功后回填 t.ID
+	// 变更为发布状态时把发布时间设置为 t.CreatedAt 并清空定时发布时间，下线时清空定时下线时间；
+	// 文章已不是 t.FromStatus 状态时返回 ErrArticleStatusChanged
+	Transition(ctx context.Context, t *models.ArticleTransition) error
+	// ApplySchedule 发布定时发布时间已到的审核通过文章，下线定时下线时间已到的已发布文章，每种最多处理 limit 篇
+	// 到期的文章在事务中加锁后再修改，多个实例同时执行时每篇文章只会被处理一次；返回本次的状态变更记录
+	ApplySchedule(ctx context.Context, now time.Time, limit int) ([]models.ArticleTransition, error)
+	// ListTransitions 按时间顺序返回文章的状态变更记录，附带操作人用户名
+	ListTransitions(ctx context.Context, articleID int) ([]models.ArticleTransition, error)
+	// ListRevisions 返回文章的所有版本，最新的在前，附带修改人用户名
+	ListRevisions(ctx context.Context, articleID int) ([]models.ArticleRevisionListItem, error)
+	// GetRevision 获取文章的一个版本，版本不存在或不属于该文章时返回 ErrNotFound
+	GetRevision(ctx context.Context, articleID, revisionID int) (*models.ArticleRevision, error)
+}
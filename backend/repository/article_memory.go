@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryArticleRepository struct {
+	mu               sync.RWMutex
+	nextID           int
+	articles         map[int]models.Article
+	nextTransitionID int
+	transitions      map[int][]models.ArticleTransition // 键为文章id
+	nextRevisionID   int
+	revisions        map[int][]models.ArticleRevision // 键为文章id
+	users            *memoryUserRepository            // 用于查询创建人用户名
+	tags             *memoryTermRepository            // 标签及其与文章的关联
+	categories       *memoryTermRepository            // 分类及其与文章的关联
+}
+
+func newMemoryArticleRepository(users *memoryUserRepository) *memoryArticleRepository {
+	r := &memoryArticleRepository{
+		nextID:           1,
+		articles:         map[int]models.Article{},
+		nextTransitionID: 1,
+		transitions:      map[int][]models.ArticleTransition{},
+		nextRevisionID:   1,
+		revisions:        map[int][]models.ArticleRevision{},
+		users:            users,
+		tags:             newMemoryTermRepository(),
+		categories:       newMemoryTermRepository(),
+	}
+	r.tags.articles = r
+	r.categories.articles = r
+	return r
+}
+
+// saveTerms 替换文章的标签和分类，ids 为 nil 的保持不变，然后回填 Tags 和 Categories
+func (r *memoryArticleRepository) saveTerms(article *models.Article) {
+	if article.TagIDs != nil {
+		r.tags.setLinks(article.ID, article.TagIDs)
+	}
+	if article.CategoryIDs != nil {
+		r.categories.setLinks(article.ID, article.CategoryIDs)
+	}
+	r.fillTerms(article)
+}
+
+// fillTerms 回填文章的标签和分类及其id
+func (r *memoryArticleRepository) fillTerms(article *models.Article) {
+	article.Tags, article.TagIDs = r.tags.refs(article.ID), r.tags.ids(article.ID)
+	article.Categories, article.CategoryIDs = r.categories.refs(article.ID), r.categories.ids(article.ID)
+}
+
+func (r *memoryArticleRepository) Create(_ context.Context, article *models.Article) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	article.ID = r.nextID
+	r.nextID++
+	r.articles[article.ID] = *article
+	r.addTransition(&models.ArticleTransition{
+		ArticleID: article.ID,
+		Action:    models.ArticleActionCreate,
+		ToStatus:  article.Status,
+		ActorID:   article.CreatorID,
+		CreatedAt: article.CreateTime,
+	})
+	r.addRevision(models.NewArticleRevision(article, article.CreatorID, article.CreateTime))
+	r.saveTerms(article)
+	return nil
+}
+
+// addRevision 保存一个版本，调用方需要持有写锁
+func (r *memoryArticleRepository) addRevision(v *models.ArticleRevision) {
+	v.ID = r.nextRevisionID
+	r.nextRevisionID++
+	r.revisions[v.ArticleID] = append(r.revisions[v.ArticleID], *v)
+}
+
+func (r *memoryArticleRepository) ListRevisions(_ context.Context, articleID int) ([]models.ArticleRevisionListItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := r.revisions[articleID]
+	list := make([]models.ArticleRevisionListItem, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		v := revisions[i]
+		v.Author, _ = r.users.username(v.AuthorID)
+		list = append(list, v.ListItem())
+	}
+	return list, nil
+}
+
+func (r *memoryArticleRepository) GetRevision(_ context.Context, articleID, revisionID int) (*models.ArticleRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, v := range r.revisions[articleID] {
+		if v.ID == revisionID {
+			v.Author, _ = r.users.username(v.AuthorID)
+			return &v, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// addTransition 记录一条状态变更，调用方需要持有写锁
+func (r *memoryArticleRepository) addTransition(t *models.ArticleTransition) {
+	t.ID = r.nextTransitionID
+	r.nextTransitionID++
+	r.transitions[t.ArticleID] = append(r.transitions[t.ArticleID], *t)
+}
+
+func (r *memoryArticleRepository) Transition(_ context.Context, t *models.ArticleTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	article, ok := r.articles[t.ArticleID]
+	if !ok || article.Status != t.FromStatus {
+		return ErrArticleStatusChanged
+	}
+	r.applyTransition(article, t)
+	return nil
+}
+
+// applyTransition 修改文章状态并记录变更，调用方需要持有写锁并已检查文章当前状态
+func (r *memoryArticleRepository) applyTransition(article models.Article, t *models.ArticleTransition) {
+	article.Status = t.ToStatus
+	if t.ToStatus == models.ArticleStatusPublished {
+		article.PublishedAt = t.CreatedAt
+		article.PublishAt = ""
+	}
+	if t.Action == models.ArticleActionUnpublish {
+		article.UnpublishAt = ""
+	}
+	r.articles[t.ArticleID] = article
+	r.addTransition(t)
+}
+
+func (r *memoryArticleRepository) ApplySchedule(_ context.Context, now time.Time, limit int) ([]models.ArticleTransition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at := now.Format(models.TimeLayout)
+	applied := []models.ArticleTransition{}
+	for _, schedule := range articleSchedules {
+		due := []models.Article{}
+		for _, a := range r.articles {
+			dueAt := a.PublishAt
+			if schedule.column == "unpublish_at" {
+				dueAt = a.UnpublishAt
+			}
+			if a.Status == schedule.from && dueAt != "" && dueAt <= at {
+				due = append(due, a)
+			}
+		}
+		// 按 id 顺序处理，保证每次执行的结果稳定
+		sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+		if limit > 0 && len(due) > limit {
+			due = due[:limit]
+		}
+		for _, a := range due {
+			t := models.ArticleTransition{
+				ArticleID:  a.ID,
+				Action:     schedule.action,
+				FromStatus: schedule.from,
+				ToStatus:   schedule.to,
+				Comment:    schedule.comment,
+				CreatedAt:  at,
+			}
+			r.applyTransition(a, &t)
+			applied = append(applied, t)
+		}
+	}
+	return applied, nil
+}
+
+func (r *memoryArticleRepository) ListTransitions(_ context.Context, articleID int) ([]models.ArticleTransition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]models.ArticleTransition, 0, len(r.transitions[articleID]))
+	for _, t := range r.transitions[articleID] {
+		t.Actor, _ = r.users.username(t.ActorID)
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (r *memoryArticleRepository) Update(_ context.Context, article *models.Article, revision *models.ArticleRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.articles[article.ID]
+	if !ok {
+		return nil // 与 UPDATE 语句一致，不存在时不报错
+	}
+	existing.Title = article.Title
+	existing.CoverImage = article.CoverImage
+	existing.Intro = article.Intro
+	existing.Keywords = article.Keywords
+	existing.Content = article.Content
+	existing.ContentHTML = article.ContentHTML
+	existing.TOC = article.TOC
+	existing.PublishAt = article.PublishAt
+	existing.UnpublishAt = article.UnpublishAt
+	r.articles[article.ID] = existing
+	if revision != nil {
+		r.addRevision(revision)
+	}
+	r.saveTerms(article)
+	return nil
+}
+
+func (r *memoryArticleRepository) SaveRendered(_ context.Context, article *models.Article) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.articles[article.ID]
+	if !ok || existing.Content != article.Content {
+		return nil
+	}
+	existing.ContentHTML = article.ContentHTML
+	existing.TOC = article.TOC
+	r.articles[article.ID] = existing
+	return nil
+}
+
+func (r *memoryArticleRepository) Delete(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.articles, id)
+	delete(r.transitions, id)
+	delete(r.revisions, id)
+	r.tags.removeArticle(id)
+	r.categories.removeArticle(id)
+	return nil
+}
+
+func (r *memoryArticleRepository) GetByID(_ context.Context, id int) (*models.Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	article, ok := r.articles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r.fillTerms(&article)
+	return &article, nil
+}
+
+func (r *memoryArticleRepository) List(_ context.Context, q ArticleQuery) ([]models.ArticleListItem, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	matched := []models.ArticleListItem{}
+	for _, a := range r.articles {
+		if q.Keyword != "" && !strings.Contains(a.Title, q.Keyword) && !strings.Contains(a.Intro, q.Keyword) && !strings.Contains(a.Keywords, q.Keyword) {
+			continue
+		}
+		if q.Status != "" && a.Status != q.Status {
+			continue
+		}
+		if q.CreatorID > 0 && a.CreatorID != q.CreatorID {
+			continue
+		}
+		if q.Public && !a.IsPublic(now) {
+			continue
+		}
+		if q.Tag != "" && !r.tags.hasSlug(a.ID, q.Tag) {
+			continue
+		}
+		if q.Category != "" && !r.categories.hasSlug(a.ID, q.Category) {
+			continue
+		}
+		// 与 JOIN 查询一致，创建人不存在的文章不出现在列表中
+		creator, ok := r.users.username(a.CreatorID)
+		if !ok {
+			continue
+		}
+		matched = append(matched, models.ArticleListItem{
+			ID:          a.ID,
+			Title:       a.Title,
+			Intro:       a.Intro,
+			CoverImage:  a.CoverImage,
+			Keywords:    a.Keywords,
+			Views:       a.Views,
+			CreatorID:   a.CreatorID,
+			CreateTime:  a.CreateTime,
+			Status:      a.Status,
+			PublishedAt: a.PublishedAt,
+			PublishAt:   a.PublishAt,
+			UnpublishAt: a.UnpublishAt,
+			Creator:     creator,
+			Tags:        r.tags.refs(a.ID),
+			Categories:  r.categories.refs(a.ID),
+		})
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return paginate(matched, q.Pagination), len(matched), nil
+}
+
+func (r *memoryArticleRepository) View(_ context.Context, id int) (*models.Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	article, ok := r.articles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated := article
+	updated.Views++
+	r.articles[id] = updated
+	r.fillTerms(&article)
+	return &article, nil
+}
@@ -0,0 +1,466 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlArticleRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlArticleRepository) Create(ctx context.Context, article *models.Article) error {
+	// 文章和创建记录放在同一个事务中
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO article (title,cover_image,intro,keywords,content,content_html,toc,creator_id,create_time,status,views,published_at,publish_at,unpublish_at) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	result, err := tx.ExecContext(ctx, query, article.Title, article.CoverImage, article.Intro, article.Keywords, article.Content, article.ContentHTML, article.TOC, article.CreatorID, article.CreateTime, article.Status, article.Views, article.PublishedAt, article.PublishAt, article.UnpublishAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := insertArticleTransition(ctx, tx, &models.ArticleTransition{
+		ArticleID: int(id),
+		Action:    models.ArticleActionCreate,
+		ToStatus:  article.Status,
+		ActorID:   article.CreatorID,
+		CreatedAt: article.CreateTime,
+	}); err != nil {
+		return err
+	}
+	revision := models.NewArticleRevision(article, article.CreatorID, article.CreateTime)
+	revision.ArticleID = int(id)
+	if err := insertArticleRevision(ctx, tx, revision); err != nil {
+		return err
+	}
+	if err := saveArticleTerms(ctx, tx, int(id), article); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	article.ID = int(id)
+	return nil
+}
+
+func (r *sqlArticleRepository) Update(ctx context.Context, article *models.Article, revision *models.ArticleRevision) error {
+	// 文章和新版本放在同一个事务中
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE article SET title=?,cover_image=?,intro=?,keywords=?,content=?,content_html=?,toc=?,publish_at=?,unpublish_at=? WHERE id=?"
+	if _, err := tx.ExecContext(ctx, query, article.Title, article.CoverImage, article.Intro, article.Keywords, article.Content, article.ContentHTML, article.TOC, article.PublishAt, article.UnpublishAt, article.ID); err != nil {
+		return err
+	}
+	if revision != nil {
+		if err := insertArticleRevision(ctx, tx, revision); err != nil {
+			return err
+		}
+	}
+	if err := saveArticleTerms(ctx, tx, article.ID, article); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveArticleTerms 在事务中替换文章的标签和分类，ids 为 nil 的保持不变，然后回填 Tags 和 Categories
+func saveArticleTerms(ctx context.Context, tx *sql.Tx, articleID int, article *models.Article) error {
+	if article.TagIDs != nil {
+		if err := replaceArticleTerms(ctx, tx, tagTable, articleID, article.TagIDs); err != nil {
+			return err
+		}
+	}
+	if article.CategoryIDs != nil {
+		if err := replaceArticleTerms(ctx, tx, categoryTable, articleID, article.CategoryIDs); err != nil {
+			return err
+		}
+	}
+	return fillArticleTerms(ctx, tx, articleID, article)
+}
+
+// fillArticleTerms 查询文章的标签和分类，同时回填 TagIDs 和 CategoryIDs
+func fillArticleTerms(ctx context.Context, db queryer, articleID int, article *models.Article) error {
+	tags, err := loadArticleTerms(ctx, db, tagTable, []int{articleID})
+	if err != nil {
+		return err
+	}
+	categories, err := loadArticleTerms(ctx, db, categoryTable, []int{articleID})
+	if err != nil {
+		return err
+	}
+	article.Tags, article.TagIDs = termRefs(tags[articleID])
+	article.Categories, article.CategoryIDs = termRefs(categories[articleID])
+	return nil
+}
+
+// termRefs 返回非 nil 的标签或分类列表及其id
+func termRefs(refs []models.TermRef) ([]models.TermRef, []int) {
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	if refs == nil {
+		refs = []models.TermRef{}
+	}
+	return refs, ids
+}
+
+func (r *sqlArticleRepository) SaveRendered(ctx context.Context, article *models.Article) error {
+	// 带上原文作为条件，避免覆盖同时保存的新内容的渲染结果
+	_, err := r.db.ExecContext(ctx, "UPDATE article SET content_html=?,toc=? WHERE id=? AND content=?", article.ContentHTML, article.TOC, article.ID, article.Content)
+	return err
+}
+
+// insertArticleRevision 在事务中保存一个版本，回填 revision.ID
+func insertArticleRevision(ctx context.Context, tx *sql.Tx, revision *models.ArticleRevision) error {
+	query := "INSERT INTO article_revision (article_id,title,cover_image,intro,keywords,content,author_id,restored_from,created_at) VALUES (?,?,?,?,?,?,?,?,?)"
+	result, err := tx.ExecContext(ctx, query, revision.ArticleID, revision.Title, revision.CoverImage, revision.Intro, revision.Keywords, revision.Content, revision.AuthorID, revision.RestoredFrom, revision.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	revision.ID = int(id)
+	return nil
+}
+
+// insertArticleTransition 在事务中插入一条状态变更记录，返回记录的 id
+func insertArticleTransition(ctx context.Context, tx *sql.Tx, t *models.ArticleTransition) (int, error) {
+	query := "INSERT INTO article_transition (article_id,action,from_status,to_status,actor_id,comment,created_at) VALUES (?,?,?,?,?,?,?)"
+	result, err := tx.ExecContext(ctx, query, t.ArticleID, t.Action, t.FromStatus, t.ToStatus, t.ActorID, t.Comment, t.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// updateArticleStatus 在事务中把文章从 t.FromStatus 变更为 t.ToStatus 并记录变更，回填 t.ID
+// 带上原状态作为条件，同时处理同一篇文章的请求只有一个能成功
+func updateArticleStatus(ctx context.Context, tx *sql.Tx, t *models.ArticleTransition) error {
+	set := "status=?"
+	args := []interface{}{t.ToStatus}
+	if t.ToStatus == models.ArticleStatusPublished {
+		set += ", published_at=?, publish_at=''"
+		args = append(args, t.CreatedAt)
+	}
+	if t.Action == models.ArticleActionUnpublish {
+		set += ", unpublish_at=''"
+	}
+	result, err := tx.ExecContext(ctx, "UPDATE article SET "+set+" WHERE id=? AND status=?", append(args, t.ArticleID, t.FromStatus)...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrArticleStatusChanged
+	}
+	t.ID, err = insertArticleTransition(ctx, tx, t)
+	return err
+}
+
+func (r *sqlArticleRepository) Transition(ctx context.Context, t *models.ArticleTransition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := updateArticleStatus(ctx, tx, t); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlArticleRepository) ApplySchedule(ctx context.Context, now time.Time, limit int) ([]models.ArticleTransition, error) {
+	applied := []models.ArticleTransition{}
+	for _, schedule := range articleSchedules {
+		transitions, err := r.applySchedule(ctx, schedule, now.Format(models.TimeLayout), limit)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, transitions...)
+	}
+	return applied, nil
+}
+
+// applySchedule 在一个事务中锁定并处理一种到期的定时状态变更
+func (r *sqlArticleRepository) applySchedule(ctx context.Context, schedule articleSchedule, now string, limit int) ([]models.ArticleTransition, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 其他实例已经锁定的文章直接跳过，由持有锁的实例处理
+	query := "SELECT id FROM article WHERE status=? AND " + schedule.column + "<>'' AND " + schedule.column + "<=? ORDER BY " + schedule.column + ", id LIMIT ?" + r.dialect.ForUpdateSkipLocked()
+	rows, err := tx.QueryContext(ctx, query, schedule.from, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	transitions := make([]models.ArticleTransition, 0, len(ids))
+	for _, id := range ids {
+		t := models.ArticleTransition{
+			ArticleID:  id,
+			Action:     schedule.action,
+			FromStatus: schedule.from,
+			ToStatus:   schedule.to,
+			Comment:    schedule.comment,
+			CreatedAt:  now,
+		}
+		if err := updateArticleStatus(ctx, tx, &t); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, t)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+func (r *sqlArticleRepository) ListTransitions(ctx context.Context, articleID int) ([]models.ArticleTransition, error) {
+	// 操作人可能已被删除，使用 LEFT JOIN 保留记录
+	query := "SELECT t.id,t.article_id,t.action,t.from_status,t.to_status,t.actor_id,COALESCE(user.username,''),t.comment,t.created_at FROM article_transition t LEFT JOIN user ON t.actor_id = user.id WHERE t.article_id=? ORDER BY t.id"
+	rows, err := r.db.QueryContext(ctx, query, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ArticleTransition{}
+	for rows.Next() {
+		var t models.ArticleTransition
+		if err := rows.Scan(&t.ID, &t.ArticleID, &t.Action, &t.FromStatus, &t.ToStatus, &t.ActorID, &t.Actor, &t.Comment, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r *sqlArticleRepository) ListRevisions(ctx context.Context, articleID int) ([]models.ArticleRevisionListItem, error) {
+	// 修改人可能已被删除，使用 LEFT JOIN 保留记录
+	query := "SELECT v.id,v.article_id,v.title,v.author_id,COALESCE(user.username,''),v.restored_from,v.created_at FROM article_revision v LEFT JOIN user ON v.author_id = user.id WHERE v.article_id=? ORDER BY v.id DESC"
+	rows, err := r.db.QueryContext(ctx, query, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ArticleRevisionListItem{}
+	for rows.Next() {
+		var item models.ArticleRevisionListItem
+		if err := rows.Scan(&item.ID, &item.ArticleID, &item.Title, &item.AuthorID, &item.Author, &item.RestoredFrom, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
+
+func (r *sqlArticleRepository) GetRevision(ctx context.Context, articleID, revisionID int) (*models.ArticleRevision, error) {
+	query := "SELECT v.id,v.article_id,v.title,v.cover_image,v.intro,v.keywords,v.content,v.author_id,COALESCE(user.username,''),v.restored_from,v.created_at FROM article_revision v LEFT JOIN user ON v.author_id = user.id WHERE v.id=? AND v.article_id=?"
+	var v models.ArticleRevision
+	err := r.db.QueryRowContext(ctx, query, revisionID, articleID).Scan(&v.ID, &v.ArticleID, &v.Title, &v.CoverImage, &v.Intro, &v.Keywords, &v.Content, &v.AuthorID, &v.Author, &v.RestoredFrom, &v.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &v, nil
+}
+
+func (r *sqlArticleRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM article_transition WHERE article_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM article_revision WHERE article_id=?", id); err != nil {
+		return err
+	}
+	for _, t := range []termTable{tagTable, categoryTable} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.link+" WHERE article_id=?", id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM article WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// articleFilter 构建列表查询和总数查询共用的 WHERE 条件
+func articleFilter(q ArticleQuery) (string, []interface{}) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if q.Keyword != "" {
+		where += " AND (article.title LIKE ? OR article.intro LIKE ? OR article.keywords LIKE ?)"
+		like := "%" + q.Keyword + "%"
+		args = append(args, like, like, like)
+	}
+	if q.Status != "" {
+		where += " AND article.status = ?"
+		args = append(args, q.Status)
+	}
+	if q.CreatorID > 0 {
+		where += " AND article.creator_id = ?"
+		args = append(args, q.CreatorID)
+	}
+	if q.Public {
+		where += " AND article.status = ? AND (article.unpublish_at = '' OR article.unpublish_at > ?)"
+		args = append(args, models.ArticleStatusPublished, time.Now().Format(models.TimeLayout))
+	}
+	for _, f := range []struct {
+		table termTable
+		slug  string
+	}{{tagTable, q.Tag}, {categoryTable, q.Category}} {
+		if f.slug != "" {
+			where += " AND EXISTS (SELECT 1 FROM " + f.table.link + " l JOIN " + f.table.table + " t ON t.id = l." + f.table.column + " WHERE l.article_id = article.id AND t.slug = ?)"
+			args = append(args, f.slug)
+		}
+	}
+	return where, args
+}
+
+func (r *sqlArticleRepository) List(ctx context.Context, q ArticleQuery) ([]models.ArticleListItem, int, error) {
+	where, args := articleFilter(q)
+
+	// 列表和总数使用相同的 FROM 和 JOIN，创建人不存在的文章两者都不包含
+	from := " FROM article JOIN user ON article.creator_id = user.id" + where
+
+	query := "SELECT article.id,article.title,article.intro,article.cover_image,article.keywords,article.views,article.creator_id,article.create_time,article.status,article.published_at,article.publish_at,article.unpublish_at,user.username" + from
+	query += " ORDER BY article.id DESC" // 按照 id 降序排列
+	query, listArgs := r.dialect.Paginate(query, args, q.Pagination)
+
+	rows, err := r.db.QueryContext(ctx, query, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.ArticleListItem{}
+	for rows.Next() {
+		var item models.ArticleListItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Intro, &item.CoverImage, &item.Keywords, &item.Views, &item.CreatorID, &item.CreateTime, &item.Status, &item.PublishedAt, &item.PublishAt, &item.UnpublishAt, &item.Creator); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if err := r.fillListTerms(ctx, list); err != nil {
+		return nil, 0, err
+	}
+
+	// 获取总记录数
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// fillListTerms 批量查询列表中文章的标签和分类
+func (r *sqlArticleRepository) fillListTerms(ctx context.Context, list []models.ArticleListItem) error {
+	ids := make([]int, len(list))
+	for i, item := range list {
+		ids[i] = item.ID
+	}
+	tags, err := loadArticleTerms(ctx, r.db, tagTable, ids)
+	if err != nil {
+		return err
+	}
+	categories, err := loadArticleTerms(ctx, r.db, categoryTable, ids)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		list[i].Tags, _ = termRefs(tags[list[i].ID])
+		list[i].Categories, _ = termRefs(categories[list[i].ID])
+	}
+	return nil
+}
+
+// articleColumns 文章详情查询的字段，顺序与 scanArticle 一致
+const articleColumns = "id,title,cover_image,intro,keywords,content,content_html,toc,views,creator_id,create_time,status,published_at,publish_at,unpublish_at"
+
+// scanArticle 将一行查询结果解析为文章
+func scanArticle(row *sql.Row) (*models.Article, error) {
+	var article models.Article
+	err := row.Scan(&article.ID, &article.Title, &article.CoverImage, &article.Intro, &article.Keywords, &article.Content, &article.ContentHTML, &article.TOC, &article.Views, &article.CreatorID, &article.CreateTime, &article.Status, &article.PublishedAt, &article.PublishAt, &article.UnpublishAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &article, nil
+}
+
+func (r *sqlArticleRepository) GetByID(ctx context.Context, id int) (*models.Article, error) {
+	article, err := scanArticle(r.db.QueryRowContext(ctx, "SELECT "+articleColumns+" FROM article WHERE id=?", id))
+	if err != nil {
+		return nil, err
+	}
+	if err := fillArticleTerms(ctx, r.db, id, article); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+func (r *sqlArticleRepository) View(ctx context.Context, id int) (*models.Article, error) {
+	// 查询和更新阅读量放在同一个事务中
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	article, err := scanArticle(tx.QueryRowContext(ctx, "SELECT "+articleColumns+" FROM article WHERE id=?", id))
+	if err != nil {
+		return nil, err
+	}
+	if err := fillArticleTerms(ctx, tx, id, article); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE article SET views = views + 1 WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
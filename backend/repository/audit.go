@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// AuditQuery 审计日志查询条件
+type AuditQuery struct {
+	Pagination
+	Action string
+	Target string
+}
+
+// AuditRepository 审计日志数据访问接口，日志只能新增不能修改
+type AuditRepository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	// List 按条件查询审计日志，按时间倒序排列，同时返回符合条件的总数
+	List(ctx context.Context, query AuditQuery) ([]models.AuditLog, int, error)
+}
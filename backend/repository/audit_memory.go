@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sync"
+)
+
+type memoryAuditRepository struct {
+	mu   sync.RWMutex
+	logs []models.AuditLog // 按 id 升序追加
+}
+
+func newMemoryAuditRepository() *memoryAuditRepository {
+	return &memoryAuditRepository{}
+}
+
+func (r *memoryAuditRepository) Create(_ context.Context, log *models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log.ID = len(r.logs) + 1
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *memoryAuditRepository) List(_ context.Context, q AuditQuery) ([]models.AuditLog, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []models.AuditLog{}
+	for i := len(r.logs) - 1; i >= 0; i-- {
+		log := r.logs[i]
+		if q.Action != "" && log.Action != q.Action {
+			continue
+		}
+		if q.Target != "" && log.Target != q.Target {
+			continue
+		}
+		matched = append(matched, log)
+	}
+	return paginate(matched, q.Pagination), len(matched), nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+)
+
+type sqlAuditRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlAuditRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	query := "INSERT INTO audit_log (action, actor_id, target, ip, detail, created_at) VALUES (?,?,?,?,?,?)"
+	result, err := r.db.ExecContext(ctx, query, log.Action, log.ActorID, log.Target, log.IP, log.Detail, log.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	log.ID = int(id)
+	return nil
+}
+
+func (r *sqlAuditRepository) List(ctx context.Context, q AuditQuery) ([]models.AuditLog, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if q.Action != "" {
+		where += " AND action = ?"
+		args = append(args, q.Action)
+	}
+	if q.Target != "" {
+		where += " AND target = ?"
+		args = append(args, q.Target)
+	}
+
+	query := "SELECT id, action, actor_id, target, ip, detail, created_at FROM audit_log" + where + " ORDER BY id DESC"
+	query, listArgs := r.dialect.Paginate(query, args, q.Pagination)
+	rows, err := r.db.QueryContext(ctx, query, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		var log models.AuditLog
+		if err := rows.Scan(&log.ID, &log.Action, &log.ActorID, &log.Target, &log.IP, &log.Detail, &log.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import "fmt"
+
+// Dialect 描述不同数据库之间的 SQL 语法差异，SQL 仓库通过它生成对应方言的语句
+type Dialect string
+
+const (
+	MySQL  Dialect = "mysql"
+	SQLite Dialect = "sqlite"
+)
+
+// DialectFor 根据配置中的驱动名称返回对应的方言
+func DialectFor(driver string) (Dialect, error) {
+	switch Dialect(driver) {
+	case MySQL, SQLite:
+		return Dialect(driver), nil
+	default:
+		return "", fmt.Errorf("不支持的数据库方言: %s", driver)
+	}
+}
+
+// IfNull 返回 "expr 为 NULL 时取 fallback" 的表达式
+// MySQL 使用 IFNULL，SQLite 使用标准的 COALESCE
+func (d Dialect) IfNull(expr, fallback string) string {
+	if d == MySQL {
+		return fmt.Sprintf("IFNULL(%s, %s)", expr, fallback)
+	}
+	return fmt.Sprintf("COALESCE(%s, %s)", expr, fallback)
+}
+
+// InsertIgnore 返回主键冲突时忽略该行的 INSERT 语句开头
+// MySQL 使用 INSERT IGNORE，SQLite 使用 INSERT OR IGNORE
+func (d Dialect) InsertIgnore(table string) string {
+	if d == MySQL {
+		return "INSERT IGNORE INTO " + table
+	}
+	return "INSERT OR IGNORE INTO " + table
+}
+
+// ForUpdateSkipLocked 返回锁定查询到的行并跳过已被其他事务锁定的行的子句
+// MySQL 8 使用 FOR UPDATE SKIP LOCKED；SQLite 的写事务本身是串行的，不需要行锁，返回空字符串
+func (d Dialect) ForUpdateSkipLocked() string {
+	if d == MySQL {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return ""
+}
+
+// Paginate 在查询末尾追加分页子句，返回新的查询语句和参数
+// 不修改传入的 args，列表查询和总数查询可以共用同一组条件参数
+// MySQL 和 SQLite 都支持 LIMIT ? OFFSET ?，但 SQLite 把负数 LIMIT 当作不限制，
+// 而 MySQL 会报错，因此这里统一把负数修正为 0
+func (d Dialect) Paginate(query string, args []interface{}, p Pagination) (string, []interface{}) {
+	if !p.Enabled() {
+		return query, args
+	}
+	limit, offset := p.LimitOffset()
+	if limit < 0 {
+		limit = 0
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return query + " LIMIT ? OFFSET ?", append(append([]interface{}{}, args...), limit, offset)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// PasswordHistoryRepository 用户之前使用过的密码哈希，用于禁止重复使用最近的密码
+type PasswordHistoryRepository interface {
+	// Add 记录一个被替换掉的密码哈希
+	Add(ctx context.Context, userID int, hash string, at string) error
+	// ListRecent 按时间倒序返回最近的 n 个密码哈希
+	ListRecent(ctx context.Context, userID int, n int) ([]string, error)
+	// Prune 只保留最近的 keep 条记录
+	Prune(ctx context.Context, userID int, keep int) error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryPasswordHistoryRepository struct {
+	mu     sync.Mutex
+	hashes map[int][]string // 按时间升序追加
+}
+
+func newMemoryPasswordHistoryRepository() *memoryPasswordHistoryRepository {
+	return &memoryPasswordHistoryRepository{hashes: map[int][]string{}}
+}
+
+func (r *memoryPasswordHistoryRepository) Add(_ context.Context, userID int, hash string, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hashes[userID] = append(r.hashes[userID], hash)
+	return nil
+}
+
+func (r *memoryPasswordHistoryRepository) ListRecent(_ context.Context, userID int, n int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := r.hashes[userID]
+	recent := []string{}
+	for i := len(all) - 1; i >= 0 && len(recent) < n; i-- {
+		recent = append(recent, all[i])
+	}
+	return recent, nil
+}
+
+func (r *memoryPasswordHistoryRepository) Prune(_ context.Context, userID int, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if all := r.hashes[userID]; len(all) > keep {
+		r.hashes[userID] = append([]string{}, all[len(all)-keep:]...)
+	}
+	return nil
+}
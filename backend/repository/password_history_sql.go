@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type sqlPasswordHistoryRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlPasswordHistoryRepository) Add(ctx context.Context, userID int, hash string, at string) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO password_history (user_id, password_hash, created_at) VALUES (?,?,?)", userID, hash, at)
+	return err
+}
+
+func (r *sqlPasswordHistoryRepository) ListRecent(ctx context.Context, userID int, n int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT password_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?", userID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+func (r *sqlPasswordHistoryRepository) Prune(ctx context.Context, userID int, keep int) error {
+	// MySQL 不支持在 IN 子查询中直接使用 LIMIT，需要再包一层派生表
+	query := "DELETE FROM password_history WHERE user_id = ? AND id NOT IN (" +
+		"SELECT id FROM (SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?) recent)"
+	_, err := r.db.ExecContext(ctx, query, userID, userID, keep)
+	return err
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// ProjectQuery 项目列表查询条件
+type ProjectQuery struct {
+	Pagination
+	ProjectName string // 模糊匹配项目名称
+}
+
+// ProjectRepository 项目数据访问接口
+type ProjectRepository interface {
+	// Create 新增项目，成功后回填 project.ID
+	Create(ctx context.Context, project *models.Project) error
+	Update(ctx context.Context, project *models.Project) error
+	Delete(ctx context.Context, id int) error
+	GetByID(ctx context.Context, id int) (*models.Project, error)
+	// List 按条件查询项目列表，同时返回符合条件的总数
+	List(ctx context.Context, query ProjectQuery) ([]models.Project, int, error)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memoryProjectRepository struct {
+	mu       sync.RWMutex
+	nextID   int
+	projects map[int]models.Project
+}
+
+func newMemoryProjectRepository() *memoryProjectRepository {
+	return &memoryProjectRepository{nextID: 1, projects: map[int]models.Project{}}
+}
+
+func (r *memoryProjectRepository) Create(_ context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	project.ID = r.nextID
+	r.nextID++
+	r.projects[project.ID] = *project
+	return nil
+}
+
+func (r *memoryProjectRepository) Update(_ context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.projects[project.ID]; ok {
+		r.projects[project.ID] = *project
+	}
+	return nil
+}
+
+func (r *memoryProjectRepository) Delete(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.projects, id)
+	return nil
+}
+
+func (r *memoryProjectRepository) GetByID(_ context.Context, id int) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &project, nil
+}
+
+func (r *memoryProjectRepository) List(_ context.Context, q ProjectQuery) ([]models.Project, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []models.Project{}
+	for _, project := range r.projects {
+		if q.ProjectName != "" && !strings.Contains(project.ProjectName, q.ProjectName) {
+			continue
+		}
+		matched = append(matched, project)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return paginate(matched, q.Pagination), len(matched), nil
+}
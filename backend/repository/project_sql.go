@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+)
+
+type sqlProjectRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	query := "INSERT INTO project (project_name, description, logo, url) VALUES (?,?,?,?)"
+	result, err := r.db.ExecContext(ctx, query, project.ProjectName, project.Description, project.Logo, project.Url)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	project.ID = int(id)
+	return nil
+}
+
+func (r *sqlProjectRepository) Update(ctx context.Context, project *models.Project) error {
+	query := "UPDATE project SET project_name=?,description=?,logo=?,url=? WHERE id=?"
+	_, err := r.db.ExecContext(ctx, query, project.ProjectName, project.Description, project.Logo, project.Url, project.ID)
+	return err
+}
+
+func (r *sqlProjectRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM project WHERE id=?", id)
+	return err
+}
+
+func (r *sqlProjectRepository) GetByID(ctx context.Context, id int) (*models.Project, error) {
+	var project models.Project
+	query := "SELECT id, project_name, description, logo, url FROM project WHERE id=?"
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&project.ID, &project.ProjectName, &project.Description, &project.Logo, &project.Url)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &project, nil
+}
+
+func (r *sqlProjectRepository) List(ctx context.Context, q ProjectQuery) ([]models.Project, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if q.ProjectName != "" {
+		where += " AND project_name LIKE ?"
+		args = append(args, "%"+q.ProjectName+"%")
+	}
+
+	query := "SELECT id, project_name, description, logo, url FROM project" + where
+	query += " ORDER BY id DESC" // 按照 id 降序排列
+	query, listArgs := r.dialect.Paginate(query, args, q.Pagination)
+
+	rows, err := r.db.QueryContext(ctx, query, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		var project models.Project
+		if err := rows.Scan(&project.ID, &project.ProjectName, &project.Description, &project.Logo, &project.Url); err != nil {
+			return nil, 0, err
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// 获取总记录数
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM project"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return projects, total, nil
+}
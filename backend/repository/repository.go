@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrNotFound 查询的记录不存在
+var ErrNotFound = errors.New("记录不存在")
+
+// Repositories 汇总所有数据仓库，由 main 创建后注入到路由和控制器中
+type Repositories struct {
+	Articles        ArticleRepository
+	Users           UserRepository
+	Projects        ProjectRepository
+	Tokens          TokenRepository
+	Audit           AuditRepository
+	TwoFactor       TwoFactorRepository
+	PasswordHistory PasswordHistoryRepository
+	Tags            TermRepository
+	Categories      TermRepository
+}
+
+// NewSQL 创建基于 SQL 数据库（MySQL 或 SQLite）的数据仓库，dialect 决定生成的 SQL 方言
+func NewSQL(db *sql.DB, dialect Dialect) *Repositories {
+	return &Repositories{
+		Articles:        &sqlArticleRepository{db: db, dialect: dialect},
+		Users:           &sqlUserRepository{db: db, dialect: dialect},
+		Projects:        &sqlProjectRepository{db: db, dialect: dialect},
+		Tokens:          &sqlTokenRepository{db: db, dialect: dialect},
+		Audit:           &sqlAuditRepository{db: db, dialect: dialect},
+		TwoFactor:       &sqlTwoFactorRepository{db: db, dialect: dialect},
+		PasswordHistory: &sqlPasswordHistoryRepository{db: db, dialect: dialect},
+		Tags:            &sqlTermRepository{db: db, dialect: dialect, termTable: tagTable},
+		Categories:      &sqlTermRepository{db: db, dialect: dialect, termTable: categoryTable},
+	}
+}
+
+// NewMemory 创建基于内存的数据仓库，数据不会持久化，适合单元测试和本地演示
+func NewMemory() *Repositories {
+	users := newMemoryUserRepository()
+	articles := newMemoryArticleRepository(users)
+	return &Repositories{
+		Articles:        articles,
+		Users:           users,
+		Projects:        newMemoryProjectRepository(),
+		Tokens:          newMemoryTokenRepository(),
+		Audit:           newMemoryAuditRepository(),
+		TwoFactor:       newMemoryTwoFactorRepository(),
+		PasswordHistory: newMemoryPasswordHistoryRepository(),
+		Tags:            articles.tags,
+		Categories:      articles.categories,
+	}
+}
+
+// Pagination 分页参数，PageNum 或 PageSize 为空时返回全部数据
+type Pagination struct {
+	PageNum  *int
+	PageSize *int
+}
+
+// Enabled 判断是否需要分页
+func (p Pagination) Enabled() bool {
+	return p.PageNum != nil && p.PageSize != nil
+}
+
+// LimitOffset 返回 LIMIT 和 OFFSET 的值
+func (p Pagination) LimitOffset() (limit, offset int) {
+	return *p.PageSize, (*p.PageNum - 1) * *p.PageSize
+}
+
+// paginate 对内存中的切片进行分页
+func paginate[T any](items []T, p Pagination) []T {
+	if !p.Enabled() {
+		return items
+	}
+	limit, offset := p.LimitOffset()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if limit < 0 || end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+// notFound 将 sql.ErrNoRows 转换为 ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
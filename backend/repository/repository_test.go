@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	cases := []struct {
+		name string
+		p    Pagination
+		want []int
+	}{
+		{"不分页", Pagination{}, []int{1, 2, 3, 4, 5}},
+		{"第一页", Pagination{PageNum: intPtr(1), PageSize: intPtr(2)}, []int{1, 2}},
+		{"最后一页不满", Pagination{PageNum: intPtr(3), PageSize: intPtr(2)}, []int{5}},
+		{"超出范围", Pagination{PageNum: intPtr(4), PageSize: intPtr(2)}, []int{}},
+		{"页码小于 1", Pagination{PageNum: intPtr(0), PageSize: intPtr(2)}, []int{1, 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := paginate(items, tc.p); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("paginate() = %v，期望 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRepositories(t *testing.T) {
+	runRepositoryTests(t, NewMemory)
+}
+
+// runRepositoryTests 对不同的存储实现执行同一组测试，newRepos 每次返回一份空的数据仓库
+func runRepositoryTests(t *testing.T, newRepos func() *Repositories) {
+	t.Run("Users", func(t *testing.T) { testUserRepository(t, newRepos()) })
+	t.Run("Articles", func(t *testing.T) { testArticleRepository(t, newRepos()) })
+	t.Run("ArticleSchedule", func(t *testing.T) { testArticleSchedule(t, newRepos()) })
+	t.Run("Terms", func(t *testing.T) { testTermRepository(t, newRepos()) })
+}
+
+func createTestUser(t *testing.T, repos *Repositories, username string) *models.User {
+	t.Helper()
+	user := &models.User{
+		Username:     username,
+		Password:     "hash-" + username,
+		Email:        username + "@example.com",
+		RegisterTime: "2024-01-01 00:00:00",
+		Status:       models.UserStatusNormal,
+		Role:         models.RoleAuthor,
+	}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("创建用户 %s 出错: %v", username, err)
+	}
+	if user.ID == 0 {
+		t.Fatalf("创建用户 %s 后没有回填 id", username)
+	}
+	return user
+}
+
+func testUserRepository(t *testing.T, repos *Repositories) {
+	ctx := context.Background()
+	alice := createTestUser(t, repos, "alice")
+	createTestUser(t, repos, "bob")
+	createTestUser(t, repos, "carol")
+
+	got, err := repos.Users.GetByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("GetByID() 出错: %v", err)
+	}
+	if got.Username != "alice" || got.Role != models.RoleAuthor {
+		t.Errorf("GetByID() = %+v，期望 alice/author", got)
+	}
+
+	byName, err := repos.Users.GetByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetByUsername() 出错: %v", err)
+	}
+	if byName.Password != "hash-alice" {
+		t.Errorf("GetByUsername() 返回的密码为 %q，期望加密后的密码", byName.Password)
+	}
+
+	if _, err := repos.Users.GetByID(ctx, 9999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("查询不存在的用户返回 %v，期望 ErrNotFound", err)
+	}
+	if exists, err := repos.Users.UsernameExists(ctx, "bob"); err != nil || !exists {
+		t.Errorf("UsernameExists(bob) = %v, %v，期望 true", exists, err)
+	}
+	if exists, err := repos.Users.UsernameExists(ctx, "dave"); err != nil || exists {
+		t.Errorf("UsernameExists(dave) = %v, %v，期望 false", exists, err)
+	}
+
+	users, total, err := repos.Users.List(ctx, UserQuery{Pagination: Pagination{PageNum: intPtr(1), PageSize: intPtr(2)}})
+	if err != nil {
+		t.Fatalf("List() 出错: %v", err)
+	}
+	if total != 3 || len(users) != 2 {
+		t.Errorf("List() 返回 %d 条，总数 %d，期望 2 条，总数 3", len(users), total)
+	}
+	if _, total, _ := repos.Users.List(ctx, UserQuery{Username: "ar"}); total != 1 {
+		t.Errorf("按用户名模糊查询的总数为 %d，期望 1", total)
+	}
+
+	if err := repos.Users.UpdatePassword(ctx, alice.ID, "new-hash", true); err != nil {
+		t.Fatalf("UpdatePassword() 出错: %v", err)
+	}
+	if hash, _ := repos.Users.GetPasswordHash(ctx, alice.ID); hash != "new-hash" {
+		t.Errorf("修改后的密码为 %q，期望 %q", hash, "new-hash")
+	}
+	if got, _ := repos.Users.GetByID(ctx, alice.ID); !got.MustChangePassword {
+		t.Error("UpdatePassword(mustChange=true) 之后 MustChangePassword 应为 true")
+	}
+
+	before, _ := repos.Users.GetByID(ctx, alice.ID)
+	if err := repos.Users.IncrementTokenVersion(ctx, alice.ID); err != nil {
+		t.Fatalf("IncrementTokenVersion() 出错: %v", err)
+	}
+	if after, _ := repos.Users.GetByID(ctx, alice.ID); after.TokenVersion != before.TokenVersion+1 {
+		t.Errorf("令牌版本 = %d，期望 %d", after.TokenVersion, before.TokenVersion+1)
+	}
+}
+
+func testArticleRepository(t *testing.T, repos *Repositories) {
+	ctx := context.Background()
+	author := createTestUser(t, repos, "author")
+
+	article := &models.Article{
+		Title:      "Hello",
+		Content:    "# Hello",
+		CreatorID:  author.ID,
+		CreateTime: "2024-01-01 00:00:00",
+		Status:     models.ArticleStatusDraft,
+	}
+	if err := repos.Articles.Create(ctx, article); err != nil {
+		t.Fatalf("Create() 出错: %v", err)
+	}
+	if article.ID == 0 {
+		t.Fatal("创建文章后没有回填 id")
+	}
+
+	viewed, err := repos.Articles.View(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("View() 出错: %v", err)
+	}
+	if viewed.Views != 0 {
+		t.Errorf("View() 返回阅读量 %d，期望加一之前的 0", viewed.Views)
+	}
+	got, err := repos.Articles.GetByID(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("GetByID() 出错: %v", err)
+	}
+	if got.Views != 1 || got.Title != "Hello" {
+		t.Errorf("GetByID() = %q（阅读量 %d），期望 Hello（阅读量 1）", got.Title, got.Views)
+	}
+
+	got.Title = "Hello again"
+	if err := repos.Articles.Update(ctx, got, nil); err != nil {
+		t.Fatalf("Update() 出错: %v", err)
+	}
+	if got, _ := repos.Articles.GetByID(ctx, article.ID); got.Title != "Hello again" {
+		t.Errorf("修改后的标题为 %q，期望 %q", got.Title, "Hello again")
+	}
+
+	submit := &models.ArticleTransition{
+		ArticleID:  article.ID,
+		Action:     models.ArticleActionSubmit,
+		FromStatus: models.ArticleStatusDraft,
+		ToStatus:   models.ArticleStatusSubmitted,
+		ActorID:    author.ID,
+		CreatedAt:  "2024-01-02 00:00:00",
+	}
+	if err := repos.Articles.Transition(ctx, submit); err != nil {
+		t.Fatalf("Transition() 出错: %v", err)
+	}
+	// 状态已经变化，再次按草稿状态变更会失败
+	stale := *submit
+	stale.ID = 0
+	if err := repos.Articles.Transition(ctx, &stale); !errors.Is(err, ErrArticleStatusChanged) {
+		t.Errorf("重复变更状态返回 %v，期望 ErrArticleStatusChanged", err)
+	}
+	transitions, err := repos.Articles.ListTransitions(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("ListTransitions() 出错: %v", err)
+	}
+	if len(transitions) != 2 || transitions[0].Action != models.ArticleActionCreate || transitions[1].Actor != "author" {
+		t.Errorf("ListTransitions() = %+v，期望 create 和 submit 两条记录", transitions)
+	}
+
+	items, total, err := repos.Articles.List(ctx, ArticleQuery{CreatorID: author.ID})
+	if err != nil {
+		t.Fatalf("List() 出错: %v", err)
+	}
+	if total != 1 || len(items) != 1 {
+		t.Errorf("List() 返回 %d 条，总数 %d，期望 1 条", len(items), total)
+	}
+	if _, total, _ := repos.Articles.List(ctx, ArticleQuery{Public: true}); total != 0 {
+		t.Errorf("未发布的文章出现在公开列表中")
+	}
+
+	if err := repos.Articles.Delete(ctx, article.ID); err != nil {
+		t.Fatalf("Delete() 出错: %v", err)
+	}
+	if _, err := repos.Articles.GetByID(ctx, article.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除后查询返回 %v，期望 ErrNotFound", err)
+	}
+}
+
+func testArticleSchedule(t *testing.T, repos *Repositories) {
+	ctx := context.Background()
+	author := createTestUser(t, repos, "author")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	past := now.Add(-time.Minute).Format(models.TimeLayout)
+	future := now.Add(time.Hour).Format(models.TimeLayout)
+
+	create := func(status, publishAt, unpublishAt string) int {
+		t.Helper()
+		article := &models.Article{
+			Title:       "定时",
+			CreatorID:   author.ID,
+			CreateTime:  "2024-01-01 00:00:00",
+			Status:      status,
+			PublishAt:   publishAt,
+			UnpublishAt: unpublishAt,
+		}
+		if err := repos.Articles.Create(ctx, article); err != nil {
+			t.Fatal(err)
+		}
+		return article.ID
+	}
+	due := create(models.ArticleStatusApproved, past, "")
+	notDue := create(models.ArticleStatusApproved, future, "")
+	draft := create(models.ArticleStatusDraft, past, "") // 未审核通过的文章到期也不发布
+	expired := create(models.ArticleStatusPublished, "", past)
+
+	transitions, err := repos.Articles.ApplySchedule(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("ApplySchedule() 出错: %v", err)
+	}
+	applied := map[int]string{}
+	for _, tr := range transitions {
+		applied[tr.ArticleID] = tr.Action
+		if tr.ActorID != 0 {
+			t.Errorf("定时任务的操作人为 %d，期望 0", tr.ActorID)
+		}
+	}
+	want := map[int]string{due: models.ArticleActionPublish, expired: models.ArticleActionUnpublish}
+	if !reflect.DeepEqual(applied, want) {
+		t.Fatalf("ApplySchedule() 处理了 %v，期望 %v", applied, want)
+	}
+
+	published, _ := repos.Articles.GetByID(ctx, due)
+	if published.Status != models.ArticleStatusPublished || published.PublishAt != "" || published.PublishedAt != now.Format(models.TimeLayout) {
+		t.Errorf("定时发布后 状态=%s 定时=%q 发布时间=%q", published.Status, published.PublishAt, published.PublishedAt)
+	}
+	archived, _ := repos.Articles.GetByID(ctx, expired)
+	if archived.Status != models.ArticleStatusArchived || archived.UnpublishAt != "" {
+		t.Errorf("定时下线后 状态=%s 定时=%q", archived.Status, archived.UnpublishAt)
+	}
+	for _, id := range []int{notDue, draft} {
+		if a, _ := repos.Articles.GetByID(ctx, id); a.Status == models.ArticleStatusPublished {
+			t.Errorf("文章 %d 不应该被发布", id)
+		}
+	}
+
+	// 已处理的文章不会被重复处理
+	if transitions, err := repos.Articles.ApplySchedule(ctx, now, 10); err != nil || len(transitions) != 0 {
+		t.Fatalf("再次执行 ApplySchedule() = %v, %v，期望没有变更", transitions, err)
+	}
+}
+
+func testTermRepository(t *testing.T, repos *Repositories) {
+	ctx := context.Background()
+	golang := &models.Term{Name: "Go", Slug: "golang"}
+	if err := repos.Tags.Create(ctx, golang); err != nil {
+		t.Fatalf("Create() 出错: %v", err)
+	}
+	rust := &models.Term{Name: "Rust", Slug: "rust"}
+	if err := repos.Tags.Create(ctx, rust); err != nil {
+		t.Fatalf("Create() 出错: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		term models.Term
+		want string
+	}{
+		{"名称重复（不区分大小写）", models.Term{Name: "GO", Slug: "go-lang"}, "name"},
+		{"别名重复", models.Term{Name: "Golang", Slug: "golang"}, "slug"},
+		{"不重复", models.Term{Name: "Python", Slug: "python"}, ""},
+		{"修改自己时不算重复", models.Term{ID: golang.ID, Name: "go", Slug: "golang"}, ""},
+		{"改成其他记录的别名", models.Term{ID: golang.ID, Name: "Go", Slug: "rust"}, "slug"},
+	}
+	for _, tc := range cases {
+		if got, err := repos.Tags.Exists(ctx, &tc.term); err != nil || got != tc.want {
+			t.Errorf("%s: Exists() = %q, %v，期望 %q", tc.name, got, err, tc.want)
+		}
+	}
+	// 标签和分类互不影响
+	if got, _ := repos.Categories.Exists(ctx, &models.Term{Name: "Go", Slug: "golang"}); got != "" {
+		t.Errorf("分类中 Exists() = %q，期望不与标签冲突", got)
+	}
+
+	if missing, err := repos.Tags.Missing(ctx, []int{golang.ID, 999}); err != nil || !reflect.DeepEqual(missing, []int{999}) {
+		t.Errorf("Missing() = %v, %v，期望 [999]", missing, err)
+	}
+
+	// 删除标签后解除与文章的关联，文章本身不受影响
+	author := createTestUser(t, repos, "author")
+	article := &models.Article{
+		Title:      "标签",
+		CreatorID:  author.ID,
+		CreateTime: "2024-01-01 00:00:00",
+		Status:     models.ArticleStatusDraft,
+		TagIDs:     []int{golang.ID, rust.ID},
+	}
+	if err := repos.Articles.Create(ctx, article); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Tags.Delete(ctx, golang.ID); err != nil {
+		t.Fatalf("Delete() 出错: %v", err)
+	}
+	got, err := repos.Articles.GetByID(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("删除标签后查询文章出错: %v", err)
+	}
+	if len(got.Tags) != 1 || got.Tags[0].Slug != "rust" {
+		t.Errorf("删除标签后文章的标签 = %+v，期望只剩 rust", got.Tags)
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"backend/migrations"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite 在临时目录中创建 SQLite 数据库并执行全部迁移
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "blog.db") + "?_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, string(SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("执行迁移出错: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteRepositories(t *testing.T) {
+	runRepositoryTests(t, func() *Repositories {
+		return NewSQL(openSQLite(t), SQLite)
+	})
+}
+
+func TestDialectFor(t *testing.T) {
+	for _, driver := range []string{"mysql", "sqlite"} {
+		if d, err := DialectFor(driver); err != nil || string(d) != driver {
+			t.Errorf("DialectFor(%q) = %q, %v", driver, d, err)
+		}
+	}
+	if _, err := DialectFor("memory"); err == nil {
+		t.Error("DialectFor(memory) 应该返回错误")
+	}
+}
+
+func TestDialectSyntax(t *testing.T) {
+	cases := []struct {
+		name   string
+		got    string
+		expect string
+	}{
+		{"MySQL IfNull", MySQL.IfNull("?", "title"), "IFNULL(?, title)"},
+		{"SQLite IfNull", SQLite.IfNull("?", "title"), "COALESCE(?, title)"},
+		{"MySQL InsertIgnore", MySQL.InsertIgnore("article_tag"), "INSERT IGNORE INTO article_tag"},
+		{"SQLite InsertIgnore", SQLite.InsertIgnore("article_tag"), "INSERT OR IGNORE INTO article_tag"},
+		{"MySQL 行锁", MySQL.ForUpdateSkipLocked(), " FOR UPDATE SKIP LOCKED"},
+		{"SQLite 行锁", SQLite.ForUpdateSkipLocked(), ""},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.expect {
+			t.Errorf("%s = %q，期望 %q", tc.name, tc.got, tc.expect)
+		}
+	}
+}
+
+func TestDialectPaginate(t *testing.T) {
+	args := []interface{}{"keyword"}
+	query, got := SQLite.Paginate("SELECT * FROM article WHERE title LIKE ?", args, Pagination{PageNum: intPtr(3), PageSize: intPtr(10)})
+	if query != "SELECT * FROM article WHERE title LIKE ? LIMIT ? OFFSET ?" {
+		t.Errorf("query = %q", query)
+	}
+	if want := []interface{}{"keyword", 10, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %v，期望 %v", got, want)
+	}
+	// 不修改传入的参数，总数查询可以继续使用
+	if len(args) != 1 {
+		t.Errorf("Paginate 修改了传入的参数: %v", args)
+	}
+
+	// 负数的 LIMIT 和 OFFSET 修正为 0
+	_, got = MySQL.Paginate("SELECT 1", nil, Pagination{PageNum: intPtr(2), PageSize: intPtr(-5)})
+	if fmt.Sprint(got) != "[0 0]" {
+		t.Errorf("负数分页参数 = %v，期望 [0 0]", got)
+	}
+}
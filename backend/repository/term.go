@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// TermQuery 标签或分类列表查询条件
+type TermQuery struct {
+	Pagination
+	Name string // 模糊匹配名称或别名
+}
+
+// TermRepository 标签或分类的数据访问接口，标签和分类各有一个实例
+type TermRepository interface {
+	// Create 新增标签或分类，成功后回填 term.ID
+	Create(ctx context.Context, term *models.Term) error
+	Update(ctx context.Context, term *models.Term) error
+	// Delete 删除标签或分类，同时解除与文章的关联，文章本身不受影响
+	Delete(ctx context.Context, id int) error
+	GetByID(ctx context.Context, id int) (*models.Term, error)
+	// List 按条件查询列表，同时返回符合条件的总数，每一项附带已发布的文章数
+	List(ctx context.Context, query TermQuery) ([]models.Term, int, error)
+	// Exists 判断除 term.ID 以外是否已有同名（不区分大小写）或同别名的记录，返回冲突的字段 name 或 slug
+	Exists(ctx context.Context, term *models.Term) (string, error)
+	// Missing 返回 ids 中不存在的 id，保存文章前用于检查
+	Missing(ctx context.Context, ids []int) ([]int, error)
+}
+
+// termTable 标签或分类的表名和与文章的关联表
+type termTable struct {
+	table  string // tag 或 category
+	link   string // article_tag 或 article_category
+	column string // 关联表中指向 table 的字段
+}
+
+var (
+	tagTable      = termTable{"tag", "article_tag", "tag_id"}
+	categoryTable = termTable{"category", "article_category", "category_id"}
+)
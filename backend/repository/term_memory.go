@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryTermRepository 同时保存标签或分类以及它们与文章的关联
+// 文章仓库持有锁时会调用这里的方法，反过来这里调用文章仓库前必须先释放自己的锁，避免死锁
+type memoryTermRepository struct {
+	mu       sync.RWMutex
+	nextID   int
+	terms    map[int]models.Term
+	links    map[int][]int            // 键为文章id，值为按 id 排序的标签或分类id
+	articles *memoryArticleRepository // 用于统计已发布的文章数
+}
+
+func newMemoryTermRepository() *memoryTermRepository {
+	return &memoryTermRepository{
+		nextID: 1,
+		terms:  map[int]models.Term{},
+		links:  map[int][]int{},
+	}
+}
+
+func (r *memoryTermRepository) Create(_ context.Context, term *models.Term) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	term.ID = r.nextID
+	r.nextID++
+	stored := *term
+	stored.ArticleCount = 0
+	r.terms[term.ID] = stored
+	return nil
+}
+
+func (r *memoryTermRepository) Update(_ context.Context, term *models.Term) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.terms[term.ID]; !ok {
+		return nil // 与 UPDATE 语句一致，不存在时不报错
+	}
+	stored := *term
+	stored.ArticleCount = 0
+	r.terms[term.ID] = stored
+	return nil
+}
+
+func (r *memoryTermRepository) Delete(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.terms, id)
+	for articleID, ids := range r.links {
+		r.links[articleID] = slices.DeleteFunc(ids, func(termID int) bool { return termID == id })
+	}
+	return nil
+}
+
+func (r *memoryTermRepository) GetByID(_ context.Context, id int) (*models.Term, error) {
+	r.mu.RLock()
+	term, ok := r.terms[id]
+	links := r.articleIDs()
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	term.ArticleCount = r.countPublished(links[id])
+	return &term, nil
+}
+
+func (r *memoryTermRepository) List(_ context.Context, q TermQuery) ([]models.Term, int, error) {
+	r.mu.RLock()
+	matched := []models.Term{}
+	for _, term := range r.terms {
+		if q.Name != "" && !strings.Contains(term.Name, q.Name) && !strings.Contains(term.Slug, q.Name) {
+			continue
+		}
+		matched = append(matched, term)
+	}
+	links := r.articleIDs()
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	page := paginate(matched, q.Pagination)
+	for i := range page {
+		page[i].ArticleCount = r.countPublished(links[page[i].ID])
+	}
+	return page, len(matched), nil
+}
+
+// articleIDs 返回以标签或分类id为键的文章id，调用方需要持有读锁
+func (r *memoryTermRepository) articleIDs() map[int][]int {
+	result := map[int][]int{}
+	for articleID, ids := range r.links {
+		for _, id := range ids {
+			result[id] = append(result[id], articleID)
+		}
+	}
+	return result
+}
+
+// countPublished 统计已发布的文章数，调用方不能持有锁
+func (r *memoryTermRepository) countPublished(articleIDs []int) int {
+	r.articles.mu.RLock()
+	defer r.articles.mu.RUnlock()
+	count := 0
+	for _, id := range articleIDs {
+		if a, ok := r.articles.articles[id]; ok && a.Status == models.ArticleStatusPublished {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *memoryTermRepository) Exists(_ context.Context, term *models.Term) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	// 与数据库一致，名称不区分大小写
+	for _, t := range r.terms {
+		if t.ID != term.ID && strings.EqualFold(t.Name, term.Name) {
+			return "name", nil
+		}
+	}
+	for _, t := range r.terms {
+		if t.ID != term.ID && t.Slug == term.Slug {
+			return "slug", nil
+		}
+	}
+	return "", nil
+}
+
+func (r *memoryTermRepository) Missing(_ context.Context, ids []int) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var missing []int
+	for _, id := range ids {
+		if _, ok := r.terms[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// setLinks 把文章关联的标签或分类替换为 ids，不存在的id会被忽略
+func (r *memoryTermRepository) setLinks(articleID int, ids []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	linked := []int{}
+	for _, id := range ids {
+		if _, ok := r.terms[id]; ok && !slices.Contains(linked, id) {
+			linked = append(linked, id)
+		}
+	}
+	slices.Sort(linked)
+	r.links[articleID] = linked
+}
+
+// removeArticle 删除文章的所有关联
+func (r *memoryTermRepository) removeArticle(articleID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.links, articleID)
+}
+
+// ids 返回文章关联的标签或分类id，按 id 排序
+func (r *memoryTermRepository) ids(articleID int) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]int{}, r.links[articleID]...)
+}
+
+// refs 返回文章关联的标签或分类，按 id 排序
+func (r *memoryTermRepository) refs(articleID int) []models.TermRef {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	refs := []models.TermRef{}
+	for _, id := range r.links[articleID] {
+		t := r.terms[id]
+		refs = append(refs, models.TermRef{ID: t.ID, Name: t.Name, Slug: t.Slug})
+	}
+	return refs
+}
+
+// hasSlug 判断文章是否关联了指定别名的标签或分类
+func (r *memoryTermRepository) hasSlug(articleID int, slug string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, id := range r.links[articleID] {
+		if r.terms[id].Slug == slug {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+type sqlTermRepository struct {
+	db      *sql.DB
+	dialect Dialect
+	termTable
+}
+
+func (r *sqlTermRepository) Create(ctx context.Context, term *models.Term) error {
+	query := "INSERT INTO " + r.table + " (name,slug,description) VALUES (?,?,?)"
+	result, err := r.db.ExecContext(ctx, query, term.Name, term.Slug, term.Description)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	term.ID = int(id)
+	return nil
+}
+
+func (r *sqlTermRepository) Update(ctx context.Context, term *models.Term) error {
+	query := "UPDATE " + r.table + " SET name=?,slug=?,description=? WHERE id=?"
+	_, err := r.db.ExecContext(ctx, query, term.Name, term.Slug, term.Description, term.ID)
+	return err
+}
+
+func (r *sqlTermRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+r.link+" WHERE "+r.column+"=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+r.table+" WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// termColumns 查询标签或分类的字段，最后一列统计已发布的文章数，顺序与 scanTerm 一致
+func (r *sqlTermRepository) termColumns() string {
+	return "t.id,t.name,t.slug,t.description,(SELECT COUNT(*) FROM " + r.link + " l JOIN article ON article.id = l.article_id WHERE l." + r.column + " = t.id AND article.status = '" + models.ArticleStatusPublished + "')"
+}
+
+// scanTerm 将一行查询结果解析为标签或分类
+func scanTerm(scan func(dest ...interface{}) error) (*models.Term, error) {
+	var term models.Term
+	if err := scan(&term.ID, &term.Name, &term.Slug, &term.Description, &term.ArticleCount); err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *sqlTermRepository) GetByID(ctx context.Context, id int) (*models.Term, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+r.termColumns()+" FROM "+r.table+" t WHERE t.id=?", id)
+	term, err := scanTerm(row.Scan)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return term, nil
+}
+
+func (r *sqlTermRepository) List(ctx context.Context, q TermQuery) ([]models.Term, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if q.Name != "" {
+		where += " AND (t.name LIKE ? OR t.slug LIKE ?)"
+		like := "%" + q.Name + "%"
+		args = append(args, like, like)
+	}
+
+	query := "SELECT " + r.termColumns() + " FROM " + r.table + " t" + where
+	query += " ORDER BY t.id DESC" // 按照 id 降序排列
+	query, listArgs := r.dialect.Paginate(query, args, q.Pagination)
+
+	rows, err := r.db.QueryContext(ctx, query, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Term{}
+	for rows.Next() {
+		term, err := scanTerm(rows.Scan)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// 获取总记录数
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+r.table+" t"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (r *sqlTermRepository) Exists(ctx context.Context, term *models.Term) (string, error) {
+	// 名称不区分大小写（MySQL 的默认排序规则，SQLite 的字段使用 NOCASE）
+	for _, field := range []struct{ column, value string }{{"name", term.Name}, {"slug", term.Slug}} {
+		var count int
+		query := "SELECT COUNT(*) FROM " + r.table + " WHERE " + field.column + "=? AND id<>?"
+		if err := r.db.QueryRowContext(ctx, query, field.value, term.ID).Scan(&count); err != nil {
+			return "", err
+		}
+		if count > 0 {
+			return field.column, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *sqlTermRepository) Missing(ctx context.Context, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := "SELECT id FROM " + r.table + " WHERE id IN (" + placeholders(len(ids)) + ")"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var missing []int
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// placeholders 返回 n 个用逗号分隔的 ?，用于 IN 条件
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// replaceArticleTerms 在事务中把文章关联的标签或分类替换为 ids
+func replaceArticleTerms(ctx context.Context, tx *sql.Tx, t termTable, articleID int, ids []int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.link+" WHERE article_id=?", articleID); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+t.link+" (article_id,"+t.column+") VALUES (?,?)", articleID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryer 可以是 *sql.DB 或 *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadArticleTerms 批量查询文章关联的标签或分类，返回以文章id为键的结果，按 id 排序
+func loadArticleTerms(ctx context.Context, db queryer, t termTable, articleIDs []int) (map[int][]models.TermRef, error) {
+	result := map[int][]models.TermRef{}
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+	args := make([]interface{}, len(articleIDs))
+	for i, id := range articleIDs {
+		args[i] = id
+	}
+	query := "SELECT l.article_id,t.id,t.name,t.slug FROM " + t.link + " l JOIN " + t.table + " t ON t.id = l." + t.column +
+		" WHERE l.article_id IN (" + placeholders(len(articleIDs)) + ") ORDER BY t.id"
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			articleID int
+			ref       models.TermRef
+		)
+		if err := rows.Scan(&articleID, &ref.ID, &ref.Name, &ref.Slug); err != nil {
+			return nil, err
+		}
+		result[articleID] = append(result[articleID], ref)
+	}
+	return result, rows.Err()
+}
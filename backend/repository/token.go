@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// TokenRepository 刷新令牌和访问令牌黑名单的数据访问接口
+type TokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// GetByHash 根据令牌哈希查询，已撤销的令牌也会返回
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// ListActive 查询用户未撤销且未过期的刷新令牌，按创建时间倒序排列
+	ListActive(ctx context.Context, userID int, now string) ([]models.RefreshToken, error)
+	// ListFamily 查询同一家族的全部刷新令牌，包括已撤销的
+	ListFamily(ctx context.Context, familyID string) ([]models.RefreshToken, error)
+	// Revoke 撤销一个仍然有效的令牌，返回是否撤销成功；令牌已被撤销时返回 false，用于检测并发重放
+	Revoke(ctx context.Context, id int, at string) (bool, error)
+	// RevokeFamily 撤销同一家族中所有仍然有效的令牌
+	RevokeFamily(ctx context.Context, familyID string, at string) error
+	// RevokeUser 撤销用户所有仍然有效的令牌
+	RevokeUser(ctx context.Context, userID int, at string) error
+
+	// Deny 将访问令牌加入黑名单，直到 expiresAt 为止，重复加入时忽略
+	Deny(ctx context.Context, jti string, expiresAt string) error
+	// IsDenied 判断访问令牌是否在黑名单中
+	IsDenied(ctx context.Context, jti string) (bool, error)
+	// PurgeDenied 删除在 before 之前已经过期的黑名单记录
+	PurgeDenied(ctx context.Context, before string) error
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sort"
+	"sync"
+)
+
+type memoryTokenRepository struct {
+	mu       sync.Mutex
+	nextID   int
+	tokens   map[int]models.RefreshToken
+	denylist map[string]string // jti -> 过期时间
+}
+
+func newMemoryTokenRepository() *memoryTokenRepository {
+	return &memoryTokenRepository{nextID: 1, tokens: map[int]models.RefreshToken{}, denylist: map[string]string{}}
+}
+
+func (r *memoryTokenRepository) Create(_ context.Context, t *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.ID = r.nextID
+	r.nextID++
+	r.tokens[t.ID] = *t
+	return nil
+}
+
+func (r *memoryTokenRepository) GetByHash(_ context.Context, hash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			return &t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryTokenRepository) ListActive(_ context.Context, userID int, now string) ([]models.RefreshToken, error) {
+	return r.filter(func(t models.RefreshToken) bool {
+		return t.UserID == userID && t.RevokedAt == "" && t.ExpiresAt > now
+	}), nil
+}
+
+func (r *memoryTokenRepository) ListFamily(_ context.Context, familyID string) ([]models.RefreshToken, error) {
+	return r.filter(func(t models.RefreshToken) bool { return t.FamilyID == familyID }), nil
+}
+
+// filter 返回满足条件的令牌，按 id 倒序排列
+func (r *memoryTokenRepository) filter(match func(models.RefreshToken) bool) []models.RefreshToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []models.RefreshToken{}
+	for _, t := range r.tokens {
+		if match(t) {
+			matched = append(matched, t)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return matched
+}
+
+func (r *memoryTokenRepository) Revoke(_ context.Context, id int, at string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[id]
+	if !ok || t.RevokedAt != "" {
+		return false, nil
+	}
+	t.RevokedAt = at
+	r.tokens[id] = t
+	return true, nil
+}
+
+func (r *memoryTokenRepository) RevokeFamily(_ context.Context, familyID string, at string) error {
+	r.revokeWhere(func(t models.RefreshToken) bool { return t.FamilyID == familyID }, at)
+	return nil
+}
+
+func (r *memoryTokenRepository) RevokeUser(_ context.Context, userID int, at string) error {
+	r.revokeWhere(func(t models.RefreshToken) bool { return t.UserID == userID }, at)
+	return nil
+}
+
+// revokeWhere 撤销满足条件且仍然有效的令牌
+func (r *memoryTokenRepository) revokeWhere(match func(models.RefreshToken) bool, at string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.tokens {
+		if t.RevokedAt == "" && match(t) {
+			t.RevokedAt = at
+			r.tokens[id] = t
+		}
+	}
+}
+
+func (r *memoryTokenRepository) Deny(_ context.Context, jti string, expiresAt string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.denylist[jti]; !ok {
+		r.denylist[jti] = expiresAt
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) IsDenied(_ context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.denylist[jti]
+	return ok, nil
+}
+
+func (r *memoryTokenRepository) PurgeDenied(_ context.Context, before string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for jti, expiresAt := range r.denylist {
+		if expiresAt <= before {
+			delete(r.denylist, jti)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+)
+
+type sqlTokenRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+const refreshTokenColumns = "id, user_id, family_id, token_hash, user_agent, ip, created_at, expires_at, revoked_at, access_jti"
+
+// scanRefreshToken 按 refreshTokenColumns 的顺序读取一行
+func scanRefreshToken(scan func(dest ...interface{}) error) (*models.RefreshToken, error) {
+	var (
+		t         models.RefreshToken
+		revokedAt sql.NullString
+	)
+	err := scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.UserAgent, &t.IP, &t.CreatedAt, &t.ExpiresAt, &revokedAt, &t.AccessJTI)
+	if err != nil {
+		return nil, err
+	}
+	t.RevokedAt = revokedAt.String
+	return &t, nil
+}
+
+func (r *sqlTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	query := "INSERT INTO refresh_token (user_id, family_id, token_hash, user_agent, ip, created_at, expires_at, access_jti) VALUES (?,?,?,?,?,?,?,?)"
+	result, err := r.db.ExecContext(ctx, query, t.UserID, t.FamilyID, t.TokenHash, t.UserAgent, t.IP, t.CreatedAt, t.ExpiresAt, t.AccessJTI)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+	return nil
+}
+
+func (r *sqlTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+refreshTokenColumns+" FROM refresh_token WHERE token_hash = ?", hash)
+	t, err := scanRefreshToken(row.Scan)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+func (r *sqlTokenRepository) ListActive(ctx context.Context, userID int, now string) ([]models.RefreshToken, error) {
+	query := "SELECT " + refreshTokenColumns + " FROM refresh_token WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY id DESC"
+	return r.list(ctx, query, userID, now)
+}
+
+func (r *sqlTokenRepository) ListFamily(ctx context.Context, familyID string) ([]models.RefreshToken, error) {
+	return r.list(ctx, "SELECT "+refreshTokenColumns+" FROM refresh_token WHERE family_id = ? ORDER BY id DESC", familyID)
+}
+
+func (r *sqlTokenRepository) list(ctx context.Context, query string, args ...interface{}) ([]models.RefreshToken, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.RefreshToken{}
+	for rows.Next() {
+		t, err := scanRefreshToken(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *sqlTokenRepository) Revoke(ctx context.Context, id int, at string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE refresh_token SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", at, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *sqlTokenRepository) RevokeFamily(ctx context.Context, familyID string, at string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE refresh_token SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", at, familyID)
+	return err
+}
+
+func (r *sqlTokenRepository) RevokeUser(ctx context.Context, userID int, at string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE refresh_token SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", at, userID)
+	return err
+}
+
+func (r *sqlTokenRepository) Deny(ctx context.Context, jti string, expiresAt string) error {
+	_, err := r.db.ExecContext(ctx, r.dialect.InsertIgnore("token_denylist")+" (jti, expires_at) VALUES (?, ?)", jti, expiresAt)
+	return err
+}
+
+func (r *sqlTokenRepository) IsDenied(ctx context.Context, jti string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM token_denylist WHERE jti = ?", jti).Scan(&count)
+	return count > 0, err
+}
+
+func (r *sqlTokenRepository) PurgeDenied(ctx context.Context, before string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM token_denylist WHERE expires_at <= ?", before)
+	return err
+}
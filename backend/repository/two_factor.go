@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// TwoFactorRepository 两步验证密钥和恢复码的数据访问接口
+type TwoFactorRepository interface {
+	// Get 查询用户的两步验证设置，未设置时返回 ErrNotFound
+	Get(ctx context.Context, userID int) (*models.TwoFactor, error)
+	// SavePending 保存尚未开启的密钥，覆盖之前未开启的密钥
+	SavePending(ctx context.Context, userID int, secret string) error
+	// Enable 开启两步验证并保存恢复码的哈希，step 为验证时使用的时间步；已开启时返回 false
+	Enable(ctx context.Context, userID int, step int64, codeHashes []string, at string) (bool, error)
+	// Disable 删除用户的密钥和全部恢复码
+	Disable(ctx context.Context, userID int) error
+	// UseStep 记录使用过的时间步，step 不大于上次使用的时间步时返回 false，用于防止验证码重放
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	// ReplaceRecoveryCodes 删除旧的恢复码并保存新的恢复码哈希
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode 将一个未使用的恢复码标记为已使用，恢复码不存在或已使用时返回 false
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string, at string) (bool, error)
+	// CountRecoveryCodes 返回未使用的恢复码数量
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+}
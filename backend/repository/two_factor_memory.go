@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sync"
+)
+
+// memoryRecoveryCode 内存中保存的恢复码
+type memoryRecoveryCode struct {
+	hash string
+	used bool
+}
+
+type memoryTwoFactorRepository struct {
+	mu       sync.Mutex
+	settings map[int]models.TwoFactor
+	codes    map[int][]memoryRecoveryCode
+}
+
+func newMemoryTwoFactorRepository() *memoryTwoFactorRepository {
+	return &memoryTwoFactorRepository{settings: map[int]models.TwoFactor{}, codes: map[int][]memoryRecoveryCode{}}
+}
+
+func (r *memoryTwoFactorRepository) Get(_ context.Context, userID int) (*models.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tf, ok := r.settings[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &tf, nil
+}
+
+func (r *memoryTwoFactorRepository) SavePending(_ context.Context, userID int, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tf, ok := r.settings[userID]; ok && tf.Enabled {
+		return nil
+	}
+	r.settings[userID] = models.TwoFactor{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) Enable(_ context.Context, userID int, step int64, codeHashes []string, at string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tf, ok := r.settings[userID]
+	if !ok || tf.Enabled {
+		return false, nil
+	}
+	tf.Enabled = true
+	tf.LastStep = step
+	tf.EnabledAt = at
+	r.settings[userID] = tf
+	r.replaceCodes(userID, codeHashes)
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepository) Disable(_ context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.settings, userID)
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) UseStep(_ context.Context, userID int, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tf, ok := r.settings[userID]
+	if !ok || tf.LastStep >= step {
+		return false, nil
+	}
+	tf.LastStep = step
+	r.settings[userID] = tf
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepository) ReplaceRecoveryCodes(_ context.Context, userID int, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replaceCodes(userID, codeHashes)
+	return nil
+}
+
+// replaceCodes 替换用户的恢复码，调用方需要持有锁
+func (r *memoryTwoFactorRepository) replaceCodes(userID int, codeHashes []string) {
+	codes := make([]memoryRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = memoryRecoveryCode{hash: hash}
+	}
+	r.codes[userID] = codes
+}
+
+func (r *memoryTwoFactorRepository) UseRecoveryCode(_ context.Context, userID int, codeHash string, _ string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, code := range r.codes[userID] {
+		if code.hash == codeHash && !code.used {
+			r.codes[userID][i].used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryTwoFactorRepository) CountRecoveryCodes(_ context.Context, userID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, code := range r.codes[userID] {
+		if !code.used {
+			count++
+		}
+	}
+	return count, nil
+}
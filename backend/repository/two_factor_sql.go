@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+)
+
+type sqlTwoFactorRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlTwoFactorRepository) Get(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+	query := "SELECT user_id, secret, enabled, last_step, enabled_at FROM user_two_factor WHERE user_id = ?"
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&tf.UserID, &tf.Secret, &tf.Enabled, &tf.LastStep, &tf.EnabledAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &tf, nil
+}
+
+func (r *sqlTwoFactorRepository) SavePending(ctx context.Context, userID int, secret string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 已开启的设置不会被覆盖
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_two_factor WHERE user_id = ? AND enabled = 0", userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.dialect.InsertIgnore("user_two_factor")+" (user_id, secret) VALUES (?,?)", userID, secret); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlTwoFactorRepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string, at string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE user_two_factor SET enabled = 1, last_step = ?, enabled_at = ? WHERE user_id = ? AND enabled = 0", step, at, userID)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *sqlTwoFactorRepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_two_factor WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_code WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlTwoFactorRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE user_two_factor SET last_step = ? WHERE user_id = ? AND last_step < ?", step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *sqlTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceRecoveryCodes 在事务中替换用户的恢复码
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_code WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO user_recovery_code (user_id, code_hash) VALUES (?,?)", userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqlTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, at string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE user_recovery_code SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL", at, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *sqlTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_recovery_code WHERE user_id = ? AND used_at IS NULL", userID).Scan(&count)
+	return count, err
+}
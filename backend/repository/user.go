@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+)
+
+// UserQuery 用户列表查询条件
+type UserQuery struct {
+	Pagination
+	Username      string // 模糊匹配用户名
+	Status        string
+	VerifiedEmail *bool // 按邮箱是否已验证筛选，为空时不筛选
+}
+
+// UserRepository 用户数据访问接口
+type UserRepository interface {
+	// Create 新增用户，user.Password 需要是已经加密后的密码，成功后回填 user.ID
+	Create(ctx context.Context, user *models.User) error
+	// Update 更新用户的基本信息、状态和角色，不修改密码；邮箱变化时重置验证状态
+	Update(ctx context.Context, user *models.User) error
+	// GetByID 根据 id 查询用户，不返回密码
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	// GetByUsername 根据用户名查询用户，返回加密后的密码，用于登录校验
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	// ListByEmail 按邮箱查询用户（不区分大小写），同一邮箱可能绑定多个账号，返回加密后的密码
+	ListByEmail(ctx context.Context, email string) ([]models.User, error)
+	// GetPasswordHash 获取用户加密后的密码
+	GetPasswordHash(ctx context.Context, id int) (string, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	// List 按条件查询用户列表，同时返回符合条件的总数
+	List(ctx context.Context, query UserQuery) ([]models.User, int, error)
+	// UpdateRole 修改用户角色
+	UpdateRole(ctx context.Context, id int, role string) error
+	// UpdateLocale 修改用户的语言偏好，locale 为空表示按 Accept-Language 选择
+	UpdateLocale(ctx context.Context, id int, locale string) error
+	// UpdatePassword 更新密码，hash 为加密后的密码；mustChange 为 true 时用户下次登录后必须修改密码
+	UpdatePassword(ctx context.Context, id int, hash string, mustChange bool) error
+	// SetEmailVerified 用户当前邮箱仍为 email 时标记为已验证，邮箱已被修改时返回 false
+	SetEmailVerified(ctx context.Context, id int, email string) (bool, error)
+	// ReplacePasswordHash 密码哈希仍为 oldHash 时替换为 newHash，用于登录时按新的哈希配置重新计算，
+	// 不修改 must_change_password；哈希已被修改时返回 false
+	ReplacePasswordHash(ctx context.Context, id int, oldHash, newHash string) (bool, error)
+	// IncrementTokenVersion 递增用户的令牌版本，使之前签发的访问令牌全部失效
+	IncrementTokenVersion(ctx context.Context, id int) error
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	users  map[int]models.User // Password 字段保存加密后的密码
+}
+
+func newMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{nextID: 1, users: map[int]models.User{}}
+}
+
+// username 返回用户名，供其他内存仓库模拟 JOIN 查询
+func (r *memoryUserRepository) username(id int) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	return user.Username, ok
+}
+
+func (r *memoryUserRepository) Create(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.ID = r.nextID
+	r.nextID++
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) Update(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return nil
+	}
+	existing.Username = user.Username
+	existing.PhoneNumber = user.PhoneNumber
+	if existing.Email != user.Email {
+		existing.VerifiedEmail = false
+	}
+	existing.Email = user.Email
+	existing.RealName = user.RealName
+	existing.Avatar = user.Avatar
+	existing.Status = user.Status
+	existing.StatusReason = user.StatusReason
+	existing.RestrictedUntil = user.RestrictedUntil
+	existing.Role = user.Role
+	r.users[user.ID] = existing
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(_ context.Context, id int) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user.Password = ""
+	user.ApplyRestrictionExpiry(time.Now())
+	return &user, nil
+}
+
+func (r *memoryUserRepository) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.Username == username {
+			user.ApplyRestrictionExpiry(time.Now())
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) ListByEmail(_ context.Context, email string) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := []models.User{}
+	for _, user := range r.users {
+		if user.Email != "" && strings.EqualFold(user.Email, email) {
+			user.ApplyRestrictionExpiry(time.Now())
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *memoryUserRepository) GetPasswordHash(_ context.Context, id int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return user.Password, nil
+}
+
+func (r *memoryUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	_, err := r.GetByUsername(ctx, username)
+	return err == nil, nil
+}
+
+func (r *memoryUserRepository) List(_ context.Context, q UserQuery) ([]models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []models.User{}
+	for _, user := range r.users {
+		user.ApplyRestrictionExpiry(time.Now())
+		if q.Username != "" && !strings.Contains(user.Username, q.Username) {
+			continue
+		}
+		if q.Status != "" && user.Status != q.Status {
+			continue
+		}
+		if q.VerifiedEmail != nil && user.VerifiedEmail != *q.VerifiedEmail {
+			continue
+		}
+		user.Password = ""
+		matched = append(matched, user)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return paginate(matched, q.Pagination), len(matched), nil
+}
+
+func (r *memoryUserRepository) UpdateRole(_ context.Context, id int, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.Role = role
+		r.users[id] = user
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateLocale(_ context.Context, id int, locale string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.Locale = locale
+		r.users[id] = user
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(_ context.Context, id int, hash string, mustChange bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.Password = hash
+		user.MustChangePassword = mustChange
+		r.users[id] = user
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) SetEmailVerified(_ context.Context, id int, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !strings.EqualFold(user.Email, email) {
+		return false, nil
+	}
+	user.VerifiedEmail = true
+	r.users[id] = user
+	return true, nil
+}
+
+func (r *memoryUserRepository) ReplacePasswordHash(_ context.Context, id int, oldHash, newHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.Password != oldHash {
+		return false, nil
+	}
+	user.Password = newHash
+	r.users[id] = user
+	return true, nil
+}
+
+func (r *memoryUserRepository) IncrementTokenVersion(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.TokenVersion++
+		r.users[id] = user
+	}
+	return nil
+}
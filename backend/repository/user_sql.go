@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlUserRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (r *sqlUserRepository) Create(ctx context.Context, user *models.User) error {
+	query := "INSERT INTO user (username, password, phone_number, email, real_name, register_time, avatar, creator_id, status, role, locale) VALUES (?,?,?,?,?,?,?,?,?,?,?)"
+	result, err := r.db.ExecContext(ctx, query, user.Username, user.Password, user.PhoneNumber, user.Email, user.RealName, user.RegisterTime, user.Avatar, user.CreatorID, user.Status, user.Role, user.Locale)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	user.ID = int(id)
+	return nil
+}
+
+func (r *sqlUserRepository) Update(ctx context.Context, user *models.User) error {
+	ifNull := r.dialect.IfNull
+	query := "UPDATE user SET username = " + ifNull("?", "username") +
+		", phone_number = " + ifNull("?", "phone_number") +
+		", verified_email = CASE WHEN email = ? THEN verified_email ELSE 0 END" + // 必须在修改 email 之前判断
+		", email = " + ifNull("?", "email") +
+		", real_name = " + ifNull("?", "real_name") +
+		", avatar = " + ifNull("?", "avatar") +
+		", status = " + ifNull("?", "status") +
+		", status_reason = " + ifNull("?", "status_reason") +
+		", restricted_until = " + ifNull("?", "restricted_until") +
+		", role = " + ifNull("?", "role") +
+		" WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, user.Username, user.PhoneNumber, user.Email, user.Email, user.RealName, user.Avatar, user.Status, user.StatusReason, user.RestrictedUntil, user.Role, user.ID)
+	return err
+}
+
+func (r *sqlUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	query := "SELECT id, username, phone_number, email, real_name, avatar, status, status_reason, restricted_until, role, must_change_password, verified_email, locale, token_version FROM user WHERE id = ?"
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.PhoneNumber, &user.Email, &user.RealName, &user.Avatar, &user.Status, &user.StatusReason, &user.RestrictedUntil, &user.Role, &user.MustChangePassword, &user.VerifiedEmail, &user.Locale, &user.TokenVersion)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	user.ApplyRestrictionExpiry(time.Now())
+	return &user, nil
+}
+
+func (r *sqlUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	query := "SELECT id, username, password, avatar, status, status_reason, restricted_until, role, must_change_password, verified_email, locale FROM user WHERE username = ?"
+	err := r.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Password, &user.Avatar, &user.Status, &user.StatusReason, &user.RestrictedUntil, &user.Role, &user.MustChangePassword, &user.VerifiedEmail, &user.Locale)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	user.ApplyRestrictionExpiry(time.Now())
+	return &user, nil
+}
+
+func (r *sqlUserRepository) ListByEmail(ctx context.Context, email string) ([]models.User, error) {
+	query := "SELECT id, username, password, email, status, status_reason, restricted_until, role, verified_email FROM user WHERE LOWER(email) = LOWER(?) ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Status, &user.StatusReason, &user.RestrictedUntil, &user.Role, &user.VerifiedEmail); err != nil {
+			return nil, err
+		}
+		user.ApplyRestrictionExpiry(time.Now())
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *sqlUserRepository) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	var hash string
+	err := r.db.QueryRowContext(ctx, "SELECT password FROM user WHERE id = ?", id).Scan(&hash)
+	if err != nil {
+		return "", notFound(err)
+	}
+	return hash, nil
+}
+
+func (r *sqlUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user WHERE username = ?", username).Scan(&count)
+	return count > 0, err
+}
+
+// userFilter 构建列表查询和总数查询共用的 WHERE 条件
+// 按状态筛选时，已经到期的临时限制按正常状态处理
+func userFilter(q UserQuery) (string, []interface{}) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if q.Username != "" {
+		where += " AND username LIKE ?"
+		args = append(args, "%"+q.Username+"%")
+	}
+	now := time.Now().Format(models.TimeLayout)
+	switch q.Status {
+	case "":
+	case models.UserStatusNormal:
+		where += " AND (status = ? OR (status = ? AND restricted_until <> '' AND restricted_until <= ?))"
+		args = append(args, models.UserStatusNormal, models.UserStatusRestricted, now)
+	case models.UserStatusRestricted:
+		where += " AND status = ? AND (restricted_until = '' OR restricted_until > ?)"
+		args = append(args, models.UserStatusRestricted, now)
+	default:
+		where += " AND status = ?"
+		args = append(args, q.Status)
+	}
+	if q.VerifiedEmail != nil {
+		where += " AND verified_email = ?"
+		args = append(args, *q.VerifiedEmail)
+	}
+	return where, args
+}
+
+func (r *sqlUserRepository) List(ctx context.Context, q UserQuery) ([]models.User, int, error) {
+	where, args := userFilter(q)
+
+	query := "SELECT id, username, phone_number, email, real_name, register_time, avatar, status, status_reason, restricted_until, role, must_change_password, verified_email FROM user" + where
+	query += " ORDER BY id DESC" // 按照 id 降序排列
+	query, listArgs := r.dialect.Paginate(query, args, q.Pagination)
+
+	rows, err := r.db.QueryContext(ctx, query, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PhoneNumber, &user.Email, &user.RealName, &user.RegisterTime, &user.Avatar, &user.Status, &user.StatusReason, &user.RestrictedUntil, &user.Role, &user.MustChangePassword, &user.VerifiedEmail); err != nil {
+			return nil, 0, err
+		}
+		user.ApplyRestrictionExpiry(time.Now())
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// 获取总记录数
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *sqlUserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user SET role = ? WHERE id = ?", role, id)
+	return err
+}
+
+func (r *sqlUserRepository) UpdateLocale(ctx context.Context, id int, locale string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user SET locale = ? WHERE id = ?", locale, id)
+	return err
+}
+
+func (r *sqlUserRepository) UpdatePassword(ctx context.Context, id int, hash string, mustChange bool) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user SET password = ?, must_change_password = ? WHERE id = ?", hash, mustChange, id)
+	return err
+}
+
+func (r *sqlUserRepository) SetEmailVerified(ctx context.Context, id int, email string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE user SET verified_email = 1 WHERE id = ? AND LOWER(email) = LOWER(?)", id, email)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *sqlUserRepository) ReplacePasswordHash(ctx context.Context, id int, oldHash, newHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE user SET password = ? WHERE id = ? AND password = ?", newHash, id, oldHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *sqlUserRepository) IncrementTokenVersion(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user SET token_version = token_version + 1 WHERE id = ?", id)
+	return err
+}
@@ -1,21 +1,66 @@
 package routers
 
 import (
+	"backend/auth"             // 引入令牌服务，用于签发和刷新令牌
+	"backend/config"           // 引入配置，读取静态文件目录等设置
 	"backend/controllers"      // 引入控制器，用于处理路由对应的业务逻辑
+	"backend/mail"             // 引入邮件队列，用于发送找回密码和验证邮箱的邮件
 	"backend/middlewares"      // 引入中间件，用于处理跨域和身份验证等
+	"backend/models"           // 引入模型，使用其中定义的权限字符串
+	"backend/repository"       // 引入数据仓库，注入到控制器和中间件中
 	"github.com/gin-gonic/gin" // 引入 Gin 框架，用于创建路由
 )
 
 // SetupRouter 初始化并设置所有的路由和中间件
+// repos: 数据仓库，由调用方根据配置创建（MySQL 或内存实现）
+// mailer: 邮件队列，由调用方创建并在服务关闭时等待发送完成
+// passwords: 密码服务，由调用方根据密码策略配置创建
 // 返回一个 *gin.Engine 对象，表示 Gin 的路由引擎
-func SetupRouter() *gin.Engine {
-	router := gin.Default() // 创建默认的 Gin 路由引擎，包含一些默认中间件（如日志、恢复机制）
+func SetupRouter(repos *repository.Repositories, mailer *mail.Queue, passwords *auth.PasswordService) *gin.Engine {
+	router := gin.New() // 创建 Gin 路由引擎
+
+	// 为每个请求分配请求 ID，请求日志、错误日志和错误响应中都带有请求 ID
+	// 发生 panic 时返回统一格式的内部错误；控制器通过 utils.Fail 记录的错误由 ErrorMiddleware 转换为错误响应
+	router.Use(middlewares.RequestIDMiddleware())
+	// 按 Accept-Language 选择响应消息的语言，登录用户的语言偏好由 JWT 中间件覆盖
+	router.Use(middlewares.LocaleMiddleware())
+	router.Use(gin.LoggerWithFormatter(middlewares.LogFormatter))
+	router.Use(gin.CustomRecovery(middlewares.RecoveryHandler))
+	router.Use(middlewares.ErrorMiddleware())
+
+	// 只采信可信代理转发的客户端 IP，避免伪造 X-Forwarded-For 绕过按 IP 的登录限流
+	// 地址格式已在配置校验时检查
+	router.SetTrustedProxies(config.Conf.Server.TrustedProxies)
 
 	// 使用 CORS 中间件，允许跨域请求
 	router.Use(middlewares.CORSMiddleware())
 
-	// 配置静态文件路径，将 /static 映射到本地的 ./static 文件夹
-	router.Static("/static", "./static")
+	// 启用 HTTPS 时添加 HSTS 响应头
+	if tlsConf := config.Conf.Server.TLS; tlsConf.Enabled {
+		router.Use(middlewares.HSTSMiddleware(tlsConf.HSTS.MaxAge.Duration, tlsConf.HSTS.IncludeSubdomains))
+	}
+
+	// 配置静态文件路径，将 /static 映射到配置的静态文件目录（默认 ./static）
+	router.Static("/static", config.Conf.Server.StaticDir)
+
+	// 创建控制器和需要查询用户的 JWT 中间件
+	tokens := auth.NewTokenService(repos.Tokens, repos.Users)
+	loginGuard := auth.NewLoginGuard(auth.NewMemoryAttemptStore(), repos.Audit, config.Conf.Login)
+	twoFactor := auth.NewTwoFactorService(repos.TwoFactor, repos.Users, repos.Audit, config.Conf.TwoFactor)
+	userController := controllers.NewUserController(repos.Users, tokens, loginGuard, twoFactor, passwords)
+	twoFactorController := controllers.NewTwoFactorController(repos.Users, twoFactor, passwords, loginGuard)
+	accountController := controllers.NewAccountController(repos.Users, tokens, loginGuard, passwords, mailer, config.Conf.Mail)
+	sessionController := controllers.NewSessionController(tokens)
+	roleController := controllers.NewRoleController(repos.Users)
+	auditController := controllers.NewAuditController(repos.Audit)
+	projectController := controllers.NewProjectController(repos.Projects)
+	articleController := controllers.NewArticleController(repos.Articles, repos.Tags, repos.Categories)
+	tagController := controllers.NewTagController(repos.Tags)
+	categoryController := controllers.NewCategoryController(repos.Categories)
+	jwtAuth := middlewares.JWTAuthMiddleware(repos.Users, tokens)
+	optionalAuth := middlewares.OptionalJWTAuthMiddleware(repos.Users, tokens)
+	passwordChangeAuth := middlewares.PasswordChangeAuthMiddleware(repos.Users, tokens)
+	activeAccount := middlewares.RequireActiveAccount()
 
 	// 创建 /api 路由组，所有以 /api 开头的路由将由此组管理
 	api := router.Group("/api")
@@ -23,36 +68,153 @@ func SetupRouter() *gin.Engine {
 		// 文件上传路由组
 		upload := api.Group("/upload")
 		{
-			upload.POST("/image", middlewares.JWTAuthMiddleware(), controllers.UploadImage)
+			upload.POST("/image", jwtAuth, middlewares.RequirePermission(models.PermUploadImage), controllers.UploadImage)
 		}
 		// 创建 /api/user 路由组，所有用户相关的路由将由此组管理
 		user := api.Group("/user")
 		{
-			user.POST("/register", controllers.Register)
-			user.POST("/login", controllers.Login)
-			user.POST("/add", middlewares.JWTAuthMiddleware(), controllers.AddUser)
-			user.POST("/edit", middlewares.JWTAuthMiddleware(), controllers.UpdateUser)
-			user.POST("/list", controllers.GetUserList)
-			user.POST("/details", controllers.GetUserInfo)
-			user.POST("/password/reset", middlewares.JWTAuthMiddleware(), controllers.ResetPassword)
-			user.POST("/password/change", middlewares.JWTAuthMiddleware(), controllers.ChangePassword)
+			user.POST("/register", userController.Register)
+			user.POST("/login", userController.Login)
+			user.POST("/login/2fa", userController.LoginTwoFactor)
+			user.POST("/login/2fa/setup", userController.LoginTwoFactorSetup)
+			user.POST("/login/2fa/enable", userController.LoginTwoFactorEnable)
+			user.POST("/token/refresh", userController.RefreshToken)
+			user.POST("/logout", userController.Logout)
+			user.POST("/add", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.AddUser)
+			user.POST("/edit", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.UpdateUser)
+			user.POST("/list", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.GetUserList)
+			user.POST("/details", userController.GetUserInfo)
+			user.POST("/password/reset", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.ResetPassword)
+			user.POST("/password/change", passwordChangeAuth, userController.ChangePassword)
+			user.POST("/locale", passwordChangeAuth, activeAccount, userController.SetLocale)
+			user.POST("/password/forgot", accountController.ForgotPassword)
+			user.POST("/password/forgot/reset", accountController.ResetForgottenPassword)
+			user.POST("/email/verify/send", jwtAuth, activeAccount, accountController.SendVerifyEmail)
+			user.POST("/email/verify", accountController.VerifyEmail)
+			user.POST("/unlock", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.UnlockUser)
+			user.POST("/role/assign", jwtAuth, middlewares.RequirePermission(models.PermRoleManage), roleController.AssignRole)
+			user.POST("/2fa/status", jwtAuth, twoFactorController.GetStatus)
+			user.POST("/2fa/setup", jwtAuth, activeAccount, twoFactorController.Setup)
+			user.POST("/2fa/enable", jwtAuth, activeAccount, twoFactorController.Enable)
+			user.POST("/2fa/disable", jwtAuth, activeAccount, twoFactorController.Disable)
+			user.POST("/2fa/recovery_codes", jwtAuth, activeAccount, twoFactorController.RegenerateRecoveryCodes)
+			user.POST("/2fa/reset", jwtAuth, middlewares.RequirePermission(models.PermUserManage), twoFactorController.Reset)
+			user.POST("/session/list", jwtAuth, sessionController.GetSessionList)
+			// 被限制的账号也可以注销自己的会话和修改密码，账号被盗用时用户仍然能够收回访问
+			user.POST("/session/revoke", jwtAuth, sessionController.RevokeSession)
+			user.POST("/session/revoke_all", jwtAuth, sessionController.RevokeAllSessions)
+		}
+		audit := api.Group("/audit")
+		{
+			audit.POST("/list", jwtAuth, middlewares.RequirePermission(models.PermUserManage), auditController.GetAuditList)
+		}
+		role := api.Group("/role")
+		{
+			role.POST("/list", jwtAuth, middlewares.RequirePermission(models.PermRoleManage), roleController.GetRoleList)
 		}
 		project := api.Group("/project")
 		{
-			project.POST("/add", middlewares.JWTAuthMiddleware(), controllers.AddProject)
-			project.POST("/edit", middlewares.JWTAuthMiddleware(), controllers.EditProject)
-			project.POST("/list", controllers.GetProjectList)
-			project.POST("/delete", middlewares.JWTAuthMiddleware(), controllers.DeleteProject)
-			project.POST("/details", controllers.GetProjectDetails)
+			project.POST("/add", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.AddProject)
+			project.POST("/edit", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.EditProject)
+			project.POST("/list", projectController.GetProjectList)
+			project.POST("/delete", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.DeleteProject)
+			project.POST("/details", projectController.GetProjectDetails)
 
 		}
+		// 标签和分类，所有人可以查看，编辑和管理员可以管理
+		tag := api.Group("/tag")
+		{
+			tag.POST("/add", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.AddTerm)
+			tag.POST("/edit", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.EditTerm)
+			tag.POST("/list", tagController.GetTermList)
+			tag.POST("/delete", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.DeleteTerm)
+			tag.POST("/details", tagController.GetTermDetails)
+		}
+		category := api.Group("/category")
+		{
+			category.POST("/add", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.AddTerm)
+			category.POST("/edit", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.EditTerm)
+			category.POST("/list", categoryController.GetTermList)
+			category.POST("/delete", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.DeleteTerm)
+			category.POST("/details", categoryController.GetTermDetails)
+		}
 		article := api.Group("/article")
 		{
-			article.POST("/add", middlewares.JWTAuthMiddleware(), controllers.AddArticle)
-			article.POST("/edit", middlewares.JWTAuthMiddleware(), controllers.EditArticle)
-			article.POST("/list", controllers.GetArticleList)
-			article.POST("/delete", middlewares.JWTAuthMiddleware(), controllers.DeleteArticle)
-			article.POST("/details", controllers.GetArticleDetails)
+			article.POST("/add", jwtAuth, middlewares.RequirePermission(models.PermArticleCreate), articleController.AddArticle)
+			article.POST("/edit", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.EditArticle)
+			article.POST("/list", optionalAuth, articleController.GetArticleList)
+			article.POST("/delete", jwtAuth, middlewares.RequirePermission(models.PermArticleDelete), articleController.DeleteArticle)
+			article.POST("/details", optionalAuth, articleController.GetArticleDetails)
+			// 审核流程，具体的状态和角色限制见 models/article_workflow.go
+			article.POST("/submit", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.SubmitArticle)
+			article.POST("/approve", jwtAuth, middlewares.RequirePermission(models.PermArticleReview), articleController.ApproveArticle)
+			article.POST("/reject", jwtAuth, middlewares.RequirePermission(models.PermArticleReview), articleController.RejectArticle)
+			article.POST("/publish", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.PublishArticle)
+			article.POST("/unpublish", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.UnpublishArticle)
+			article.POST("/transitions", jwtAuth, articleController.GetArticleTransitions)
+			// 历史版本，每次编辑都会保存一个版本
+			article.POST("/revisions", jwtAuth, articleController.GetArticleRevisions)
+			article.POST("/revisions/details", jwtAuth, articleController.GetArticleRevision)
+			article.POST("/revisions/diff", jwtAuth, articleController.DiffArticleRevisions)
+			article.POST("/revisions/restore", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.RestoreArticleRevision)
+		}
+	}
+
+	// 创建 /api/v2 路由组，按资源组织 URL，使用 GET、POST、PUT、PATCH、DELETE 表示操作
+	// 与 /api 下的 v1 接口同时提供，前端可以逐步迁移；登录、账号和两步验证等接口仍然只在 v1 中提供
+	v2 := router.Group("/api/v2")
+	{
+		articles := v2.Group("/articles")
+		{
+			articles.GET("", optionalAuth, articleController.ListArticles)
+			articles.GET("/:id", optionalAuth, articleController.GetArticle)
+			articles.POST("", jwtAuth, middlewares.RequirePermission(models.PermArticleCreate), articleController.CreateArticle)
+			articles.PUT("/:id", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.ReplaceArticle)
+			articles.PATCH("/:id", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.PatchArticle)
+			articles.DELETE("/:id", jwtAuth, middlewares.RequirePermission(models.PermArticleDelete), articleController.RemoveArticle)
+			articles.POST("/:id/submit", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.SubmitArticle)
+			articles.POST("/:id/approve", jwtAuth, middlewares.RequirePermission(models.PermArticleReview), articleController.ApproveArticle)
+			articles.POST("/:id/reject", jwtAuth, middlewares.RequirePermission(models.PermArticleReview), articleController.RejectArticle)
+			articles.POST("/:id/publish", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.PublishArticle)
+			articles.POST("/:id/unpublish", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.UnpublishArticle)
+			articles.GET("/:id/transitions", jwtAuth, articleController.GetArticleTransitions)
+			articles.GET("/:id/revisions", jwtAuth, articleController.GetArticleRevisions)
+			articles.GET("/:id/revisions/diff", jwtAuth, articleController.DiffArticleRevisions)
+			articles.GET("/:id/revisions/:revision_id", jwtAuth, articleController.GetArticleRevision)
+			articles.POST("/:id/revisions/:revision_id/restore", jwtAuth, middlewares.RequirePermission(models.PermArticleEdit), articleController.RestoreArticleRevision)
+		}
+		projects := v2.Group("/projects")
+		{
+			projects.GET("", projectController.ListProjects)
+			projects.GET("/:id", projectController.GetProject)
+			projects.POST("", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.CreateProject)
+			projects.PUT("/:id", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.ReplaceProject)
+			projects.PATCH("/:id", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.PatchProject)
+			projects.DELETE("/:id", jwtAuth, middlewares.RequirePermission(models.PermProjectManage), projectController.RemoveProject)
+		}
+		tags := v2.Group("/tags")
+		{
+			tags.GET("", tagController.ListTerms)
+			tags.GET("/:id", tagController.GetTerm)
+			tags.POST("", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.CreateTerm)
+			tags.PUT("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.ReplaceTerm)
+			tags.PATCH("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.PatchTerm)
+			tags.DELETE("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), tagController.RemoveTerm)
+		}
+		categories := v2.Group("/categories")
+		{
+			categories.GET("", categoryController.ListTerms)
+			categories.GET("/:id", categoryController.GetTerm)
+			categories.POST("", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.CreateTerm)
+			categories.PUT("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.ReplaceTerm)
+			categories.PATCH("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.PatchTerm)
+			categories.DELETE("/:id", jwtAuth, middlewares.RequirePermission(models.PermTaxonomyManage), categoryController.RemoveTerm)
+		}
+		users := v2.Group("/users")
+		{
+			// 返回邮箱、手机号和账号状态等信息，只有用户管理员可以访问
+			users.GET("", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.ListUsers)
+			users.GET("/:id", jwtAuth, middlewares.RequirePermission(models.PermUserManage), userController.GetUser)
 		}
 	}
 
@@ -0,0 +1,556 @@
+package routers
+
+import (
+	"backend/auth"
+	"backend/config"
+	"backend/mail"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testPassword = "Abcdef1!xy"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// testServer 使用内存仓库的完整路由，包含一个管理员 admin
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	repos  *repository.Repositories
+}
+
+// response 接口的统一响应格式
+type response struct {
+	Status  int             `json:"status"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	conf, secret := config.Conf, config.JwtSecret
+	t.Cleanup(func() { config.Conf, config.JwtSecret = conf, secret })
+	config.Conf = config.Default()
+	config.Conf.Password.Hash.BcryptCost = 4 // 测试中使用最低的计算成本
+	config.Conf.Login.DelayBase = config.Duration{}
+	config.Conf.Admin = config.AdminConfig{Username: "admin", Password: testPassword}
+	config.JwtSecret = []byte("0123456789abcdef0123456789abcdef")
+
+	repos := repository.NewMemory()
+	passwords, err := auth.NewPasswordService(config.Conf.Password, repos.Users, repos.PasswordHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.EnsureAdmin(context.Background(), config.Conf.Admin, repos.Users, passwords); err != nil {
+		t.Fatal(err)
+	}
+	mailer := mail.NewQueue(mail.NewLogSender(config.Conf.Mail.From))
+	t.Cleanup(func() { mailer.Close(context.Background()) })
+	return &testServer{t: t, router: SetupRouter(repos, mailer, passwords), repos: repos}
+}
+
+// do 发送 JSON 请求，token 为空时不携带令牌，out 不为 nil 时解析响应中的 data
+func (s *testServer) do(method, path, token string, body interface{}, out interface{}) response {
+	s.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var resp response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("%s %s 响应不是 JSON: %s", method, path, w.Body.String())
+	}
+	if resp.Status != w.Code {
+		s.t.Fatalf("%s %s 响应中的 status = %d，HTTP 状态码为 %d", method, path, resp.Status, w.Code)
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			s.t.Fatalf("%s %s 解析 data 失败: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+// expect 检查响应的状态码
+func (s *testServer) expect(resp response, status int, what string) {
+	s.t.Helper()
+	if resp.Status != status {
+		s.t.Fatalf("%s: 状态码 = %d（%s %s），期望 %d", what, resp.Status, resp.Code, resp.Message, status)
+	}
+}
+
+// login 登录并返回令牌
+func (s *testServer) login(username string) auth.TokenPair {
+	s.t.Helper()
+	var pair auth.TokenPair
+	resp := s.do(http.MethodPost, "/api/user/login", "", gin.H{"username": username, "password": testPassword}, &pair)
+	s.expect(resp, http.StatusOK, "登录 "+username)
+	return pair
+}
+
+// addUser 由管理员添加指定角色的用户并登录，返回访问令牌
+func (s *testServer) addUser(adminToken, username, role string) string {
+	s.t.Helper()
+	resp := s.do(http.MethodPost, "/api/user/add", adminToken, gin.H{
+		"username": username, "password": testPassword, "email": username + "@example.com", "role": role,
+	}, nil)
+	s.expect(resp, http.StatusOK, "添加用户 "+username)
+	return s.login(username).AccessToken
+}
+
+func TestRegisterCreatesViewer(t *testing.T) {
+	s := newTestServer(t)
+	resp := s.do(http.MethodPost, "/api/user/register", "", gin.H{
+		"username": "alice", "password": testPassword, "email": "alice@example.com",
+	}, nil)
+	s.expect(resp, http.StatusOK, "注册")
+
+	user, err := s.repos.Users.GetByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleViewer {
+		t.Fatalf("注册用户的角色为 %q，期望 %q", user.Role, models.RoleViewer)
+	}
+	admin, err := s.repos.Users.GetByUsername(context.Background(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != models.RoleAdmin {
+		t.Fatalf("配置的初始管理员角色为 %q，期望 %q", admin.Role, models.RoleAdmin)
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	authorToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+	viewerToken := s.addUser(adminToken, "bob", models.RoleViewer)
+
+	article := gin.H{"title": "标题", "content": "正文"}
+	s.expect(s.do(http.MethodPost, "/api/article/add", "", article, nil), http.StatusUnauthorized, "未登录创建文章")
+	s.expect(s.do(http.MethodPost, "/api/article/add", viewerToken, article, nil), http.StatusForbidden, "访客创建文章")
+	s.expect(s.do(http.MethodPost, "/api/article/add", authorToken, article, nil), http.StatusOK, "作者创建文章")
+
+	// 角色管理只有管理员可以使用
+	assign := gin.H{"id": 3, "role": models.RoleEditor}
+	s.expect(s.do(http.MethodPost, "/api/user/role/assign", authorToken, assign, nil), http.StatusForbidden, "作者分配角色")
+	s.expect(s.do(http.MethodPost, "/api/user/role/assign", adminToken, assign, nil), http.StatusOK, "管理员分配角色")
+
+	// 角色在每次请求时读取，修改后无需重新登录即可生效
+	s.expect(s.do(http.MethodPost, "/api/article/add", viewerToken, article, nil), http.StatusOK, "升级为编辑后创建文章")
+}
+
+func TestArticleOwnership(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	aliceToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+	bobToken := s.addUser(adminToken, "bob", models.RoleAuthor)
+	editorToken := s.addUser(adminToken, "carol", models.RoleEditor)
+
+	s.expect(s.do(http.MethodPost, "/api/article/add", aliceToken, gin.H{"title": "标题", "content": "正文"}, nil), http.StatusOK, "创建文章")
+
+	// 作者只能编辑和删除自己的文章，编辑可以编辑所有文章
+	edit := gin.H{"id": 1, "title": "新标题", "content": "正文"}
+	s.expect(s.do(http.MethodPost, "/api/article/edit", bobToken, edit, nil), http.StatusForbidden, "其他作者编辑")
+	s.expect(s.do(http.MethodPost, "/api/article/delete", bobToken, gin.H{"id": 1}, nil), http.StatusForbidden, "其他作者删除")
+	s.expect(s.do(http.MethodPost, "/api/article/edit", aliceToken, edit, nil), http.StatusOK, "创建人编辑")
+	s.expect(s.do(http.MethodPost, "/api/article/edit", editorToken, edit, nil), http.StatusOK, "编辑修改他人的文章")
+	s.expect(s.do(http.MethodPost, "/api/article/edit", bobToken, gin.H{"id": 99, "title": "标题"}, nil), http.StatusNotFound, "编辑不存在的文章")
+	s.expect(s.do(http.MethodPost, "/api/article/delete", aliceToken, gin.H{"id": 1}, nil), http.StatusOK, "创建人删除")
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	s := newTestServer(t)
+	first := s.login("admin")
+
+	var second auth.TokenPair
+	resp := s.do(http.MethodPost, "/api/user/token/refresh", "", gin.H{"refresh_token": first.RefreshToken}, &second)
+	s.expect(resp, http.StatusOK, "刷新令牌")
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", second.AccessToken, gin.H{}, nil), http.StatusOK, "使用新令牌")
+
+	// 重放已轮换的刷新令牌后，整个会话失效，新签发的访问令牌也立即失效
+	resp = s.do(http.MethodPost, "/api/user/token/refresh", "", gin.H{"refresh_token": first.RefreshToken}, nil)
+	s.expect(resp, http.StatusUnauthorized, "重放刷新令牌")
+	resp = s.do(http.MethodPost, "/api/user/token/refresh", "", gin.H{"refresh_token": second.RefreshToken}, nil)
+	s.expect(resp, http.StatusUnauthorized, "会话撤销后刷新")
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", second.AccessToken, gin.H{}, nil), http.StatusUnauthorized, "会话撤销后访问")
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	s := newTestServer(t)
+	first := s.login("admin")
+	second := s.login("admin")
+
+	// 退出登录后，该会话尚未过期的访问令牌立即失效，刷新令牌不能再使用
+	s.expect(s.do(http.MethodPost, "/api/user/logout", "", gin.H{"refresh_token": first.RefreshToken}, nil), http.StatusOK, "退出登录")
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", first.AccessToken, gin.H{}, nil), http.StatusUnauthorized, "退出后访问")
+	s.expect(s.do(http.MethodPost, "/api/user/token/refresh", "", gin.H{"refresh_token": first.RefreshToken}, nil), http.StatusUnauthorized, "退出后刷新")
+
+	// 其他会话不受影响，注销全部会话后同样失效
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", second.AccessToken, gin.H{}, nil), http.StatusOK, "其他会话访问")
+	s.expect(s.do(http.MethodPost, "/api/user/session/revoke_all", second.AccessToken, gin.H{}, nil), http.StatusOK, "注销全部会话")
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", second.AccessToken, gin.H{}, nil), http.StatusUnauthorized, "注销全部会话后访问")
+}
+
+func TestRestrictedAccount(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	user, err := s.repos.Users.GetByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Status, user.StatusReason = models.UserStatusRestricted, "测试"
+	if err := s.repos.Users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	token := s.login("alice").AccessToken
+
+	for _, path := range []string{"/api/user/2fa/setup", "/api/user/locale", "/api/user/email/verify/send", "/api/article/add"} {
+		resp := s.do(http.MethodPost, path, token, gin.H{"locale": "en-US", "title": "标题", "content": "正文"}, nil)
+		s.expect(resp, http.StatusForbidden, path)
+		if resp.Code != models.CodeAccountRestricted {
+			t.Fatalf("%s code = %q，期望 %q", path, resp.Code, models.CodeAccountRestricted)
+		}
+	}
+	// 浏览和注销自己的会话不受限制，管理其他用户的会话同样被拒绝
+	s.expect(s.do(http.MethodPost, "/api/user/2fa/status", token, gin.H{}, nil), http.StatusOK, "查看两步验证状态")
+	s.expect(s.do(http.MethodPost, "/api/user/session/revoke_all", token, gin.H{"user_id": 1}, nil), http.StatusForbidden, "注销其他用户的会话")
+	s.expect(s.do(http.MethodPost, "/api/user/session/revoke_all", token, gin.H{}, nil), http.StatusOK, "注销自己的会话")
+}
+
+func TestCancelledAccount(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	token := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	user, err := s.repos.Users.GetByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Status, user.StatusReason = models.UserStatusCancelled, "测试"
+	if err := s.repos.Users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	// 注销后已签发的令牌立即失效，也不能再登录
+	resp := s.do(http.MethodPost, "/api/user/session/list", token, gin.H{}, nil)
+	s.expect(resp, http.StatusForbidden, "注销后访问")
+	if resp.Code != models.CodeAccountCancelled {
+		t.Fatalf("code = %q，期望 %q", resp.Code, models.CodeAccountCancelled)
+	}
+	resp = s.do(http.MethodPost, "/api/user/login", "", gin.H{"username": "alice", "password": testPassword}, nil)
+	s.expect(resp, http.StatusForbidden, "注销后登录")
+}
+
+func TestRestrictionExpires(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	token := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	// 解除时间已过的限制不再生效，不需要等待管理员手动恢复
+	user, err := s.repos.Users.GetByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Status, user.StatusReason = models.UserStatusRestricted, "测试"
+	user.RestrictedUntil = time.Now().Add(-time.Minute).Format(models.TimeLayout)
+	if err := s.repos.Users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do(http.MethodPost, "/api/article/add", token, gin.H{"title": "标题", "content": "正文"}, nil), http.StatusOK, "限制到期后创建文章")
+}
+
+func TestResetPasswordRequiresChange(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	oldToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	var reset struct {
+		NewPassword string `json:"newPassword"`
+	}
+	s.expect(s.do(http.MethodPost, "/api/user/password/reset", adminToken, gin.H{"id": 2}, &reset), http.StatusOK, "重置密码")
+	if reset.NewPassword == "" || reset.NewPassword == "123456" {
+		t.Fatalf("临时密码为 %q，期望随机生成", reset.NewPassword)
+	}
+
+	// 重置后原有的登录失效，用临时密码登录后只能修改密码
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", oldToken, gin.H{}, nil), http.StatusUnauthorized, "重置后使用原令牌")
+	var pair auth.TokenPair
+	resp := s.do(http.MethodPost, "/api/user/login", "", gin.H{"username": "alice", "password": reset.NewPassword}, &pair)
+	s.expect(resp, http.StatusOK, "使用临时密码登录")
+	resp = s.do(http.MethodPost, "/api/user/session/list", pair.AccessToken, gin.H{}, nil)
+	s.expect(resp, http.StatusForbidden, "修改密码前访问其他接口")
+	if resp.Code != models.CodePasswordChangeRequired {
+		t.Fatalf("code = %q，期望 %q", resp.Code, models.CodePasswordChangeRequired)
+	}
+
+	resp = s.do(http.MethodPost, "/api/user/password/change", pair.AccessToken, gin.H{"password": reset.NewPassword, "newPassword": "Zyxwvu2@ab"}, &pair)
+	s.expect(resp, http.StatusOK, "修改密码")
+	s.expect(s.do(http.MethodPost, "/api/user/session/list", pair.AccessToken, gin.H{}, nil), http.StatusOK, "修改密码后访问")
+}
+
+func TestUserListRequiresUserManage(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	authorToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	// 用户列表包含邮箱和验证状态，只有管理用户的角色可以查询
+	s.expect(s.do(http.MethodPost, "/api/user/list", "", gin.H{}, nil), http.StatusUnauthorized, "未登录查询用户列表")
+	s.expect(s.do(http.MethodPost, "/api/user/list", authorToken, gin.H{"verified_email": false}, nil), http.StatusForbidden, "作者查询用户列表")
+	s.expect(s.do(http.MethodPost, "/api/user/list", adminToken, gin.H{"verified_email": false}, nil), http.StatusOK, "管理员查询用户列表")
+}
+
+func TestForgotPasswordDoesNotRevealEmail(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	// 已注册但未验证的邮箱和不存在的邮箱返回相同的结果
+	registered := s.do(http.MethodPost, "/api/user/password/forgot", "", gin.H{"email": "alice@example.com"}, nil)
+	unknown := s.do(http.MethodPost, "/api/user/password/forgot", "", gin.H{"email": "nobody@example.com"}, nil)
+	s.expect(registered, http.StatusOK, "已注册的邮箱")
+	s.expect(unknown, http.StatusOK, "不存在的邮箱")
+	if registered.Message != unknown.Message {
+		t.Fatalf("响应消息不同: %q 和 %q", registered.Message, unknown.Message)
+	}
+
+	resp := s.do(http.MethodPost, "/api/user/password/forgot/reset", "", gin.H{"token": "invalid", "newPassword": "Zyxwvu2@ab"}, nil)
+	s.expect(resp, http.StatusBadRequest, "使用无效的链接重置密码")
+}
+
+func TestTwoFactorStepUpLockout(t *testing.T) {
+	s := newTestServer(t)
+	token := s.login("admin").AccessToken
+
+	// 关闭两步验证时密码错误同样计入失败次数，达到上限后即使密码正确也被拒绝
+	for i := 0; i < config.Conf.Login.MaxFailures; i++ {
+		resp := s.do(http.MethodPost, "/api/user/2fa/disable", token, gin.H{"password": "wrong", "code": "000000"}, nil)
+		s.expect(resp, http.StatusUnauthorized, "密码错误")
+	}
+	resp := s.do(http.MethodPost, "/api/user/2fa/disable", token, gin.H{"password": testPassword, "code": "000000"}, nil)
+	s.expect(resp, http.StatusTooManyRequests, "超过失败次数")
+	resp = s.do(http.MethodPost, "/api/user/2fa/recovery_codes", token, gin.H{"code": "000000"}, nil)
+	s.expect(resp, http.StatusTooManyRequests, "超过失败次数后重新生成恢复码")
+
+	// 与登录分开计数，不影响正常登录
+	s.login("admin")
+}
+
+func TestArticleEditChecksOwnerFirst(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	aliceToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+	bobToken := s.addUser(adminToken, "bob", models.RoleAuthor)
+
+	resp := s.do(http.MethodPost, "/api/v2/articles", aliceToken, gin.H{"title": "标题", "content": "正文"}, nil)
+	s.expect(resp, http.StatusCreated, "创建文章")
+
+	// 数据不合法时，非创建人同样先返回 403，不泄露校验结果
+	resp = s.do(http.MethodPut, "/api/v2/articles/1", bobToken, gin.H{"title": ""}, nil)
+	s.expect(resp, http.StatusForbidden, "非创建人编辑")
+	resp = s.do(http.MethodPut, "/api/v2/articles/1", aliceToken, gin.H{"title": ""}, nil)
+	s.expect(resp, http.StatusBadRequest, "创建人提交不合法的数据")
+
+	// PATCH 在解析请求之前检查权限，无法解析的请求同样先返回 403
+	req := httptest.NewRequest(http.MethodPatch, "/api/v2/articles/1", strings.NewReader("{"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+bobToken)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("非创建人提交无法解析的 PATCH 请求: 状态码 = %d，期望 %d", w.Code, http.StatusForbidden)
+	}
+	s.expect(s.do(http.MethodPatch, "/api/v2/articles/99", bobToken, gin.H{"title": "标题"}, nil), http.StatusNotFound, "PATCH 不存在的文章")
+}
+
+func TestArticleReplaceClearsTerms(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	s.expect(s.do(http.MethodPost, "/api/v2/tags", adminToken, gin.H{"name": "go"}, nil), http.StatusCreated, "创建标签")
+	s.expect(s.do(http.MethodPost, "/api/v2/categories", adminToken, gin.H{"name": "backend"}, nil), http.StatusCreated, "创建分类")
+	resp := s.do(http.MethodPost, "/api/v2/articles", adminToken, gin.H{
+		"title": "标题", "content": "正文", "tag_ids": []int{1}, "category_ids": []int{1},
+	}, nil)
+	s.expect(resp, http.StatusCreated, "创建文章")
+
+	// PATCH 没有传入的字段保持不变
+	var article models.Article
+	resp = s.do(http.MethodPatch, "/api/v2/articles/1", adminToken, gin.H{"title": "新标题"}, &article)
+	s.expect(resp, http.StatusOK, "PATCH 文章")
+	if len(article.TagIDs) != 1 || len(article.CategoryIDs) != 1 {
+		t.Fatalf("PATCH 后标签 %v、分类 %v，期望保持不变", article.TagIDs, article.CategoryIDs)
+	}
+
+	// PUT 提交的是完整数据，没有传入的标签和分类被清空
+	resp = s.do(http.MethodPut, "/api/v2/articles/1", adminToken, gin.H{"title": "标题", "content": "正文"}, &article)
+	s.expect(resp, http.StatusOK, "PUT 文章")
+	if len(article.TagIDs) != 0 || len(article.CategoryIDs) != 0 {
+		t.Fatalf("PUT 后标签 %v、分类 %v，期望为空", article.TagIDs, article.CategoryIDs)
+	}
+}
+
+func TestUsersV2RequiresUserManage(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	authorToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	for _, path := range []string{"/api/v2/users", "/api/v2/users/1"} {
+		s.expect(s.do(http.MethodGet, path, "", nil, nil), http.StatusUnauthorized, "未登录 "+path)
+		s.expect(s.do(http.MethodGet, path, authorToken, nil, nil), http.StatusForbidden, "作者 "+path)
+		s.expect(s.do(http.MethodGet, path, adminToken, nil, nil), http.StatusOK, "管理员 "+path)
+	}
+}
+
+func TestAuthorCannotEditReviewedContent(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	aliceToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	resp := s.do(http.MethodPost, "/api/v2/articles", aliceToken, gin.H{"title": "标题", "content": "正文", "status": models.ArticleStatusSubmitted}, nil)
+	s.expect(resp, http.StatusCreated, "创建并提交文章")
+	s.expect(s.do(http.MethodPost, "/api/v2/articles/1/approve", adminToken, gin.H{}, nil), http.StatusOK, "审核通过")
+
+	// 审核通过后作者修改内容会绕过审核，返回 409
+	resp = s.do(http.MethodPatch, "/api/v2/articles/1", aliceToken, gin.H{"content": "未经审核的内容"}, nil)
+	s.expect(resp, http.StatusConflict, "作者修改审核通过的文章")
+	if resp.Code != models.CodeInvalidArticleTransition {
+		t.Fatalf("code = %q，期望 %q", resp.Code, models.CodeInvalidArticleTransition)
+	}
+
+	// 管理员修改后，作者也不能通过恢复历史版本改回去
+	s.expect(s.do(http.MethodPatch, "/api/v2/articles/1", adminToken, gin.H{"content": "修改后的内容"}, nil), http.StatusOK, "管理员修改")
+	resp = s.do(http.MethodPost, "/api/v2/articles/1/revisions/1/restore", aliceToken, gin.H{}, nil)
+	s.expect(resp, http.StatusConflict, "作者恢复历史版本")
+
+	// 发布并下线后作者可以修改
+	s.expect(s.do(http.MethodPost, "/api/v2/articles/1/publish", aliceToken, gin.H{}, nil), http.StatusOK, "发布")
+	s.expect(s.do(http.MethodPost, "/api/v2/articles/1/unpublish", aliceToken, gin.H{}, nil), http.StatusOK, "下线")
+	s.expect(s.do(http.MethodPatch, "/api/v2/articles/1", aliceToken, gin.H{"content": "下线后修改"}, nil), http.StatusOK, "下线后修改")
+}
+
+func TestArticleRevisionRestore(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	aliceToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+	bobToken := s.addUser(adminToken, "bob", models.RoleAuthor)
+
+	s.expect(s.do(http.MethodPost, "/api/v2/articles", aliceToken, gin.H{"title": "标题", "content": "第一行\n第二行"}, nil), http.StatusCreated, "创建文章")
+	s.expect(s.do(http.MethodPatch, "/api/v2/articles/1", aliceToken, gin.H{"title": "新标题", "content": "第一行\n修改后"}, nil), http.StatusOK, "修改文章")
+
+	var diff struct {
+		Fields  []struct{ Field, Old, New string } `json:"fields"`
+		Content []utils.DiffLine                   `json:"content"`
+	}
+	s.expect(s.do(http.MethodGet, "/api/v2/articles/1/revisions/diff?from=1&to=2", aliceToken, nil, &diff), http.StatusOK, "比较版本")
+	if len(diff.Fields) != 1 || diff.Fields[0].Field != "title" || diff.Fields[0].New != "新标题" {
+		t.Fatalf("字段差异 = %+v，期望只有标题变化", diff.Fields)
+	}
+	if len(diff.Content) != 3 {
+		t.Fatalf("正文差异 = %+v，期望一行相同、一行删除、一行新增", diff.Content)
+	}
+
+	// 其他作者不能查看和恢复历史版本
+	s.expect(s.do(http.MethodGet, "/api/v2/articles/1/revisions", bobToken, nil, nil), http.StatusForbidden, "其他作者查看历史版本")
+	s.expect(s.do(http.MethodPost, "/api/v2/articles/1/revisions/1/restore", bobToken, gin.H{}, nil), http.StatusForbidden, "其他作者恢复历史版本")
+
+	// 恢复后保存为新版本，原有版本保持不变
+	var article models.Article
+	s.expect(s.do(http.MethodPost, "/api/v2/articles/1/revisions/1/restore", aliceToken, gin.H{}, &article), http.StatusOK, "恢复历史版本")
+	if article.Title != "标题" || article.Content != "第一行\n第二行" {
+		t.Fatalf("恢复后 = %q %q，期望第一个版本的内容", article.Title, article.Content)
+	}
+	var revisions []models.ArticleRevisionListItem
+	s.expect(s.do(http.MethodGet, "/api/v2/articles/1/revisions", aliceToken, nil, &revisions), http.StatusOK, "查看历史版本")
+	if len(revisions) != 3 || revisions[0].RestoredFrom != 1 {
+		t.Fatalf("历史版本 = %+v，期望 3 个版本且最新的恢复自版本 1", revisions)
+	}
+}
+
+func TestArticleContentRendered(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+
+	// 请求中传入的 content_html 被忽略，由服务端渲染并过滤
+	var article models.Article
+	resp := s.do(http.MethodPost, "/api/v2/articles", adminToken, gin.H{
+		"title":        "标题",
+		"content":      "# 标题\n\n<script>alert(1)</script>\n\n[链接](javascript:alert(2))",
+		"content_html": "<script>alert(3)</script>",
+	}, &article)
+	s.expect(resp, http.StatusCreated, "创建文章")
+	if strings.Contains(article.ContentHTML, "<script") || strings.Contains(article.ContentHTML, "javascript:") {
+		t.Fatalf("content_html 没有过滤: %s", article.ContentHTML)
+	}
+	if !strings.Contains(article.ContentHTML, `<h1 id="标题">标题</h1>`) || len(article.TOC) != 1 {
+		t.Fatalf("content_html = %s，toc = %+v", article.ContentHTML, article.TOC)
+	}
+}
+
+func TestTermSlugAndUniqueness(t *testing.T) {
+	s := newTestServer(t)
+	adminToken := s.login("admin").AccessToken
+	authorToken := s.addUser(adminToken, "alice", models.RoleAuthor)
+
+	// 没有填写别名时根据英文名称生成
+	var term models.Term
+	s.expect(s.do(http.MethodPost, "/api/v2/tags", adminToken, gin.H{"name": "  Go Modules "}, &term), http.StatusCreated, "创建标签")
+	if term.Name != "Go Modules" || term.Slug != "go-modules" {
+		t.Fatalf("标签 = %q/%q，期望 Go Modules/go-modules", term.Name, term.Slug)
+	}
+
+	cases := []struct {
+		name   string
+		body   gin.H
+		status int
+	}{
+		{"中文名称需要填写别名", gin.H{"name": "后端"}, http.StatusBadRequest},
+		{"别名格式错误", gin.H{"name": "后端", "slug": "Back End"}, http.StatusBadRequest},
+		{"名称重复（不区分大小写）", gin.H{"name": "go modules", "slug": "modules"}, http.StatusConflict},
+		{"别名重复", gin.H{"name": "Go 模块", "slug": "go-modules"}, http.StatusConflict},
+		{"中文名称和别名", gin.H{"name": "后端", "slug": "backend"}, http.StatusCreated},
+	}
+	for _, tc := range cases {
+		s.expect(s.do(http.MethodPost, "/api/v2/tags", adminToken, tc.body, nil), tc.status, tc.name)
+	}
+
+	// 标签和分类分开管理，同名的分类不冲突；作者不能管理标签
+	s.expect(s.do(http.MethodPost, "/api/v2/categories", adminToken, gin.H{"name": "Go Modules"}, nil), http.StatusCreated, "创建同名分类")
+	s.expect(s.do(http.MethodPost, "/api/v2/tags", authorToken, gin.H{"name": "Rust"}, nil), http.StatusForbidden, "作者创建标签")
+
+	// 文章引用不存在的标签时拒绝保存
+	resp := s.do(http.MethodPost, "/api/v2/articles", adminToken, gin.H{"title": "标题", "content": "正文", "tag_ids": []int{99}}, nil)
+	s.expect(resp, http.StatusBadRequest, "引用不存在的标签")
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"backend/config"
+	"backend/repository"
+	"context"
+	"log"
+	"time"
+)
+
+// runTimeout 单次检查的超时时间，避免数据库无响应时任务一直阻塞
+const runTimeout = 30 * time.Second
+
+// ArticleScheduler 定时检查并发布、下线到期的文章
+type ArticleScheduler struct {
+	articles  repository.ArticleRepository
+	interval  time.Duration
+	batchSize int
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewArticleScheduler 创建文章定时任务，调用 Start 后开始运行
+func NewArticleScheduler(articles repository.ArticleRepository, conf config.SchedulerConfig) *ArticleScheduler {
+	return &ArticleScheduler{
+		articles:  articles,
+		interval:  conf.Interval.Duration,
+		batchSize: conf.BatchSize,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start 在后台运行定时任务，启动时立即检查一次，之后每隔 interval 检查一次
+func (s *ArticleScheduler) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.run()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// run 执行一次检查，出错时只记录日志，下次检查时重试
+func (s *ArticleScheduler) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	transitions, err := s.articles.ApplySchedule(ctx, time.Now(), s.batchSize)
+	for _, t := range transitions {
+		log.Printf("Scheduled %s of article %d applied", t.Action, t.ArticleID)
+	}
+	if err != nil {
+		log.Printf("Failed to apply article schedule: %v", err)
+	}
+}
+
+// Close 停止定时任务并等待正在进行的检查完成，作为服务关闭钩子使用
+func (s *ArticleScheduler) Close(ctx context.Context) error {
+	close(s.stop)
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// CertReloader 持有当前使用的证书，收到 SIGHUP 时从磁盘重新加载
+// 新证书只对之后的 TLS 握手生效，已建立的连接不受影响
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// NewCertReloader 加载证书和私钥，文件无效时返回错误
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 从磁盘重新读取证书，失败时继续使用原证书
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载证书失败: %w", err)
+	}
+	r.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate 供 tls.Config.GetCertificate 使用，每次握手时返回当前证书
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// WatchSIGHUP 在后台监听 SIGHUP 信号并重新加载证书，返回用于停止监听的函数
+func (r *CertReloader) WatchSIGHUP() (stop func()) {
+	sighup := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-sighup:
+				if err := r.Reload(); err != nil {
+					log.Printf("Certificate reload failed, keeping the current certificate: %v", err)
+					continue
+				}
+				log.Printf("Certificate reloaded from %s", r.certFile)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sighup)
+		close(done)
+	}
+}
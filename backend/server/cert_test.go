@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert 生成自签名证书并写入 dir，返回证书和私钥的路径
+func writeSelfSignedCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// currentCommonName 返回 reloader 当前证书的 CommonName
+func currentCommonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "old.test")
+
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() 出错: %v", err)
+	}
+	if got := currentCommonName(t, r); got != "old.test" {
+		t.Fatalf("CommonName = %q，期望 %q", got, "old.test")
+	}
+
+	// 替换证书文件后重新加载
+	writeSelfSignedCert(t, dir, "new.test")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() 出错: %v", err)
+	}
+	if got := currentCommonName(t, r); got != "new.test" {
+		t.Fatalf("重新加载后 CommonName = %q，期望 %q", got, "new.test")
+	}
+
+	// 文件损坏时返回错误并继续使用原证书
+	if err := os.WriteFile(certFile, []byte("broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("证书文件无效时 Reload() 应该返回错误")
+	}
+	if got := currentCommonName(t, r); got != "new.test" {
+		t.Fatalf("加载失败后 CommonName = %q，期望保留 %q", got, "new.test")
+	}
+}
+
+func TestNewCertReloaderMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")); err == nil {
+		t.Fatal("证书文件不存在时 NewCertReloader() 应该返回错误")
+	}
+}
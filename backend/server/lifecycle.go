@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// shutdownHook 在服务关闭时执行的清理函数
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	hooksMu sync.Mutex
+	hooks   []shutdownHook
+)
+
+// OnShutdown 注册一个关闭时执行的清理函数，例如关闭数据库连接池、停止后台任务
+// 清理函数在 HTTP 请求处理完毕后按注册的相反顺序执行（与 defer 相同），
+// 因此应先注册底层资源（数据库），再注册依赖它的后台任务
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, shutdownHook{name: name, fn: fn})
+}
+
+// runShutdownHooks 按注册的相反顺序执行清理函数，单个函数失败不影响其余函数
+func runShutdownHooks(ctx context.Context) {
+	hooksMu.Lock()
+	pending := hooks
+	hooks = nil
+	hooksMu.Unlock()
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		h := pending[i]
+		if err := h.fn(ctx); err != nil {
+			log.Printf("Shutdown hook %q failed: %v", h.name, err)
+			continue
+		}
+		log.Printf("Shutdown hook %q done", h.name)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"backend/config"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShutdownHooksRunInReverseOrder(t *testing.T) {
+	var order []string
+	record := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			order = append(order, name)
+			return err
+		}
+	}
+	OnShutdown("database", record("database", nil))
+	OnShutdown("mail", record("mail", errors.New("flush failed")))
+	OnShutdown("scheduler", record("scheduler", nil))
+
+	runShutdownHooks(context.Background())
+
+	// 后注册的先执行，单个清理函数失败不影响其余函数
+	want := []string{"scheduler", "mail", "database"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("执行顺序 = %v，期望 %v", order, want)
+	}
+
+	// 清理函数只执行一次
+	order = nil
+	runShutdownHooks(context.Background())
+	if len(order) != 0 {
+		t.Fatalf("第二次执行了 %v，期望不再执行", order)
+	}
+}
+
+func TestShutdownDrainsRequestsBeforeHooks(t *testing.T) {
+	conf := config.ServerConfig{ShutdownTimeout: config.Duration{Duration: 2 * time.Second}}
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		close(finished)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newHTTPServer(conf, ln.Addr().String(), handler)
+	go srv.Serve(ln)
+
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+
+	var hookSawFinished bool
+	var hookDeadline time.Time
+	OnShutdown("check", func(ctx context.Context) error {
+		select {
+		case <-finished:
+			hookSawFinished = true
+		default:
+		}
+		hookDeadline, _ = ctx.Deadline()
+		return nil
+	})
+
+	shutdown(conf, []*http.Server{srv})
+
+	if !hookSawFinished {
+		t.Fatal("清理函数在进行中的请求完成之前执行")
+	}
+	// 清理函数有自己的超时时间，不受等待请求所用时间的影响
+	if remaining := time.Until(hookDeadline); remaining < time.Second {
+		t.Fatalf("清理函数剩余时间 %s，期望接近 shutdown_timeout", remaining)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	conf := config.ServerConfig{ShutdownTimeout: config.Duration{Duration: 100 * time.Millisecond}}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newHTTPServer(conf, ln.Addr().String(), handler)
+	go srv.Serve(ln)
+
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+
+	hookRan := false
+	OnShutdown("check", func(context.Context) error {
+		hookRan = true
+		return nil
+	})
+
+	// 请求一直没有完成，超时后强制关闭连接并继续执行清理函数
+	begin := time.Now()
+	shutdown(conf, []*http.Server{srv})
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("shutdown 耗时 %s，期望在超时后返回", elapsed)
+	}
+	if !hookRan {
+		t.Fatal("超时后没有执行清理函数")
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net"
+	"net/http"
+)
+
+// redirectHandler 将所有 HTTP 请求 301 重定向到 HTTPS 地址
+// tlsAddr 为 HTTPS 监听地址，端口不是 443 时会保留在重定向地址中
+func redirectHandler(tlsAddr string) http.Handler {
+	_, tlsPort, _ := net.SplitHostPort(tlsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "" && tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		name    string
+		tlsAddr string
+		host    string
+		path    string
+		want    string
+	}{
+		{"默认端口", ":443", "example.com", "/api/articles?page=2", "https://example.com/api/articles?page=2"},
+		{"去掉 HTTP 端口", ":443", "example.com:80", "/", "https://example.com/"},
+		{"保留非标准 HTTPS 端口", ":8443", "localhost:8080", "/login", "https://localhost:8443/login"},
+		{"IPv6 地址", ":8443", "[::1]:8080", "/", "https://[::1]:8443/"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://"+tc.host+tc.path, nil)
+			req.Host = tc.host
+			w := httptest.NewRecorder()
+			redirectHandler(tc.tlsAddr).ServeHTTP(w, req)
+
+			if w.Code != http.StatusMovedPermanently {
+				t.Fatalf("状态码 = %d，期望 %d", w.Code, http.StatusMovedPermanently)
+			}
+			if got := w.Header().Get("Location"); got != tc.want {
+				t.Errorf("Location = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
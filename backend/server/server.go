@@ -0,0 +1,105 @@
+package server
+
+import (
+	"backend/config"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+)
+
+// Run 根据配置启动 HTTP 或 HTTPS 服务，阻塞直到服务退出
+// 启用 TLS 时证书支持 SIGHUP 热加载，并可选地启动一个 HTTP 监听将请求重定向到 HTTPS
+// 收到 SIGINT/SIGTERM 后停止接收新连接，在 shutdown_timeout 内等待进行中的请求完成，
+// 然后在新的 shutdown_timeout 内执行 OnShutdown 注册的清理函数
+func Run(conf config.ServerConfig, handler http.Handler) error {
+	srv := newHTTPServer(conf, conf.Addr, handler)
+	servers := []*http.Server{srv}
+
+	var listen func() error
+	if conf.TLS.Enabled {
+		reloader, err := NewCertReloader(conf.TLS.CertFile, conf.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		stopWatch := reloader.WatchSIGHUP()
+		defer stopWatch()
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+		// 证书由 TLSConfig.GetCertificate 提供，这里不需要传入文件路径
+		listen = func() error { return srv.ListenAndServeTLS("", "") }
+		log.Printf("HTTPS server listening on %s", conf.Addr)
+
+		if conf.TLS.RedirectAddr != "" {
+			redirect := newHTTPServer(conf, conf.TLS.RedirectAddr, redirectHandler(conf.Addr))
+			servers = append(servers, redirect)
+			go func() {
+				log.Printf("HTTP redirect server listening on %s", conf.TLS.RedirectAddr)
+				if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Printf("HTTP redirect server stopped: %v", err)
+				}
+			}()
+		}
+	} else {
+		listen = srv.ListenAndServe
+		log.Printf("HTTP server listening on %s", conf.Addr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- listen()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// 监听失败（如端口被占用），仍然执行清理后返回错误
+		shutdown(conf, servers)
+		return err
+	case <-ctx.Done():
+		stop() // 再次收到信号时直接退出
+		log.Printf("Shutdown signal received, draining in-flight requests (timeout %s)", conf.ShutdownTimeout.Duration)
+	}
+
+	shutdown(conf, servers)
+	log.Printf("Server exited")
+	return nil
+}
+
+// newHTTPServer 创建带有超时设置的 http.Server
+func newHTTPServer(conf config.ServerConfig, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: conf.ReadHeaderTimeout.Duration,
+		ReadTimeout:       conf.ReadTimeout.Duration,
+		WriteTimeout:      conf.WriteTimeout.Duration,
+		IdleTimeout:       conf.IdleTimeout.Duration,
+	}
+}
+
+// shutdown 在超时时间内关闭所有监听，然后执行清理函数
+// 清理函数另外有一个同样长的超时时间，等待请求耗尽超时时间后仍然可以发送邮件、关闭数据库
+func shutdown(conf config.ServerConfig, servers []*http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout.Duration)
+	defer cancel()
+
+	for _, s := range servers {
+		if err := s.Shutdown(ctx); err != nil {
+			log.Printf("Server %s shutdown: %v", s.Addr, err)
+			s.Close()
+		}
+	}
+
+	hookCtx, hookCancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout.Duration)
+	defer hookCancel()
+	runShutdownHooks(hookCtx)
+}
@@ -0,0 +1,148 @@
+package utils
+
+import "strings"
+
+// 差异中每一行的类型
+const (
+	DiffEqual  = "equal"  // 两边相同
+	DiffInsert = "insert" // 只在新文本中
+	DiffDelete = "delete" // 只在旧文本中
+)
+
+// maxDiffEdits 逐行比较时允许的最大修改行数，超过后不再查找最短差异，
+// 直接把不同的部分整体作为删除和新增，避免两段完全不同的长文本占用过多内存
+const maxDiffEdits = 2000
+
+// DiffLine 行级差异中的一行
+type DiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"` // 在旧文本中的行号，从 1 开始，新增的行没有该字段
+	NewLine int    `json:"new_line,omitempty"` // 在新文本中的行号，从 1 开始，删除的行没有该字段
+	Text    string `json:"text"`
+}
+
+// DiffLines 逐行比较两段文本，返回把旧文本修改为新文本的最短差异（Myers 算法）
+// 相同的行也包含在结果中，调用方可以据此显示上下文
+func DiffLines(oldText, newText string) []DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	// 先去掉相同的开头和结尾，只比较中间不同的部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Op: DiffEqual, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+	for _, line := range diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := suffix; i > 0; i-- {
+		oldLine, newLine := len(a)-i, len(b)-i
+		lines = append(lines, DiffLine{Op: DiffEqual, OldLine: oldLine + 1, NewLine: newLine + 1, Text: a[oldLine]})
+	}
+	return lines
+}
+
+// splitLines 按行拆分文本，统一换行符，末尾的换行不产生空行
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffMiddle 使用 Myers 算法比较去掉相同首尾后的部分，行号从 1 开始
+func diffMiddle(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+	// v[offset+k] 为对角线 k 上能到达的最远 x，trace[d] 保存第 d 步开始前 [-d, d] 范围内的 v
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // 从对角线 k+1 向下移动，即新增一行
+			} else {
+				x = v[offset+k-1] + 1 // 从对角线 k-1 向右移动，即删除一行
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+// backtrackDiff 从终点沿 trace 回溯出每一步的操作
+func backtrackDiff(trace [][]int, a, b []string) []DiffLine {
+	x, y := len(a), len(b)
+	var reversed []DiffLine
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d] // 下标 k+d 对应对角线 k
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Op: DiffEqual, OldLine: x, NewLine: y, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, DiffLine{Op: DiffInsert, NewLine: y, Text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, DiffLine{Op: DiffDelete, OldLine: x, Text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, DiffLine{Op: DiffEqual, OldLine: x, NewLine: y, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	lines := make([]DiffLine, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		lines = append(lines, reversed[i])
+	}
+	return lines
+}
+
+// replaceAll 把旧的行全部作为删除、新的行全部作为新增
+func replaceAll(a, b []string) []DiffLine {
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, DiffLine{Op: DiffDelete, OldLine: i + 1, Text: text})
+	}
+	for i, text := range b {
+		lines = append(lines, DiffLine{Op: DiffInsert, NewLine: i + 1, Text: text})
+	}
+	return lines
+}
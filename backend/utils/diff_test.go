@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyDiff 按差异还原旧文本和新文本，同时检查行号是否连续
+func applyDiff(t *testing.T, lines []DiffLine) (oldText, newText string) {
+	t.Helper()
+	var a, b []string
+	for _, line := range lines {
+		if line.Op != DiffInsert {
+			a = append(a, line.Text)
+			if line.OldLine != len(a) {
+				t.Fatalf("旧行号 = %d，期望 %d: %+v", line.OldLine, len(a), line)
+			}
+		}
+		if line.Op != DiffDelete {
+			b = append(b, line.Text)
+			if line.NewLine != len(b) {
+				t.Fatalf("新行号 = %d，期望 %d: %+v", line.NewLine, len(b), line)
+			}
+		}
+	}
+	return strings.Join(a, "\n"), strings.Join(b, "\n")
+}
+
+// countEdits 统计新增和删除的行数
+func countEdits(lines []DiffLine) int {
+	n := 0
+	for _, line := range lines {
+		if line.Op != DiffEqual {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		old, new  string
+		wantEdits int
+	}{
+		{"相同", "a\nb\nc", "a\nb\nc", 0},
+		{"都为空", "", "", 0},
+		{"新增全部", "", "a\nb", 2},
+		{"删除全部", "a\nb", "", 2},
+		{"修改中间一行", "a\nb\nc", "a\nx\nc", 2},
+		{"开头插入", "b\nc", "a\nb\nc", 1},
+		{"末尾删除", "a\nb\nc", "a\nb", 1},
+		{"移动一行", "a\nb\nc\nd", "b\nc\na\nd", 2},
+		{"重复的行", "a\nb\na\nb", "a\nb\nb\na\nb", 1},
+		{"交错修改", "a\nb\nc\nd\ne\nf", "a\nx\nc\ny\ne\nf\ng", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := DiffLines(tt.old, tt.new)
+			oldText, newText := applyDiff(t, lines)
+			if oldText != tt.old || newText != tt.new {
+				t.Fatalf("还原结果为 %q、%q，期望 %q、%q", oldText, newText, tt.old, tt.new)
+			}
+			if got := countEdits(lines); got != tt.wantEdits {
+				t.Fatalf("修改行数 = %d，期望 %d: %+v", got, tt.wantEdits, lines)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLineNumbers(t *testing.T) {
+	got := DiffLines("a\nb\nc", "a\nx\nc")
+	want := []DiffLine{
+		{Op: DiffEqual, OldLine: 1, NewLine: 1, Text: "a"},
+		{Op: DiffDelete, OldLine: 2, Text: "b"},
+		{Op: DiffInsert, NewLine: 2, Text: "x"},
+		{Op: DiffEqual, OldLine: 3, NewLine: 3, Text: "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffLines() = %+v，期望 %+v", got, want)
+	}
+}
+
+func TestDiffLinesNewlines(t *testing.T) {
+	// 换行符不同和末尾多一个换行都不算修改
+	if lines := DiffLines("a\r\nb\r\n", "a\nb"); countEdits(lines) != 0 {
+		t.Fatalf("DiffLines() = %+v，期望没有修改", lines)
+	}
+}
+
+func TestDiffLinesTooManyEdits(t *testing.T) {
+	// 超过 maxDiffEdits 时整体替换，结果仍然可以还原两段文本
+	var a, b []string
+	for i := 0; i < maxDiffEdits+10; i++ {
+		a = append(a, "old")
+		b = append(b, "new")
+	}
+	oldText, newText := strings.Join(a, "\n"), strings.Join(b, "\n")
+	lines := DiffLines(oldText, newText)
+	gotOld, gotNew := applyDiff(t, lines)
+	if gotOld != oldText || gotNew != newText {
+		t.Fatal("整体替换后无法还原两段文本")
+	}
+	if got, want := countEdits(lines), 2*len(a); got != want {
+		t.Fatalf("修改行数 = %d，期望 %d", got, want)
+	}
+}
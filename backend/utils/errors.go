@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"backend/i18n"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// 通用错误码，客户端应根据 code 而不是 message 判断错误类型
+// 业务相关的错误码（例如 account_cancelled、login_delayed）定义在各自的包中
+const (
+	CodeOK               = "ok"
+	CodeInvalidInput     = "invalid_input"     // 请求数据无法解析
+	CodeValidationFailed = "validation_failed" // 请求数据不符合校验规则，details 中列出具体字段
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeUnavailable      = "service_unavailable"
+	CodeInternal         = "internal_error"
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`           // 字段名，与请求中的 JSON 字段名一致
+	Rule    string `json:"rule"`            // 未通过的规则，例如 required、max
+	Param   string `json:"param,omitempty"` // 规则的参数，例如 max=20 中的 20
+	Message string `json:"message,omitempty"`
+
+	source validator.FieldError // 验证器返回的原始错误，用于按请求的语言生成 Message
+	custom string               // 字段 msg 标签中的中文提示，优先于验证器的提示
+}
+
+// AppError 返回给客户端的错误
+// Message 为中文消息，返回时按请求的语言翻译，Params 用于替换翻译中的占位符；
+// Err 是内部原因，只记录日志，不会返回给客户端
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Params  map[string]string
+	Details []FieldError
+	Data    interface{}
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode 返回错误码，JSONResponse 用它填充响应中的 code
+func (e *AppError) ErrorCode() string {
+	return e.Code
+}
+
+// NewError 创建错误，code 为空时按状态码使用通用错误码
+func NewError(status int, code, message string) *AppError {
+	if code == "" {
+		code = CodeForStatus(status)
+	}
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+// Internal 服务器内部错误，客户端只能看到通用的提示和请求 ID，err 记录在日志中
+func Internal(err error) *AppError {
+	return &AppError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "服务器内部错误，请稍后重试", Err: err}
+}
+
+// NotFound 资源不存在
+func NotFound(message string) *AppError {
+	return &AppError{Status: http.StatusNotFound, Code: CodeNotFound, Message: message}
+}
+
+// InvalidInput 请求数据无法解析，字段类型错误时在 details 中指出字段
+func InvalidInput(err error) *AppError {
+	appErr := &AppError{Status: http.StatusBadRequest, Code: CodeInvalidInput, Message: "无效的输入", Err: err}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		appErr.Details = []FieldError{{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}}
+	}
+	return appErr
+}
+
+// Invalid 请求数据不符合校验规则，message 为返回给用户的提示，为空时使用第一个字段错误的提示
+func Invalid(message string, details ...FieldError) *AppError {
+	return &AppError{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: message, Details: details}
+}
+
+// WithData 设置错误响应的 data 字段
+func (e *AppError) WithData(data interface{}) *AppError {
+	e.Data = data
+	return e
+}
+
+// WithParams 设置错误码消息中占位符的值，例如 {"max_mb": "5"}
+func (e *AppError) WithParams(params map[string]string) *AppError {
+	e.Params = params
+	return e
+}
+
+// WithCause 记录错误的内部原因，只写入日志
+func (e *AppError) WithCause(err error) *AppError {
+	e.Err = err
+	return e
+}
+
+// CodeForStatus 返回状态码对应的通用错误码
+func CodeForStatus(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return CodeOK
+	case status == http.StatusUnauthorized:
+		return CodeUnauthorized
+	case status == http.StatusForbidden:
+		return CodeForbidden
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusConflict:
+		return CodeConflict
+	case status == http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case status == http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case status >= http.StatusInternalServerError:
+		return CodeInternal
+	default:
+		return CodeInvalidInput
+	}
+}
+
+// Fail 终止请求处理，由 ErrorMiddleware 统一把 err 转换为错误响应
+// err 不是 *AppError 时按内部错误处理，数据不存在（sql.ErrNoRows）按 404 处理
+func Fail(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// ErrorResponse 返回错误响应，消息按请求的语言翻译，响应中带有请求 ID，方便根据日志排查问题
+func ErrorResponse(c *gin.Context, err *AppError) {
+	data := err.Data
+	if data == nil {
+		data = gin.H{}
+	}
+	locale := c.GetString(LocaleKey)
+	body := gin.H{
+		"status":     err.Status,
+		"code":       err.Code,
+		"message":    i18n.Translate(locale, err.Code, err.Message, err.Params),
+		"data":       data,
+		"request_id": c.GetString(RequestIDKey),
+	}
+	if len(err.Details) > 0 {
+		details := make([]FieldError, len(err.Details))
+		for i, fe := range err.Details {
+			details[i] = translateFieldError(fe, locale)
+		}
+		body["details"] = details
+		if err.Message == "" && details[0].Message != "" {
+			body["message"] = details[0].Message
+		}
+	}
+	c.AbortWithStatusJSON(err.Status, body)
+}
@@ -1,26 +1,64 @@
 package utils
 
 import (
+	"backend/i18n"             // 引入多语言支持，按请求的语言翻译响应消息
 	"github.com/gin-gonic/gin" // 引入 Gin 框架，用于处理 HTTP 请求和响应
 )
 
+// RequestIDKey 请求 ID 在 Gin 上下文中的键，由 RequestIDMiddleware 设置
+const RequestIDKey = "requestID"
+
+// LocaleKey 请求使用的语言在 Gin 上下文中的键，由 LocaleMiddleware 设置，登录用户设置了语言偏好时由 JWT 中间件覆盖
+const LocaleKey = "locale"
+
+// SetLocale 设置当前请求使用的语言，并通过 Content-Language 响应头告知客户端
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(LocaleKey, locale)
+	c.Header("Content-Language", locale)
+}
+
 // JSONResponse 标准化响应格式函数
 // 该函数用于构建一个标准化的 JSON 响应格式，并发送给客户端
 // 参数说明：
 // - c: Gin 上下文，用于处理请求和响应
 // - status: HTTP 状态码，如 200, 400, 404 等
-// - message: 响应消息，通常用于描述操作结果
+// - message: 响应消息，通常用于描述操作结果，使用中文书写，返回时按请求的语言翻译
 // - data: 响应的数据内容，接受任意类型，如果为空则默认为空对象
+//
+// 响应中的 code 为错误码，成功时为 ok；data 中带有业务错误码（gin.H 的 code 或者实现了 ErrorCode 方法）时使用该错误码，
+// 否则按状态码使用通用错误码。失败的响应中还会带有请求 ID
 func JSONResponse(c *gin.Context, status int, message string, data interface{}) {
 	// 如果 data 为空，将其设置为一个空的 gin.H（空对象）
 	if data == nil {
 		data = gin.H{}
 	}
 
-	// 返回标准化的 JSON 格式响应，其中包含状态码、消息和数据
-	c.JSON(status, gin.H{
-		"status":  status,  // 状态码
-		"message": message, // 响应消息
-		"data":    data,    // 响应数据（默认为空对象）
-	})
+	// 返回标准化的 JSON 格式响应，其中包含状态码、错误码、消息和数据
+	code := codeOf(status, data)
+	body := gin.H{
+		"status":  status,                                                     // 状态码，与 HTTP 状态码相同，保留用于兼容
+		"code":    code,                                                       // 错误码
+		"message": i18n.Translate(c.GetString(LocaleKey), code, message, nil), // 响应消息
+		"data":    data,                                                       // 响应数据（默认为空对象）
+	}
+	if status >= 400 {
+		body["request_id"] = c.GetString(RequestIDKey)
+	}
+	c.JSON(status, body)
+}
+
+// codeOf 返回响应的错误码
+func codeOf(status int, data interface{}) string {
+	if status < 400 {
+		return CodeOK
+	}
+	switch d := data.(type) {
+	case interface{ ErrorCode() string }:
+		return d.ErrorCode()
+	case gin.H:
+		if code, ok := d["code"].(string); ok && code != "" {
+			return code
+		}
+	}
+	return CodeForStatus(status)
 }
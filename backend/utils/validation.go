@@ -1,15 +1,105 @@
 package utils
 
-import "github.com/go-playground/validator/v10"
+import (
+	"backend/i18n"
+	"errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
 
 // 声明全局变量 validate，用于存储验证器实例
 var validate *validator.Validate
 
+// translators 各语言的校验错误翻译器，键为 i18n 中的语言
+var translators = map[string]ut.Translator{}
+
+// 自定义校验规则使用的正则表达式
+var (
+	phoneRegex = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9_.+-]+@[a-zA-Z0-9-]+\.[a-zA-Z0-9-.]+$`)
+	slugRegex  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+)
+
+// customRule 自定义校验规则及其中文、英文提示，{0} 为字段名
+type customRule struct {
+	tag   string
+	fn    func(value string) bool
+	zhMsg string
+	enMsg string
+}
+
+// customRules 项目中使用的自定义校验规则，email 和 url 覆盖验证器自带的规则
+// 字段为空时同样会校验，可以为空的字段需要加上 omitempty
+var customRules = []customRule{
+	{"phone", IsValidPhoneNumber, "{0}必须是有效的手机号", "{0} must be a valid phone number"},
+	{"email", IsValidEmail, "{0}必须是一个有效的邮箱", "{0} must be a valid email address"},
+	{"slug", IsValidSlug, "{0}只能包含小写字母、数字和连字符", "{0} may only contain lowercase letters, numbers and hyphens"},
+	{"url", IsValidURL, "{0}必须是一个以 http:// 或 https:// 开头的链接", "{0} must be an http:// or https:// URL"},
+}
+
 // init 函数用于初始化验证器实例
 // init 函数在包加载时自动调用，用于初始化必要的依赖
 func init() {
 	// 创建一个新的验证器实例
 	validate = validator.New()
+	// 校验错误中的字段名使用 JSON 字段名，与请求数据一致
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	// 注册校验错误的中文和英文翻译
+	uni := ut.New(zh.New(), zh.New(), en.New())
+	zhTrans, _ := uni.GetTranslator("zh")
+	enTrans, _ := uni.GetTranslator("en")
+	if err := zhTranslations.RegisterDefaultTranslations(validate, zhTrans); err != nil {
+		panic(err)
+	}
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		panic(err)
+	}
+	translators[i18n.ZhCN] = zhTrans
+	translators[i18n.EnUS] = enTrans
+
+	// 注册自定义校验规则，需要在默认翻译之后注册，才能覆盖 email 和 url 的提示
+	for _, rule := range customRules {
+		registerRule(rule)
+	}
+}
+
+// registerRule 注册一条自定义校验规则及其翻译
+func registerRule(rule customRule) {
+	fn := rule.fn
+	if err := validate.RegisterValidation(rule.tag, func(fl validator.FieldLevel) bool {
+		return fn(fl.Field().String())
+	}); err != nil {
+		panic(err)
+	}
+	for locale, msg := range map[string]string{i18n.ZhCN: rule.zhMsg, i18n.EnUS: rule.enMsg} {
+		trans := translators[locale]
+		msg := msg
+		err := validate.RegisterTranslation(rule.tag, trans, func(ut ut.Translator) error {
+			return ut.Add(rule.tag, msg, true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T(fe.Tag(), fe.Field())
+			return t
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
 }
 
 // GetValidator 返回全局的验证器实例
@@ -18,3 +108,99 @@ func GetValidator() *validator.Validate {
 	// 返回已经初始化的验证器对象
 	return validate
 }
+
+// Validate 按 validate 标签校验结构体，通过时返回 nil
+// 未通过时返回的错误中包含所有未通过的字段（details），message 为第一个字段的提示；
+// 字段可以用 msg 标签指定中文提示，例如 `validate:"required,max=20" msg:"用户名不能为空"`，没有时使用验证器的提示
+func Validate(obj interface{}) *AppError {
+	err := validate.Struct(obj)
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return Internal(err)
+	}
+	details := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		details[i] = FieldError{
+			Field:  fe.Field(),
+			Rule:   fe.Tag(),
+			Param:  fe.Param(),
+			source: fe,
+			custom: fieldMessage(obj, fe.StructNamespace()),
+		}
+	}
+	return Invalid("", details...)
+}
+
+// fieldMessage 返回字段 msg 标签中的提示，namespace 为验证器返回的结构体字段路径，例如 User.Username
+func fieldMessage(obj interface{}, namespace string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	parts := strings.Split(namespace, ".")
+	// 具名结构体的第一段是结构体名称，匿名结构体没有这一段
+	if t.Name() != "" {
+		parts = parts[1:]
+	}
+	var field reflect.StructField
+	for _, part := range parts {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return ""
+		}
+		name, _, _ := strings.Cut(part, "[")
+		var ok bool
+		if field, ok = t.FieldByName(name); !ok {
+			return ""
+		}
+		t = field.Type
+	}
+	return field.Tag.Get("msg")
+}
+
+// translateFieldError 把字段错误翻译为 locale 对应的语言，没有来源错误时保留原来的 message
+// 字段指定了 msg 标签时按中文提示翻译
+func translateFieldError(fe FieldError, locale string) FieldError {
+	if fe.custom != "" {
+		fe.Message = i18n.Translate(locale, "", fe.custom, nil)
+		return fe
+	}
+	if fe.source == nil {
+		return fe
+	}
+	trans, ok := translators[locale]
+	if !ok {
+		trans = translators[i18n.Default()]
+	}
+	fe.Message = fe.source.Translate(trans)
+	return fe
+}
+
+// IsValidPhoneNumber 验证手机号格式
+func IsValidPhoneNumber(phoneNumber string) bool {
+	return phoneRegex.MatchString(phoneNumber)
+}
+
+// IsValidEmail 验证邮箱格式
+func IsValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
+
+// IsValidSlug 验证别名格式，只能包含小写字母、数字和连字符，不能以连字符开头或结尾
+func IsValidSlug(slug string) bool {
+	return slugRegex.MatchString(slug)
+}
+
+// IsValidURL 验证链接格式，只允许带有主机名的 http 和 https 链接
+func IsValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"backend/i18n"
+	"testing"
+)
+
+type testProfile struct {
+	Nickname string `json:"nickname" validate:"required" msg:"昵称不能为空"`
+}
+
+type testAccount struct {
+	Username string         `json:"username" validate:"required,max=5"`
+	Email    string         `json:"email" validate:"omitempty,email" msg:"请输入正确的邮箱"`
+	Profiles []*testProfile `json:"profiles" validate:"dive"`
+}
+
+func TestValidateDetails(t *testing.T) {
+	err := Validate(testAccount{Username: "toolong", Email: "bad", Profiles: []*testProfile{{}}})
+	if err == nil {
+		t.Fatal("Validate() = nil，期望校验失败")
+	}
+	if err.Code != CodeValidationFailed {
+		t.Fatalf("code = %q，期望 %q", err.Code, CodeValidationFailed)
+	}
+
+	want := []struct {
+		field, rule, param, message string
+	}{
+		{"username", "max", "5", "username长度不能超过5个字符"},
+		{"email", "email", "", "请输入正确的邮箱"},
+		{"nickname", "required", "", "昵称不能为空"},
+	}
+	if len(err.Details) != len(want) {
+		t.Fatalf("details = %+v，期望 %d 个字段", err.Details, len(want))
+	}
+	for i, w := range want {
+		fe := translateFieldError(err.Details[i], i18n.ZhCN)
+		if fe.Field != w.field || fe.Rule != w.rule || fe.Param != w.param || fe.Message != w.message {
+			t.Errorf("details[%d] = %s/%s/%s %q，期望 %s/%s/%s %q", i, fe.Field, fe.Rule, fe.Param, fe.Message, w.field, w.rule, w.param, w.message)
+		}
+	}
+}
+
+func TestValidateAnonymousStruct(t *testing.T) {
+	var request struct {
+		Code string `json:"code" validate:"required" msg:"验证码不能为空"`
+	}
+	err := Validate(request)
+	if err == nil || len(err.Details) != 1 {
+		t.Fatalf("Validate() = %v，期望一个字段错误", err)
+	}
+	if fe := translateFieldError(err.Details[0], i18n.ZhCN); fe.Message != "验证码不能为空" {
+		t.Fatalf("message = %q，期望使用 msg 标签", fe.Message)
+	}
+}
+
+func TestTranslateFieldErrorEnglish(t *testing.T) {
+	err := Validate(testAccount{Username: ""})
+	if err == nil {
+		t.Fatal("Validate() = nil，期望校验失败")
+	}
+	if fe := translateFieldError(err.Details[0], i18n.EnUS); fe.Message != "username is a required field" {
+		t.Fatalf("message = %q，期望验证器的英文提示", fe.Message)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	cases := []struct {
+		name  string
+		fn    func(string) bool
+		value string
+		want  bool
+	}{
+		{"手机号", IsValidPhoneNumber, "13800138000", true},
+		{"手机号位数不对", IsValidPhoneNumber, "1380013800", false},
+		{"邮箱", IsValidEmail, "alice@example.com", true},
+		{"邮箱缺少域名", IsValidEmail, "alice@", false},
+		{"别名", IsValidSlug, "go-modules", true},
+		{"别名包含大写字母", IsValidSlug, "Go-Modules", false},
+		{"别名以连字符结尾", IsValidSlug, "go-", false},
+		{"链接", IsValidURL, "https://example.com/a", true},
+		{"javascript 链接", IsValidURL, "javascript:alert(1)", false},
+		{"缺少主机名", IsValidURL, "http://", false},
+	}
+	for _, tc := range cases {
+		if got := tc.fn(tc.value); got != tc.want {
+			t.Errorf("%s: %q = %v，期望 %v", tc.name, tc.value, got, tc.want)
+		}
+	}
+}